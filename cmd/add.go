@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-sova/sova-cli/internal/config"
+	"github.com/go-sova/sova-cli/internal/hooks"
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	addDir        string
+	addYes        bool
+	addSkipTidy   bool
+	addOnConflict string
+	addTemplate   string
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add <component>...",
+	Short: "Add components to an existing project",
+	Long: `Add components to a project generated by sova, for example:
+  sova add postgres
+  sova add redis rabbitmq
+
+The project type and the answers the project was generated with are read
+from its .sova.lock, or detected from the files in the project when it has
+none. The files of the new components are
+created and the shared files they affect, such as .env, docker-compose.yml
+and go.mod, are regenerated.
+
+Files you have edited since they were generated are not overwritten: the
+new version is written next to them with a .sova-new suffix for you to merge
+by hand. Use --on-conflict to decide otherwise: skip keeps your version,
+overwrite replaces it, backup saves it with a .bak suffix first, and prompt
+shows a diff of every edited file and asks. New requirements are merged
+into an edited go.mod, and go mod tidy runs afterwards unless --skip-tidy is
+given.
+
+The components available depend on the project type; for API projects they
+are postgres, redis, rabbitmq and zap.
+
+A template that was fetched with sova init --template is fetched again from
+the location recorded in .sova.lock. Use --template to fetch it from
+somewhere else when it has moved.`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		onConflict, err := conflictResolver(addOnConflict)
+		if err != nil {
+			return err
+		}
+
+		answers, lock, err := projectAnswers(cmd.Context(), addDir, addTemplate)
+		if err != nil {
+			return err
+		}
+
+		preset, err := questions.PresetFromAnswers(answers)
+		if err != nil {
+			return err
+		}
+
+		var added []string
+		for _, component := range args {
+			prompt, err := questions.Component(answers.ProjectType, component)
+			if err != nil {
+				return err
+			}
+			if enabled, _ := answers.Values[prompt.Name].(bool); enabled {
+				PrintWarning("%s is already part of %s", prompt.Alias, answers.ProjectName)
+				continue
+			}
+			preset.Set(prompt.Name, true)
+			added = append(added, prompt.Alias)
+		}
+		if len(added) == 0 {
+			return nil
+		}
+
+		if addYes {
+			if err := preset.ApplyDefaults(answers.ProjectType); err != nil {
+				return err
+			}
+		}
+		updated, err := questions.AskProjectQuestionsWithPreset(answers.ProjectType, preset)
+		if err != nil {
+			return fmt.Errorf("failed to get project configuration: %v", err)
+		}
+
+		// The files as they were generated tell the edits apart from what
+		// the templates rendered. Projects without a base render are
+		// compared with a render of their answers with the current templates.
+		base, err := project.ReadBase(addDir)
+		if os.IsNotExist(err) {
+			base, err = planProject(cmd.Context(), answers.ProjectName, answers)
+		}
+		if err != nil {
+			return err
+		}
+		base.Lock = lock
+		plan, err := planProject(cmd.Context(), answers.ProjectName, updated)
+		if err != nil {
+			return err
+		}
+		plan.OnConflict = onConflict
+
+		changes, err := plan.Update(cmd.Context(), addDir, base)
+		summary := printChanges(addDir, changes)
+		if err != nil {
+			return fmt.Errorf("failed to update project: %v", err)
+		}
+		if err := recordChanges(cmd.Context(), addDir, plan, base, changes, addSkipTidy); err != nil {
+			return err
+		}
+
+		PrintSuccess("Added %s to %s", strings.Join(added, ", "), answers.ProjectName)
+		if summary.pending > 0 {
+			fmt.Printf("Merge the %s files into the files next to them and delete them.\n", project.NewFileSuffix)
+		}
+		return nil
+	},
+}
+
+// changeSummary counts the files Update or Upgrade touched
+type changeSummary struct {
+	clean     int
+	merged    int
+	skipped   int
+	conflicts int
+	// pending counts the conflicts whose new content was written next to
+	// the file
+	pending int
+}
+
+// printChanges prints every file Update or Upgrade touched in dir
+func printChanges(dir string, changes []project.FileChange) changeSummary {
+	var summary changeSummary
+	for _, change := range changes {
+		fullPath := filepath.Join(dir, change.Path)
+		switch change.Action {
+		case project.FileCreated:
+			fmt.Printf("Created file: %s\n", fullPath)
+			summary.clean++
+		case project.FileUpdated:
+			if change.Backup != "" {
+				fmt.Printf("Updated file: %s (previous version saved to %s)\n", fullPath, filepath.Join(dir, change.Backup))
+			} else {
+				fmt.Printf("Updated file: %s\n", fullPath)
+			}
+			summary.clean++
+		case project.FileRemoved:
+			fmt.Printf("Removed file: %s\n", fullPath)
+			summary.clean++
+		case project.FileMerged:
+			fmt.Printf("Merged file:  %s\n", fullPath)
+			summary.merged++
+		case project.FileSkipped:
+			fmt.Printf("Kept file:    %s\n", fullPath)
+			summary.skipped++
+		case project.FileConflict:
+			summary.conflicts++
+			if change.NewPath != "" {
+				PrintWarning("%s has local changes; the new version was written to %s", fullPath, filepath.Join(dir, change.NewPath))
+				summary.pending++
+			} else {
+				PrintWarning("%s has conflicts; resolve the conflict markers", fullPath)
+			}
+		}
+	}
+	return summary
+}
+
+// recordChanges runs go mod tidy when go.mod changed and records the lock
+// and base render of plan in dir
+func recordChanges(ctx context.Context, dir string, plan, base *project.Plan, changes []project.FileChange, skipTidy bool) error {
+	for _, change := range changes {
+		if change.Path != "go.mod" || change.NewPath != "" || skipTidy {
+			continue
+		}
+		steps := []templates.HookStep{{Command: hooks.StepGoModTidy}}
+		if err := hooks.NewRunner().Run(ctx, dir, steps); err != nil {
+			return err
+		}
+	}
+	return plan.RecordUpdate(dir, base, changes)
+}
+
+// templateOverrideUsage is the usage of the --template flag of every command
+// that works on an existing project
+const templateOverrideUsage = "template source to fetch the project's template from instead of the one in .sova.lock"
+
+// projectAnswers returns the answers the project in dir was generated with
+// and its lock. Projects without a lock, generated by an older sova, are
+// detected from their files. The template of the project is fetched again
+// when needed, or from override when it is set; see useProjectTemplate.
+func projectAnswers(ctx context.Context, dir, override string) (*questions.ProjectAnswers, *project.Lock, error) {
+	lock, err := project.ReadLock(dir)
+	if err == nil {
+		if err := useProjectTemplate(ctx, lock, override); err != nil {
+			return nil, nil, err
+		}
+		answers, err := lock.ProjectAnswers()
+		return answers, lock, err
+	}
+	if !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	if override != "" {
+		if err := useTemplateOverride(ctx, override); err != nil {
+			return nil, nil, err
+		}
+	}
+	answers, err := project.DetectProject(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	defaults := config.GetDefaults(viper.GetViper())
+	answers.Author = defaults.Author
+	answers.License = defaults.License
+	if answers.GoVersion == "" {
+		answers.GoVersion = defaults.GoVersion
+	}
+	return answers, nil, nil
+}
+
+// useProjectTemplate makes the template of the project with lock available.
+// A template that is not available locally is fetched again from the
+// location the lock records, and override, a template source as given to
+// sova init --template, replaces that location. The lock is updated to
+// record where the template was fetched from.
+func useProjectTemplate(ctx context.Context, lock *project.Lock, override string) error {
+	ref := override
+	if ref == "" {
+		if lock.Template.Location == "" || templateAvailable(lock.Template.Name) {
+			return nil
+		}
+		ref = lock.Template.Location
+	}
+
+	source, err := parseTemplateSource(ref)
+	if err != nil {
+		return err
+	}
+	fetched, types, err := fetchTemplateSource(ctx, source)
+	if err != nil {
+		return fmt.Errorf("failed to fetch template %s of the project: %v (use --template if it has moved)", lock.Template.Name, err)
+	}
+	found := false
+	for _, t := range types {
+		found = found || t == lock.Template.Name
+	}
+	if !found {
+		return fmt.Errorf("template %s does not provide project type %s (available: %s)", source, lock.Template.Name, strings.Join(types, ", "))
+	}
+	return lock.SetSource(ref, fetched.Revision)
+}
+
+// useTemplateOverride fetches the template source given with --template for
+// a project without a lock
+func useTemplateOverride(ctx context.Context, ref string) error {
+	source, err := parseTemplateSource(ref)
+	if err != nil {
+		return err
+	}
+	_, _, err = fetchTemplateSource(ctx, source)
+	return err
+}
+
+// templateAvailable reports whether the project type name is provided by
+// the embedded templates or a template directory
+func templateAvailable(name string) bool {
+	_, err := templates.LoadManifest(name)
+	return err == nil
+}
+
+func init() {
+	addCmd.Flags().StringVar(&addDir, "dir", ".", "directory of the project")
+	addCmd.Flags().BoolVarP(&addYes, "yes", "y", false, "accept defaults for every new question")
+	addCmd.Flags().BoolVar(&addSkipTidy, "skip-tidy", false, "don't run go mod tidy after go.mod changes")
+	addCmd.Flags().StringVar(&addOnConflict, "on-conflict", "", onConflictUsage)
+	addCmd.Flags().StringVar(&addTemplate, "template", "", templateOverrideUsage)
+	rootCmd.AddCommand(addCmd)
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-sova/sova-cli/internal/config"
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var goVersionPattern = regexp.MustCompile(`^1\.\d+(\.\d+)?$`)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and edit the Sova CLI configuration file",
+	Long: `Read and edit the Sova CLI configuration file ($HOME/.sova.yaml by default).
+
+Keys use dots to address nested sections, for example:
+  sova config set defaults.author "Jane Doe"
+  sova config get defaults.license
+  sova config list
+
+The keys are defaults.author, defaults.license, defaults.goVersion,
+defaults.template, defaults.modulePrefix and templates.directory; set
+rejects any other key. Every defaults.* key can also be overridden with an
+environment variable, e.g. SOVA_DEFAULT_AUTHOR.`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:          "get <key>",
+	Short:        "Print the effective value of a configuration key",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := canonicalConfigKey(args[0])
+		if !viper.IsSet(key) {
+			return fmt.Errorf("%s is not set", key)
+		}
+		fmt.Println(configValue(key))
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:          "set <key> <value>",
+	Short:        "Set a configuration key in the configuration file",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, value := canonicalConfigKey(args[0]), args[1]
+		if err := config.ValidateKey(key); err != nil {
+			return err
+		}
+		if err := validateConfigValue(key, value); err != nil {
+			return err
+		}
+
+		path, err := configFilePath()
+		if err != nil {
+			return err
+		}
+		if err := config.SetValue(path, key, value); err != nil {
+			return err
+		}
+
+		if env, ok := config.EnvVar(key); ok && viper.GetString(key) != value && envIsSet(env) {
+			PrintWarning("%s is set and overrides %s", env, key)
+		}
+		PrintSuccess("Set %s in %s", key, path)
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the effective configuration and where each value comes from",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		keys := map[string]string{}
+		for _, key := range config.KnownKeys() {
+			keys[strings.ToLower(key)] = key
+		}
+		for _, key := range viper.AllKeys() {
+			if _, ok := keys[key]; !ok && viper.InConfig(key) {
+				keys[key] = key
+			}
+		}
+
+		sorted := make([]string, 0, len(keys))
+		for _, key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+
+		for _, key := range sorted {
+			fmt.Printf("%s = %s  (%s)\n", key, configValue(key), configSource(key))
+		}
+	},
+}
+
+func configFilePath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	return config.DefaultPath()
+}
+
+// canonicalConfigKey maps a key typed in any case to the spelling sova uses
+func canonicalConfigKey(key string) string {
+	for _, known := range config.KnownKeys() {
+		if strings.EqualFold(known, key) {
+			return known
+		}
+	}
+	return key
+}
+
+// configValue returns the effective value of key for printing. The
+// template directories may be a YAML list, which is printed like the
+// string form of the setting.
+func configValue(key string) string {
+	if key == config.KeyTemplateDirectory {
+		return strings.Join(config.TemplateDirs(viper.GetViper()), string(os.PathListSeparator))
+	}
+	return viper.GetString(key)
+}
+
+func validateConfigValue(key, value string) error {
+	switch key {
+	case config.KeyGoVersion:
+		if !goVersionPattern.MatchString(value) {
+			return fmt.Errorf("invalid Go version %q, expected something like 1.21 or 1.22.3", value)
+		}
+	case config.KeyModulePrefix:
+		if err := project.ValidateModulePath(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func configSource(key string) string {
+	if env, ok := config.EnvVar(key); ok && envIsSet(env) {
+		return "env " + env
+	}
+	if viper.InConfig(key) {
+		return "file"
+	}
+	if viper.IsSet(key) {
+		return "default"
+	}
+	return "unset"
+}
+
+func envIsSet(name string) bool {
+	_, ok := os.LookupEnv(name)
+	return ok
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/go-sova/sova-cli/internal/conflict"
+	"github.com/go-sova/sova-cli/internal/diff"
+	"github.com/go-sova/sova-cli/pkg/questions"
+)
+
+// onConflictUsage is the usage of the --on-conflict flag of every command
+// that writes into an existing project
+const onConflictUsage = "what to do with existing files that differ from the new version: skip, overwrite, prompt or backup"
+
+// conflictResolver returns the resolver for the policy given with
+// --on-conflict, or nil when the flag is empty. The prompt policy shows a
+// diff of every conflicting file and asks what to do with it.
+func conflictResolver(name string) (*conflict.Resolver, error) {
+	if name == "" {
+		return nil, nil
+	}
+	policy, err := conflict.ParsePolicy(name)
+	if err != nil {
+		return nil, err
+	}
+	if policy == conflict.Prompt && !questions.IsInteractive() {
+		return nil, fmt.Errorf("--on-conflict=prompt needs a terminal; use skip, overwrite or backup")
+	}
+
+	// remembered is the decision the user chose for every remaining file
+	var remembered conflict.Policy
+	return conflict.NewResolver(policy, func(path string, current, next []byte) (conflict.Policy, error) {
+		if remembered != "" {
+			return remembered, nil
+		}
+		printDiff(path, current, next)
+		decision, all, err := questions.AskConflict(path)
+		if all {
+			remembered = decision
+		}
+		return decision, err
+	}), nil
+}
+
+// printDiff prints a unified diff of the existing file at path against the
+// content sova is about to write
+func printDiff(path string, current, next []byte) {
+	text := diff.Unified(path+" (current)", path+" (new)", diff.Lines(string(current)), diff.Lines(string(next)), 3)
+	for _, line := range diff.Lines(text) {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			color.New(color.Bold).Print(line)
+		case strings.HasPrefix(line, "@@"):
+			color.New(color.FgCyan).Print(line)
+		case strings.HasPrefix(line, "-"):
+			color.New(color.FgRed).Print(line)
+		case strings.HasPrefix(line, "+"):
+			color.New(color.FgGreen).Print(line)
+		default:
+			fmt.Print(line)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var doctorDir string
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check a generated project against its .sova.lock",
+	Long: `Check a project generated by sova against the .sova.lock written when it
+was generated. Doctor reports the sova and template versions the project was
+generated with, whether the template is still available, and the generated
+files that were modified or deleted since. Modifying generated files is
+expected, so only a lock that cannot be read, a template that is no longer
+available and deleted files are problems. Doctor exits with an error when
+it finds one, so it can be used in CI jobs.`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lock, err := project.ReadLock(doctorDir)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s in %s: the project was not generated by sova, or by an older version of sova", project.LockFile, doctorDir)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Project:  %s\n", lock.Project.Name)
+		template := lock.Template.Name
+		if lock.Template.Version != "" {
+			template += " " + lock.Template.Version
+		}
+		if lock.Template.Location != "" {
+			template += " (" + lock.Template.Location + ")"
+		}
+		if lock.Template.Revision != "" {
+			template += " at " + lock.Template.Revision
+		}
+		fmt.Printf("Template: %s\n", template)
+		fmt.Printf("Sova:     %s\n\n", lock.SovaVersion)
+
+		problems := 0
+		if current := version.GetInfo().Version; current != lock.SovaVersion {
+			PrintInfo("The project was generated by sova %s; this is sova %s", lock.SovaVersion, current)
+		}
+
+		info, err := project.NewTemplateManager().Template(lock.Template.Name)
+		switch {
+		case err != nil && lock.Template.Location != "":
+			PrintInfo("Template %s is not available locally; sova add and sova upgrade fetch it from %s", lock.Template.Name, lock.Template.Location)
+		case err != nil:
+			PrintWarning("Template %s is not available: %v", lock.Template.Name, err)
+			problems++
+		case info.Version != lock.Template.Version:
+			PrintInfo("Template %s is now at version %s", lock.Template.Name, info.Version)
+		}
+
+		statuses, err := lock.Check(doctorDir)
+		if err != nil {
+			return err
+		}
+		modified := 0
+		for _, status := range statuses {
+			if status.State == project.FileModified {
+				PrintInfo("%s: %s since it was generated", filepath.Join(doctorDir, status.Path), status.State)
+				modified++
+				continue
+			}
+			PrintWarning("%s: %s since it was generated", filepath.Join(doctorDir, status.Path), status.State)
+			problems++
+		}
+
+		if len(statuses) == 0 {
+			PrintSuccess("Every generated file is as sova wrote it")
+		} else if modified > 0 {
+			fmt.Printf("%d generated files were modified; sova add and sova upgrade merge into them instead of overwriting them.\n", modified)
+		}
+		if problems > 0 {
+			return fmt.Errorf("found %d problem(s)", problems)
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().StringVar(&doctorDir, "dir", ".", "directory of the project")
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,333 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-sova/sova-cli/internal/conflict"
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/internal/scaffold"
+	"github.com/go-sova/sova-cli/pkg/utils"
+	"github.com/go-sova/sova-cli/templates"
+	"github.com/spf13/cobra"
+)
+
+var (
+	generateDir        string
+	generateOnConflict string
+	generateTemplate   string
+	handlerMethod      string
+	handlerPath        string
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate code in an existing project",
+	Long: `Generate code in a project generated by sova, from the generator templates
+of its project type. The project type and answers are read from the
+project's .sova.lock, and a template fetched with --template is fetched
+again, as for 'sova add'.
+
+Examples:
+  sova generate handler get-user --method GET --path /users/:id
+  sova generate resource user name:string email:string:unique age:int`,
+}
+
+var generateHandlerCmd = &cobra.Command{
+	Use:   "handler <name>",
+	Short: "Add a handler and its route to an API project",
+	Long: `Add a handler to a project generated from the api template. The handler is
+written to internal/handlers/<name>.go with a test stub next to it, and its
+route is registered in the router group of SetupRoutes in
+internal/routes/routes.go.
+
+The route is added by parsing routes.go rather than by editing its text, so
+the file may have been changed since it was generated. --path is relative
+to the router group; a path that starts with the group's prefix, such as
+/api/users, is taken to include it. It defaults to /<name>.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		onConflict, err := conflictResolver(generateOnConflict)
+		if err != nil {
+			return err
+		}
+		method, err := scaffold.ParseMethod(handlerMethod)
+		if err != nil {
+			return err
+		}
+
+		s, err := projectScaffold(cmd.Context(), generateDir, "handler.go.tpl")
+		if err != nil {
+			return err
+		}
+		routes, routesContent, err := readRoutes(generateDir)
+		if err != nil {
+			return err
+		}
+
+		routePath := handlerPath
+		if routePath == "" {
+			routePath = "/" + utils.KebabCase(args[0])
+		}
+		handler, err := scaffold.NewHandler(args[0], method, routes.RelativePath(routePath))
+		if err != nil {
+			return err
+		}
+		if err := checkDeclared(generateDir, map[string]string{handler.Name: handler.File}); err != nil {
+			return err
+		}
+
+		values := handler.Values(routes.Prefix())
+		files := []generatedFile{{Path: handler.File}, {Path: handler.TestFile}}
+		for i, name := range []string{"handler.go.tpl", "handler_test.go.tpl"} {
+			if files[i].Content, err = s.Render(name, files[i].Path, values); err != nil {
+				return err
+			}
+		}
+		if routesContent, err = routes.Add(scaffold.HandlersPackage(s.ModulePath()), handler.Route); err != nil {
+			return err
+		}
+		files = append(files, generatedFile{Path: scaffold.RoutesFile, Content: routesContent, Update: true})
+
+		if err := writeGenerated(generateDir, files, onConflict); err != nil {
+			return err
+		}
+		PrintSuccess("Added %s for %s %s", handler.Name, method, values["URL"])
+		return nil
+	},
+}
+
+var generateResourceCmd = &cobra.Command{
+	Use:   "resource <name> <field:type[:unique]>...",
+	Short: "Add a database-backed resource with CRUD handlers to an API project",
+	Long: `Add a resource stored in PostgreSQL to a project generated from the api
+template with postgres enabled. For "sova generate resource user" it writes:
+
+  internal/models/user.go            the User model
+  internal/repository/user.go        a UserRepository using service.DB
+  internal/handlers/user.go          list, get, create, update and delete handlers
+  internal/handlers/user_test.go     a test stub for the handlers
+  migrations/<version>_create_users.up.sql and .down.sql
+
+and registers the routes of the handlers under /users in the router group of
+SetupRoutes. Every resource has an id and created_at and updated_at
+timestamps; the other fields are given as name:type, with :unique for a
+unique column. The types are string, text, int, int64, float, bool and time.
+
+Generating a resource again rewrites its migration instead of adding one.
+The migrations follow the golang-migrate naming and are not run by sova.`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		onConflict, err := conflictResolver(generateOnConflict)
+		if err != nil {
+			return err
+		}
+		resource, err := scaffold.NewResource(args[0], args[1:])
+		if err != nil {
+			return err
+		}
+
+		s, err := projectScaffold(cmd.Context(), generateDir, "resource_handlers.go.tpl")
+		if err != nil {
+			return err
+		}
+		if usePostgres, _ := s.Value("UsePostgres").(bool); !usePostgres {
+			return fmt.Errorf("resources are stored in PostgreSQL, which the project does not use; add it with 'sova add postgres'")
+		}
+		routes, routesContent, err := readRoutes(generateDir)
+		if err != nil {
+			return err
+		}
+
+		handlersFile := filepath.Join(scaffold.HandlersDir, utils.SnakeCase(resource.Model)+".go")
+		funcs := make(map[string]string)
+		for _, name := range resource.Funcs() {
+			funcs[name] = handlersFile
+		}
+		if err := checkDeclared(generateDir, funcs); err != nil {
+			return err
+		}
+
+		version, ok := scaffold.FindMigration(generateDir, resource.Table)
+		if !ok {
+			version = scaffold.MigrationVersion(time.Now())
+		}
+		values := resource.Values(routes.Prefix())
+		var files []generatedFile
+		for _, file := range resource.Files(version) {
+			content, err := s.Render(file.Template, file.Path, values)
+			if err != nil {
+				return err
+			}
+			files = append(files, generatedFile{Path: file.Path, Content: content})
+		}
+
+		// Routes registered when the resource was generated before are kept
+		var missing []scaffold.Route
+		for _, route := range resource.Routes() {
+			if !routes.Has(route.Method, route.Path) {
+				missing = append(missing, route)
+			}
+		}
+		if len(missing) > 0 {
+			if routesContent, err = routes.Add(scaffold.HandlersPackage(s.ModulePath()), missing...); err != nil {
+				return err
+			}
+			files = append(files, generatedFile{Path: scaffold.RoutesFile, Content: routesContent, Update: true})
+		}
+
+		if err := writeGenerated(generateDir, files, onConflict); err != nil {
+			return err
+		}
+		PrintSuccess("Added %s with routes under %s", resource.Model, values["URL"])
+		return nil
+	},
+}
+
+// generatedFile is a file sova generate writes, relative to the project
+// directory. Update marks an existing file sova generate changes.
+type generatedFile struct {
+	Path    string
+	Content []byte
+	Update  bool
+}
+
+// projectScaffold returns the scaffold of the project in dir, which must
+// have the generator template name
+func projectScaffold(ctx context.Context, dir, name string) (*project.Scaffold, error) {
+	answers, _, err := projectAnswers(ctx, dir, generateTemplate)
+	if err != nil {
+		return nil, err
+	}
+	s, err := project.NewScaffold(templates.GetTemplateFS(), answers)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.Template(name); !ok {
+		return nil, fmt.Errorf("%s projects have no %s generator template; sova generate works in projects generated from the api template", answers.ProjectType, name)
+	}
+	return s, nil
+}
+
+// readRoutes parses the routes.go of the project in dir
+func readRoutes(dir string) (*scaffold.Routes, []byte, error) {
+	content, err := os.ReadFile(filepath.Join(dir, scaffold.RoutesFile))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read routes: %w", err)
+	}
+	routes, err := scaffold.ParseRoutes(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	return routes, content, nil
+}
+
+// checkDeclared fails when one of funcs, which maps function names to the
+// file that will declare them, is already declared in another file of the
+// handlers package of the project in dir. The file itself is left to
+// writeGenerated.
+func checkDeclared(dir string, funcs map[string]string) error {
+	declared, err := scaffold.DeclaredFuncs(filepath.Join(dir, scaffold.HandlersDir))
+	if err != nil {
+		return err
+	}
+	for name, file := range funcs {
+		existing, ok := declared[name]
+		if !ok || existing == filepath.Base(file) {
+			continue
+		}
+		return fmt.Errorf("%s is already declared in %s", name, filepath.Join(dir, scaffold.HandlersDir, existing))
+	}
+	return nil
+}
+
+// writeGenerated writes files into the project in dir. New files that
+// already exist are an error unless onConflict says what to do with them;
+// every file is checked before anything is written.
+//
+// The files written are recorded in the lock of the project, so that doctor
+// and upgrade do not take them for edits. An updated file that had been
+// edited before keeps the hash it had, and so does a file onConflict kept.
+func writeGenerated(dir string, files []generatedFile, onConflict *conflict.Resolver) error {
+	lock, err := project.ReadLock(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	existing := make(map[string]bool)
+	edited := make(map[string]bool)
+	for _, file := range files {
+		fullPath := filepath.Join(dir, file.Path)
+		current, err := os.ReadFile(fullPath)
+		if err == nil {
+			if !file.Update && onConflict == nil {
+				return fmt.Errorf("%s already exists; use --on-conflict to decide what to do with it", fullPath)
+			}
+			existing[file.Path] = true
+		}
+		if lock != nil && file.Update && err == nil {
+			_, locked := lock.Files[filepath.ToSlash(file.Path)]
+			edited[file.Path] = locked && !lock.Generated(filepath.ToSlash(file.Path), current)
+		}
+	}
+
+	var record []string
+	for _, file := range files {
+		fullPath := filepath.Join(dir, file.Path)
+		if file.Update {
+			if err := os.WriteFile(fullPath, file.Content, 0644); err != nil {
+				return fmt.Errorf("failed to write file %s: %w", fullPath, err)
+			}
+			fmt.Printf("Updated file: %s\n", fullPath)
+			if !edited[file.Path] {
+				record = append(record, filepath.ToSlash(file.Path))
+			}
+			continue
+		}
+
+		result, err := onConflict.WriteFile(fullPath, file.Content)
+		if err != nil {
+			return err
+		}
+		if result.Decision != conflict.Skip {
+			record = append(record, filepath.ToSlash(file.Path))
+		}
+		switch {
+		case result.Decision == "" && existing[file.Path]:
+			fmt.Printf("Kept file:    %s (unchanged)\n", fullPath)
+		case result.Decision == "":
+			fmt.Printf("Created file: %s\n", fullPath)
+		case result.Decision == conflict.Skip:
+			fmt.Printf("Kept file:    %s\n", fullPath)
+		case result.Decision == conflict.Backup:
+			fmt.Printf("Updated file: %s (previous version saved to %s)\n", fullPath, result.Backup)
+		default:
+			fmt.Printf("Updated file: %s\n", fullPath)
+		}
+	}
+
+	if lock == nil {
+		return nil
+	}
+	if err := lock.HashFiles(dir, record); err != nil {
+		return err
+	}
+	return lock.Write(dir)
+}
+
+func init() {
+	generateCmd.PersistentFlags().StringVar(&generateDir, "dir", ".", "directory of the project")
+	generateCmd.PersistentFlags().StringVar(&generateOnConflict, "on-conflict", "", onConflictUsage)
+	generateCmd.PersistentFlags().StringVar(&generateTemplate, "template", "", templateOverrideUsage)
+	generateHandlerCmd.Flags().StringVar(&handlerMethod, "method", "GET", "HTTP method of the route")
+	generateHandlerCmd.Flags().StringVar(&handlerPath, "path", "", "path of the route (default /<name>)")
+
+	generateCmd.AddCommand(generateHandlerCmd)
+	generateCmd.AddCommand(generateResourceCmd)
+	rootCmd.AddCommand(generateCmd)
+}
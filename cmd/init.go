@@ -1,12 +1,39 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
-	"github.com/go-sova/sova-cli/internal/project/api"
-	"github.com/go-sova/sova-cli/internal/project/cli"
+	"github.com/go-sova/sova-cli/internal/config"
+	"github.com/go-sova/sova-cli/internal/hooks"
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/internal/remote"
 	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	initAnswersFile string
+	initProjectType string
+	initTemplate    string
+	initModulePath  string
+	initWith        []string
+	initSet         []string
+	initYes         bool
+	initDryRun      bool
+	initShow        string
+	initSkipHooks   bool
+	initNoGit       bool
+	initTrust       bool
+	initRunHooks    bool
+	initOnConflict  string
 )
 
 var initCmd = &cobra.Command{
@@ -17,48 +44,391 @@ This command will guide you through the project setup process.
 If you don't provide a project name, you'll be prompted to enter one.
 You can choose between different project types:
   - api: A Go API project with clean architecture
-  - cli: A Go CLI project with clean architecture`,
-	Args: cobra.MaximumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
+  - cli: A Go CLI project with clean architecture
+Additional project types are picked up from --template-dir and the
+templates.directory setting.
+
+Every prompt can be answered up front with flags, which makes the command
+usable from scripts and CI jobs:
+  sova init my-api --type api --with postgres,redis
+  sova init my-cli --type cli --yes
+
+Templates can declare their own prompts in template.yaml; answer them with
+--set, using the prompt name or its alias:
+  sova init my-svc --type service --set port=8080 --set features=auth,metrics
+
+Answers can also be read from a YAML or JSON file; flags take precedence
+over the file and only the fields it leaves out are prompted for:
+  sova init --answers answers.yaml
+
+The Go module path defaults to the project name inside the git repository
+you run the command from (based on its origin remote), or to
+defaults.modulePrefix from ~/.sova.yaml. Set it explicitly with --module:
+  sova init --module github.com/acme/my-api --type api --yes
+
+Templates can also be fetched from a git repository, pinned to a tag, branch
+or commit, or from a .tar.gz or .zip archive. Fetched templates are cached
+under ~/.sova/cache:
+  sova init my-svc --template git+https://github.com/acme/templates.git@v1.2.0
+  sova init my-svc --template ./path/to/template.tar.gz
+
+Use --dry-run to print the directories and files that would be generated,
+and --show to print a single rendered file:
+  sova init my-api --type api --yes --dry-run
+  sova init my-api --type api --yes --dry-run --show cmd/main.go
+
+After the project is written, the post-generate hooks of the template run in
+the project directory; the built-in templates format the Go files, run
+go mod tidy and create a git repository with an initial commit. Use
+--no-git to skip the repository and --skip-hooks to skip every hook.
+
+Hooks of a template that is not built in, whether fetched with --template,
+installed or read from a template directory, can run any shell command, so
+sova lists those commands and asks before running them. Without a terminal,
+or with --yes, they are skipped unless --trust is given:
+  sova init my-svc --template ./svc.tar.gz --yes --trust
+
+sova init refuses to write into an existing directory unless --on-conflict
+says what to do with the files that are already there and differ from the
+generated ones: skip keeps them, overwrite replaces them, backup saves them
+with a .bak suffix first, and prompt shows a diff of every file and asks:
+  sova init my-api --type api --yes --on-conflict=prompt
+The hooks of the template don't run in an existing directory, since they
+could change files sova did not write; --run-hooks runs them anyway, except
+git init, which never runs there.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		var projectName string
-		var projectType string
-		var err error
+
+		if initShow != "" && !initDryRun {
+			return fmt.Errorf("--show can only be used together with --dry-run")
+		}
+
+		onConflict, err := conflictResolver(initOnConflict)
+		if err != nil {
+			return err
+		}
+
+		projectType := initProjectType
+		var fetched *remote.Fetched
+		if initTemplate != "" {
+			projectType, fetched, err = useTemplateSource(cmd, initTemplate, projectType)
+			if err != nil {
+				return err
+			}
+		}
+
+		preset := &questions.Preset{}
+		if initAnswersFile != "" {
+			preset, err = questions.LoadPreset(initAnswersFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		defaults := config.GetDefaults(viper.GetViper())
+		preset.ApplyMetadataDefaults(defaults.Author, defaults.License, defaults.GoVersion)
+
+		if initModulePath != "" {
+			preset.ModulePath = initModulePath
+		}
 
 		if len(args) > 0 {
 			projectName = args[0]
+		} else if preset.ProjectName != "" {
+			projectName = preset.ProjectName
+		} else if preset.ModulePath != "" {
+			projectName = path.Base(preset.ModulePath)
 		} else {
 			projectName, err = questions.AskProjectName()
 			if err != nil {
-				fmt.Printf("Error: %v\n", err)
-				return
+				return err
 			}
 		}
 
-		projectType, err = questions.AskProjectType()
+		if preset.ModulePath == "" {
+			preset.ModulePath = project.DefaultModulePath(".", projectName, defaults.ModulePrefix)
+		}
+		if err := project.ValidateModulePath(preset.ModulePath); err != nil {
+			return fmt.Errorf("%v (use --module to set it explicitly)", err)
+		}
+		if !project.IsFetchableModulePath(preset.ModulePath) {
+			PrintWarning("Module path %q has no domain in its first element; other modules will not be able to import it", preset.ModulePath)
+		}
+
+		if projectType == "" {
+			projectType = preset.ProjectType
+		}
+		if projectType == "" && initYes {
+			projectType = defaults.Template
+		}
+		if projectType == "" {
+			projectType, err = questions.AskProjectTypeWithDefault(defaults.Template)
+			if err != nil {
+				return fmt.Errorf("%v (use --type)", err)
+			}
+		}
+
+		if _, err := questions.Components(projectType); err != nil {
+			return fmt.Errorf("%v (available: %s)", err, strings.Join(questions.ProjectTypes(), ", "))
+		}
+
+		if cmd.Flags().Changed("with") {
+			if err := preset.With(projectType, initWith); err != nil {
+				return err
+			}
+		}
+		for _, assignment := range initSet {
+			name, value, ok := strings.Cut(assignment, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				return fmt.Errorf("invalid --set %q: expected name=value", assignment)
+			}
+			preset.Set(strings.TrimSpace(name), value)
+		}
+		if initYes {
+			if err := preset.ApplyDefaults(projectType); err != nil {
+				return err
+			}
+		}
+
+		answers, err := questions.AskProjectQuestionsWithPreset(projectType, preset)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
-			return
+			if questions.IsInteractive() {
+				return fmt.Errorf("failed to get project configuration: %v", err)
+			}
+			return fmt.Errorf("failed to get project configuration: %v (use --with, --set, --answers or --yes)", err)
 		}
 
-		switch projectType {
-		case "api":
-			apiCmd := api.InitCmd
-			apiCmd.SetArgs([]string{projectName})
-			err = apiCmd.Execute()
-		case "cli":
-			cliCmd := cli.InitCmd
-			cliCmd.SetArgs([]string{projectName})
-			err = cliCmd.Execute()
-		default:
-			err = fmt.Errorf("unsupported project type: %s", projectType)
+		if initDryRun {
+			return printPlan(cmd.Context(), projectName, answers)
 		}
 
+		_, statErr := os.Stat(projectName)
+		existing := statErr == nil
+		if existing && onConflict == nil {
+			return fmt.Errorf("directory %s already exists (use --on-conflict to generate into it)", projectName)
+		}
+
+		var runner *hooks.Runner
+		if !initSkipHooks {
+			runner = hooks.NewRunner()
+			runner.NoGit = initNoGit
+			runner.InExistingDir = initRunHooks
+			if source := templateOrigin(answers.ProjectType); source != "" {
+				runner.ConfirmShell = confirmShellHooks(source)
+			}
+		}
+
+		projectDir := filepath.Join(".", projectName)
+		plan, err := planProject(cmd.Context(), projectName, answers)
 		if err != nil {
-			fmt.Printf("Error: %v\n", err)
+			return fmt.Errorf("failed to generate project files: %v", err)
+		}
+		if fetched != nil {
+			if err := plan.Lock.SetSource(initTemplate, fetched.Revision); err != nil {
+				return err
+			}
+		}
+		plan.OnConflict = onConflict
+
+		err = plan.Generate(cmd.Context(), projectDir, runner)
+		var hookErr *project.HookError
+		if err != nil && !errors.As(err, &hookErr) {
+			return fmt.Errorf("failed to generate project: %v", err)
+		}
+
+		for _, dir := range plan.Directories {
+			fmt.Printf("Created directory: %s\n", filepath.Join(projectDir, dir))
+		}
+		for _, file := range plan.Files {
+			fmt.Println(plan.DescribeFile(projectDir, file.Path))
 		}
+		if hookErr != nil {
+			return hookErr
+		}
+
+		fmt.Printf("\nProject %s created successfully!\n", projectName)
+		printNextSteps(projectName, answers.ProjectType, plan, runner != nil && (!existing || initRunHooks))
+		return nil
 	},
 }
 
+// printNextSteps prints how to run the project that was just generated.
+// ranHooks tells whether the post-generate hooks ran, which tidy go.mod.
+func printNextSteps(projectName, projectType string, plan *project.Plan, ranHooks bool) {
+	if projectType != "api" && projectType != "cli" {
+		return
+	}
+
+	fmt.Println("\nNext steps:")
+	fmt.Printf("cd %s\n", projectName)
+	if !ranHooks {
+		fmt.Println("go mod tidy")
+	}
+
+	switch projectType {
+	case "api":
+		if _, ok := plan.File("docker-compose.yml"); ok {
+			fmt.Println("docker compose up -d")
+		}
+		fmt.Println("go run cmd/main.go")
+		fmt.Println("\nYour API will be available at http://localhost:8080")
+		fmt.Println("Test the ping endpoint: curl http://localhost:8080/api/ping")
+	case "cli":
+		fmt.Println("go run main.go")
+		fmt.Println("\nTry your CLI commands:")
+		fmt.Printf("   ./%s command1\n", projectName)
+		fmt.Printf("   ./%s command2\n", projectName)
+	}
+}
+
+// templateOrigin returns where the template of projectType comes from, or ""
+// when it is one of the embedded templates. Any other template, whether
+// fetched with --template, installed with sova template install or read from
+// a template directory, can declare arbitrary shell hooks.
+func templateOrigin(projectType string) string {
+	if initTemplate != "" && remote.IsSource(initTemplate) {
+		return initTemplate
+	}
+	info, err := project.NewTemplateManager().Template(projectType)
+	switch {
+	case err != nil:
+		return projectType
+	case info.Source == project.SourceEmbedded:
+		return ""
+	case info.Location != "":
+		return info.Location
+	default:
+		return projectType
+	}
+}
+
+// confirmShellHooks returns the ConfirmShell of the hook runner of a template
+// that is not embedded, from source. Its shell commands run with --trust, or
+// when the user agrees after seeing them; otherwise they are skipped.
+func confirmShellHooks(source string) func(commands []string) (bool, error) {
+	return func(commands []string) (bool, error) {
+		if initTrust {
+			return true, nil
+		}
+
+		fmt.Fprintf(os.Stderr, "Template %s runs these commands:\n", source)
+		for _, command := range commands {
+			fmt.Fprintf(os.Stderr, "  %s\n", command)
+		}
+		if initYes || !questions.IsInteractive() {
+			PrintWarning("Skipping them; use --trust to run the commands of a template you trust")
+			return false, nil
+		}
+		return questions.AskRunCommands(source)
+	}
+}
+
+// useTemplateSource fetches the template given with --template and makes its
+// project types available. It returns the project type to generate, or ""
+// when the source provides several and none was selected with --type, and
+// what was fetched; the latter is nil when ref names a project type.
+func useTemplateSource(cmd *cobra.Command, ref, projectType string) (string, *remote.Fetched, error) {
+	if !remote.IsSource(ref) {
+		if projectType != "" && projectType != ref {
+			return "", nil, fmt.Errorf("--template %s conflicts with --type %s", ref, projectType)
+		}
+		return ref, nil, nil
+	}
+
+	source, err := parseTemplateSource(ref)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fetched, types, err := fetchTemplateSource(cmd.Context(), source)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if projectType != "" {
+		for _, t := range types {
+			if t == projectType {
+				return projectType, fetched, nil
+			}
+		}
+		return "", nil, fmt.Errorf("template %s does not provide project type %s (available: %s)", source, projectType, strings.Join(types, ", "))
+	}
+	if len(types) == 1 {
+		return types[0], fetched, nil
+	}
+	return "", fetched, nil
+}
+
+// fetchTemplateSource fetches source and makes its project types available.
+// It returns what was fetched and the project types the source provides.
+func fetchTemplateSource(ctx context.Context, source *remote.Source) (*remote.Fetched, []string, error) {
+	cacheDir, err := remote.DefaultCacheDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to locate template cache: %v", err)
+	}
+
+	if source.Kind != remote.KindDir {
+		fmt.Fprintf(os.Stderr, "Fetching template %s\n", source)
+	}
+	fetched, err := source.Fetch(ctx, cacheDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if verbose && fetched.Revision != "" {
+		fmt.Fprintf(os.Stderr, "Using template %s at %s\n", source, fetched.Revision)
+	}
+
+	types, err := templates.AddTemplateSource(fetched.Dir, source.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+	return fetched, types, nil
+}
+
+// planProject renders the project answers describe in memory, from the
+// embedded templates overlaid with the template directories
+func planProject(ctx context.Context, projectName string, answers *questions.ProjectAnswers) (*project.Plan, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return project.PlanProject(templates.GetTemplateFS(), nil, projectName, answers)
+}
+
+func printPlan(ctx context.Context, projectName string, answers *questions.ProjectAnswers) error {
+	plan, err := planProject(ctx, projectName, answers)
+	if err != nil {
+		return err
+	}
+
+	if initShow != "" {
+		file, ok := plan.File(initShow)
+		if !ok {
+			return fmt.Errorf("%s is not part of the generated project", initShow)
+		}
+		_, err := os.Stdout.Write(file.Content)
+		return err
+	}
+
+	plan.Print(os.Stdout)
+	return nil
+}
+
 func init() {
+	initCmd.Flags().StringVar(&initAnswersFile, "answers", "", "YAML or JSON file with answers to the project questions")
+	initCmd.Flags().StringVar(&initModulePath, "module", "", "Go module path, e.g. github.com/org/my-api")
+	initCmd.Flags().StringVarP(&initProjectType, "type", "t", "", "project type (api, cli, or one from a template directory)")
+	initCmd.Flags().StringVar(&initTemplate, "template", "", "template source: git+<url>[@ref], a .tar.gz/.zip archive, or a directory")
+	initCmd.Flags().StringSliceVar(&initWith, "with", nil, "components to enable, e.g. postgres,redis,rabbitmq,zap (all others are disabled)")
+	initCmd.Flags().StringArrayVar(&initSet, "set", nil, "answer a template prompt, e.g. --set port=8080 (repeatable)")
+	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "accept defaults for every question not answered by a flag")
+	initCmd.Flags().BoolVar(&initDryRun, "dry-run", false, "print the files that would be generated without writing anything")
+	initCmd.Flags().StringVar(&initShow, "show", "", "with --dry-run, print the rendered content of one file")
+	initCmd.Flags().BoolVar(&initSkipHooks, "skip-hooks", false, "don't run the pre- and post-generate hooks of the template")
+	initCmd.Flags().BoolVar(&initNoGit, "no-git", false, "don't initialize a git repository")
+	initCmd.Flags().BoolVar(&initTrust, "trust", false, "run the shell commands in the hooks of a template that is not built in without asking")
+	initCmd.Flags().StringVar(&initOnConflict, "on-conflict", "", onConflictUsage)
+	initCmd.Flags().BoolVar(&initRunHooks, "run-hooks", false, "with --on-conflict, run the hooks in the existing directory too (except git init)")
 	rootCmd.AddCommand(initCmd)
 }
@@ -1,29 +1,42 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"os"
+	"os/signal"
+	"path/filepath"
 
 	"github.com/fatih/color"
+	"github.com/go-sova/sova-cli/internal/config"
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/pkg/utils"
 	"github.com/go-sova/sova-cli/templates"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	cfgFile string
-	verbose bool
+	cfgFile      string
+	verbose      bool
+	templateDirs []string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "sova",
 	Short: "Sova CLI - A tool for initializing projects",
-	Long: `Sova CLI is a powerful tool for initializing projects 
+	Long: `Sova CLI is a powerful tool for initializing projects
 with predefined templates and structures.
 
 Available Commands:
   init        Initialize a new project with your desired settings
+  add         Add components to an existing project
+  generate    Generate handlers and resources in an existing project
+  upgrade     Apply template improvements to an existing project
+  doctor      Check a generated project against its .sova.lock
+  template    List, inspect, install and remove templates
+  config      Read and edit the configuration file
   version     Display version information
   help        Help about any command
 
@@ -36,7 +49,12 @@ Use 'sova init' to create a new project with your desired settings.`,
 var templateFS fs.FS
 
 func Execute() error {
-	return rootCmd.Execute()
+	// Cancel the context on Ctrl-C so that commands can clean up partial
+	// output instead of being killed halfway through writing it
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
@@ -44,20 +62,21 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.sova.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
+	rootCmd.PersistentFlags().StringSliceVar(&templateDirs, "template-dir", nil, "directory with templates that override or extend the built-in ones (repeatable)")
 
 	rootCmd.Flags().BoolP("version", "V", false, "display version information")
 
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.SilenceErrors = true
 
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
-
-	// Initialize template filesystem
-	templateFS = templates.GetTemplateFS()
 }
 
 func initConfig() {
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
+	} else if path := os.Getenv("SOVA_CONFIG"); path != "" {
+		viper.SetConfigFile(path)
 	} else {
 		home, err := os.UserHomeDir()
 		cobra.CheckErr(err)
@@ -68,12 +87,50 @@ func initConfig() {
 	}
 
 	viper.AutomaticEnv()
+	config.BindEnv(viper.GetViper())
 
 	if err := viper.ReadInConfig(); err == nil {
 		if verbose {
 			fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 		}
 	}
+
+	initTemplates()
+}
+
+// initTemplates overlays the template directories from --template-dir and
+// templates.directory, and the templates installed with `sova template
+// install`, on the embedded templates
+func initTemplates() {
+	dirs := append([]string(nil), templateDirs...)
+	dirs = append(dirs, config.TemplateDirs(viper.GetViper())...)
+	for i, dir := range dirs {
+		dirs[i] = utils.ExpandPath(dir)
+		if i < len(templateDirs) && !utils.DirExists(dirs[i]) {
+			PrintWarning("Template directory %s does not exist", dirs[i])
+		}
+	}
+	if storeDir, err := project.DefaultTemplateStoreDir(); err == nil && !containsDir(dirs, storeDir) {
+		dirs = append(dirs, storeDir)
+	}
+
+	templates.SetTemplateDirs(dirs)
+	templateFS = templates.GetTemplateFS()
+
+	if verbose {
+		for _, dir := range templates.TemplateDirs() {
+			fmt.Fprintln(os.Stderr, "Using template directory:", dir)
+		}
+	}
+}
+
+func containsDir(dirs []string, dir string) bool {
+	for _, d := range dirs {
+		if filepath.Clean(d) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	return false
 }
 
 func PrintSuccess(format string, a ...interface{}) {
@@ -84,12 +141,14 @@ func PrintInfo(format string, a ...interface{}) {
 	color.Blue(format, a...)
 }
 
+// PrintWarning writes to stderr so that it never mixes with output meant to
+// be piped, such as `sova init --dry-run --show`
 func PrintWarning(format string, a ...interface{}) {
-	color.Yellow(format, a...)
+	color.New(color.FgYellow).Fprintln(os.Stderr, fmt.Sprintf(format, a...))
 }
 
 func PrintError(format string, a ...interface{}) {
-	color.Red(format, a...)
+	color.New(color.FgRed).Fprintln(os.Stderr, fmt.Sprintf(format, a...))
 }
 
 // GetTemplate returns the contents of a template file
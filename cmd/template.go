@@ -0,0 +1,505 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-sova/sova-cli/internal/golden"
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/internal/remote"
+	"github.com/go-sova/sova-cli/pkg/utils"
+	"github.com/go-sova/sova-cli/templates"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	templateInstallName  string
+	templateInstallForce bool
+	templateTestGolden   string
+	templateTestUpdate   bool
+)
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "List, inspect, install and remove templates",
+	Long: `List, inspect, install and remove project templates.
+
+Templates come from three places, in order of precedence:
+  local      directories given with --template-dir or templates.directory
+  installed  templates installed with 'sova template install' (~/.sova/templates)
+  embedded   the api and cli templates built into sova
+
+Examples:
+  sova template list
+  sova template show api
+  sova template lint
+  sova template test ./worker
+  sova template install git+https://github.com/acme/templates.git@v1.2.0
+  sova template remove worker`,
+}
+
+var templateListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the available templates",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		infos, err := project.NewTemplateManager().Templates()
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVERSION\tSOURCE\tDESCRIPTION")
+		for _, info := range infos {
+			version := info.Version
+			if version == "" {
+				version = "-"
+			}
+			source := info.Source
+			if info.Location != "" {
+				source = fmt.Sprintf("%s (%s)", info.Source, info.Location)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", info.Name, version, source, info.Description)
+		}
+		return w.Flush()
+	},
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:          "show <name>",
+	Short:        "Print the manifest and files of a template",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		info, err := project.NewTemplateManager().Template(name)
+		if err != nil {
+			return err
+		}
+		fsys := templates.GetTemplateFS()
+		manifest, err := templates.LoadManifestFS(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Name:    %s\n", info.Name)
+		if info.Version != "" {
+			fmt.Printf("Version: %s\n", info.Version)
+		}
+		fmt.Printf("Source:  %s\n", info.Source)
+		if manifest.Extends != "" {
+			fmt.Printf("Extends: %s\n", manifest.Extends)
+		}
+		if info.Location != "" {
+			fmt.Printf("From:    %s\n", info.Location)
+		}
+
+		fmt.Printf("\n%s:\n", templates.ManifestFile)
+		content, err := fs.ReadFile(fsys, path.Join(name, templates.ManifestFile))
+		if err != nil {
+			// Templates without a manifest are rendered by convention
+			if content, err = yaml.Marshal(manifest); err != nil {
+				return err
+			}
+			fmt.Println("# generated: this template has no template.yaml")
+		}
+		fmt.Print(string(content))
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			fmt.Println()
+		}
+
+		files, err := templates.TemplateFiles(name)
+		if err != nil {
+			return err
+		}
+		// Files inherited from the project type this one extends are listed
+		// with the path of their project type
+		targets := make(map[string][]string)
+		var inherited []string
+		for _, file := range manifest.Files {
+			source := file.Template(name)
+			if !strings.HasPrefix(source, name+"/") {
+				if len(targets[source]) == 0 {
+					inherited = append(inherited, source)
+				}
+				targets[source] = append(targets[source], file.Target)
+				continue
+			}
+			targets[file.Source] = append(targets[file.Source], file.Target)
+		}
+
+		tree := &project.Plan{ProjectName: name}
+		for _, file := range files {
+			content, err := fs.ReadFile(fsys, path.Join(name, file))
+			if err != nil {
+				return err
+			}
+			target := "not rendered"
+			if len(targets[file]) > 0 {
+				target = "-> " + strings.Join(targets[file], ", ")
+			}
+			tree.AddFile(file, target, content)
+		}
+		for _, source := range inherited {
+			content, err := fs.ReadFile(fsys, source)
+			if err != nil {
+				return err
+			}
+			tree.AddFile("../"+source, "-> "+strings.Join(targets[source], ", "), content)
+		}
+
+		fmt.Println()
+		tree.Print(os.Stdout)
+		return nil
+	},
+}
+
+var templateValidateCmd = &cobra.Command{
+	Use:   "validate [name|source]...",
+	Short: "Check that templates have a valid manifest and parse",
+	Long: `Check that templates have a valid manifest, that every file the manifest
+refers to exists, and that every template parses.
+
+Without arguments every available template is validated. Arguments are
+template names, or sources as accepted by 'sova template install', e.g. a
+template directory you are working on.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manager := project.NewTemplateManager()
+
+		if len(args) == 0 {
+			names, err := manager.ListTemplates()
+			if err != nil {
+				return err
+			}
+			args = names
+		}
+
+		results := make(map[string]error)
+		var names []string
+		for _, arg := range args {
+			if !remote.IsSource(arg) {
+				results[arg] = manager.ValidateTemplate(arg)
+				names = append(names, arg)
+				continue
+			}
+
+			source, err := parseTemplateSource(arg)
+			if err != nil {
+				return err
+			}
+			cacheDir, err := remote.DefaultCacheDir()
+			if err != nil {
+				return fmt.Errorf("failed to locate template cache: %v", err)
+			}
+			sourceResults, err := project.ValidateSource(cmd.Context(), source, cacheDir)
+			if err != nil {
+				return err
+			}
+			var sourceNames []string
+			for name, err := range sourceResults {
+				label := fmt.Sprintf("%s (%s)", name, arg)
+				results[label] = err
+				sourceNames = append(sourceNames, label)
+			}
+			sort.Strings(sourceNames)
+			names = append(names, sourceNames...)
+		}
+
+		failed := 0
+		for _, name := range names {
+			if err := results[name]; err != nil {
+				failed++
+				PrintError("✗ %s: %v", name, err)
+				continue
+			}
+			PrintSuccess("✓ %s", name)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d templates are invalid", failed, len(names))
+		}
+		return nil
+	},
+}
+
+var templateLintCmd = &cobra.Command{
+	Use:   "lint [name|source]...",
+	Short: "Render templates with every combination of answers and check the output",
+	Long: `Render templates with every combination of answers to their prompts and
+check the generated files:
+  .go            parses, imports every package it uses
+  .yml, .yaml    parses, has no empty blocks
+  go.mod         parses
+
+Every combination that produces a broken file is reported. Without arguments
+every available template is linted. Arguments are template names, or sources
+as accepted by 'sova template install'.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			names, err := project.NewTemplateManager().ListTemplates()
+			if err != nil {
+				return err
+			}
+			args = names
+		}
+
+		var reports []*project.LintReport
+		for _, arg := range args {
+			if !remote.IsSource(arg) {
+				report, err := project.LintTemplate(arg)
+				if err != nil {
+					return err
+				}
+				reports = append(reports, report)
+				continue
+			}
+
+			source, err := parseTemplateSource(arg)
+			if err != nil {
+				return err
+			}
+			cacheDir, err := remote.DefaultCacheDir()
+			if err != nil {
+				return fmt.Errorf("failed to locate template cache: %v", err)
+			}
+			sources, err := project.LoadSource(cmd.Context(), source, cacheDir)
+			if err != nil {
+				return err
+			}
+			names := make([]string, 0, len(sources))
+			for name := range sources {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				report, err := project.LintTemplateFS(sources[name], name)
+				if err != nil {
+					return err
+				}
+				reports = append(reports, report)
+			}
+		}
+
+		failed := 0
+		for _, report := range reports {
+			if len(report.Failures) == 0 {
+				PrintSuccess("✓ %s: %d combinations", report.Template, report.Combinations)
+				continue
+			}
+
+			failed++
+			PrintError("✗ %s: %d of %d combinations are broken", report.Template, len(report.Failures), report.Combinations)
+			for _, failure := range report.Failures {
+				fmt.Fprintf(os.Stderr, "  %s\n", failure.Label)
+				for _, problem := range failure.Problems {
+					fmt.Fprintf(os.Stderr, "    %s\n", problem)
+				}
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d templates failed linting", failed, len(reports))
+		}
+		return nil
+	},
+}
+
+var templateTestCmd = &cobra.Command{
+	Use:   "test <name|dir>",
+	Short: "Compare the projects a template generates with its golden files",
+	Long: `Render a template with the answers of every test case and compare the
+generated project with the golden files of the case.
+
+The testdata directory of a template holds one answers file per case, in the
+format read by 'sova init --answers', next to the expected project of the case
+with a .golden suffix on every file:
+
+  worker/testdata/default.yaml
+  worker/testdata/default/main.go.golden
+
+Without answers files there is a single case, "default", that answers every
+prompt with its default. With --update the golden files are rewritten from the
+generated projects, so that changes to a template can be reviewed as changes
+to its golden files.
+
+The argument is a template directory or the name of a template from a
+template directory. Embedded templates need --golden.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targets, err := templateTestTargets(args[0])
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, target := range targets {
+			results, err := golden.Run(cmd.Context(), target.ProjectType, target.Dir, templateTestUpdate)
+			if err != nil {
+				return err
+			}
+
+			for _, result := range results {
+				name := fmt.Sprintf("%s/%s", target.ProjectType, result.Case)
+				switch {
+				case result.Updated:
+					PrintSuccess("Updated %s", filepath.Join(target.Dir, result.Case))
+				case len(result.Differences) == 0:
+					PrintSuccess("✓ %s", name)
+				default:
+					failed++
+					PrintError("✗ %s", name)
+					for _, difference := range result.Differences {
+						fmt.Fprintf(os.Stderr, "  %s\n", difference)
+					}
+				}
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d cases differ from their golden files (use --update to accept the changes)", failed)
+		}
+		return nil
+	},
+}
+
+// templateTestTargets returns the project types to test for the argument of
+// `sova template test` and their golden directories
+func templateTestTargets(arg string) ([]golden.Target, error) {
+	if !remote.IsSource(arg) {
+		if _, err := project.NewTemplateManager().Template(arg); err != nil {
+			return nil, err
+		}
+		dir := templateTestGolden
+		if dir == "" {
+			templateDir, ok := templates.TemplateDir(arg)
+			if !ok {
+				return nil, fmt.Errorf("template %s is embedded; use --golden to give its golden directory", arg)
+			}
+			dir = filepath.Join(templateDir, arg, templates.TestDataDir)
+		}
+		return []golden.Target{{ProjectType: arg, Dir: dir}}, nil
+	}
+
+	source, err := parseTemplateSource(arg)
+	if err != nil {
+		return nil, err
+	}
+	if source.Kind != remote.KindDir {
+		return nil, fmt.Errorf("%s is not a directory; golden files can only be kept next to a template on disk", arg)
+	}
+	targets, err := golden.AddSource(source.Location)
+	if err != nil {
+		return nil, err
+	}
+	if templateTestGolden != "" {
+		if len(targets) > 1 {
+			return nil, fmt.Errorf("%s contains several templates; --golden only applies to a single template", arg)
+		}
+		targets[0].Dir = templateTestGolden
+	}
+	return targets, nil
+}
+
+var templateInstallCmd = &cobra.Command{
+	Use:   "install <source>",
+	Short: "Install a template into the local template store",
+	Long: `Install a template from a git repository, an archive or a directory into
+~/.sova/templates, after which it can be used with 'sova init --type <name>'.
+
+Sources:
+  git+https://github.com/acme/templates.git@v1.2.0   git repository at a tag, branch or commit
+  ./service.tar.gz, https://example.com/service.zip   .tar.gz, .tgz or .zip archive
+  ./service                                          directory
+
+A source with a template.yaml at its root is installed as a single template,
+named after the manifest (or --name). Any other source installs one template
+per subdirectory.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		source, err := parseTemplateSource(args[0])
+		if err != nil {
+			return err
+		}
+
+		storeDir, err := project.DefaultTemplateStoreDir()
+		if err != nil {
+			return fmt.Errorf("failed to locate template store: %v", err)
+		}
+		cacheDir, err := remote.DefaultCacheDir()
+		if err != nil {
+			return fmt.Errorf("failed to locate template cache: %v", err)
+		}
+
+		if source.Kind != remote.KindDir {
+			fmt.Fprintf(os.Stderr, "Fetching template %s\n", source)
+		}
+		installed, err := project.NewTemplateStore(storeDir).Install(cmd.Context(), source, cacheDir, templateInstallName, templateInstallForce)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range installed {
+			PrintSuccess("Installed template %s into %s", entry.Name, storeDir)
+			if dir, ok := templates.TemplateDir(entry.Name); ok && !containsDir([]string{storeDir}, dir) {
+				PrintWarning("Template %s from %s takes precedence over the installed one", entry.Name, dir)
+			}
+		}
+		return nil
+	},
+}
+
+var templateRemoveCmd = &cobra.Command{
+	Use:          "remove <name>",
+	Aliases:      []string{"rm"},
+	Short:        "Remove an installed template",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		storeDir, err := project.DefaultTemplateStoreDir()
+		if err != nil {
+			return fmt.Errorf("failed to locate template store: %v", err)
+		}
+
+		if err := project.NewTemplateStore(storeDir).Remove(args[0]); err != nil {
+			return err
+		}
+		PrintSuccess("Removed template %s", args[0])
+		return nil
+	},
+}
+
+func parseTemplateSource(ref string) (*remote.Source, error) {
+	source, err := remote.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+	if source.Kind == remote.KindDir {
+		source.Location = utils.ExpandPath(source.Location)
+	}
+	return source, nil
+}
+
+func init() {
+	templateTestCmd.Flags().StringVar(&templateTestGolden, "golden", "", "directory with the test cases and golden files (default <template>/testdata)")
+	templateTestCmd.Flags().BoolVar(&templateTestUpdate, "update", false, "rewrite the golden files from the generated projects")
+	templateInstallCmd.Flags().StringVar(&templateInstallName, "name", "", "install a single template under this name")
+	templateInstallCmd.Flags().BoolVar(&templateInstallForce, "force", false, "replace a template that is already installed")
+
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateShowCmd)
+	templateCmd.AddCommand(templateValidateCmd)
+	templateCmd.AddCommand(templateLintCmd)
+	templateCmd.AddCommand(templateTestCmd)
+	templateCmd.AddCommand(templateInstallCmd)
+	templateCmd.AddCommand(templateRemoveCmd)
+	rootCmd.AddCommand(templateCmd)
+}
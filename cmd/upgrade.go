@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeDir        string
+	upgradeYes        bool
+	upgradeSkipTidy   bool
+	upgradeOnConflict string
+	upgradeTemplate   string
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Apply template improvements to an existing project",
+	Long: `Re-render a project generated by sova from the answers recorded in its
+.sova.lock with the current templates, and merge the changes into the
+project.
+
+The changes are three-way merged: sova compares the new render with the
+render the project was generated from (kept in .sova/base) and applies the
+difference to your files. Files you have not edited are simply replaced;
+files you have edited get the template changes merged in. Where you and the
+template changed the same lines, both versions are kept between git-style
+conflict markers:
+  <<<<<<< current
+  your version
+  =======
+  the template's version
+  >>>>>>> template api 1.1.0
+
+Use --on-conflict to resolve such files differently: skip keeps your
+version, overwrite replaces it with the template's, backup saves it with a
+.bak suffix first, and prompt shows a diff of every conflicting file and
+asks. Files that merge cleanly are merged either way.
+
+Questions added to the template since the project was generated are asked,
+or answered with their defaults with --yes. The command exits with an error
+when conflicts are left to resolve.
+
+A template that was fetched with sova init --template is fetched again from
+the location recorded in .sova.lock when it is not available locally. Use
+--template to fetch it from somewhere else, e.g. a newer tag or a location
+it has moved to:
+  sova upgrade --template git+https://github.com/acme/templates.git@v1.3.0`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		onConflict, err := conflictResolver(upgradeOnConflict)
+		if err != nil {
+			return err
+		}
+
+		lock, err := project.ReadLock(upgradeDir)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s in %s: only projects generated by sova can be upgraded", project.LockFile, upgradeDir)
+		}
+		if err != nil {
+			return err
+		}
+		if err := useProjectTemplate(cmd.Context(), lock, upgradeTemplate); err != nil {
+			return err
+		}
+		answers, err := lock.ProjectAnswers()
+		if err != nil {
+			return err
+		}
+
+		preset, err := questions.PresetFromAnswers(answers)
+		if err != nil {
+			return err
+		}
+		if upgradeYes {
+			if err := preset.ApplyDefaults(answers.ProjectType); err != nil {
+				return err
+			}
+		}
+		updated, err := questions.AskProjectQuestionsWithPreset(answers.ProjectType, preset)
+		if err != nil {
+			return fmt.Errorf("failed to get project configuration: %v (use --yes to accept the defaults of new questions)", err)
+		}
+
+		base, err := project.ReadBase(upgradeDir)
+		if os.IsNotExist(err) {
+			PrintWarning("%s has no %s to merge against; the new version of every file you edited will be written next to it", upgradeDir, project.BaseDir)
+			base, err = &project.Plan{}, nil
+		}
+		if err != nil {
+			return err
+		}
+		base.Lock = lock
+
+		plan, err := planProject(cmd.Context(), answers.ProjectName, updated)
+		if err != nil {
+			return err
+		}
+		plan.OnConflict = onConflict
+
+		changes, err := plan.Upgrade(cmd.Context(), upgradeDir, base)
+		summary := printChanges(upgradeDir, changes)
+		if err != nil {
+			return fmt.Errorf("failed to upgrade project: %v", err)
+		}
+		if err := recordChanges(cmd.Context(), upgradeDir, plan, base, changes, upgradeSkipTidy); err != nil {
+			return err
+		}
+
+		template := plan.Lock.Template.Name + " " + plan.Lock.Template.Version
+		if len(changes) == 0 {
+			PrintSuccess("%s is up to date with template %s", answers.ProjectName, template)
+			return nil
+		}
+
+		if summary.skipped > 0 {
+			fmt.Printf("\n%d clean, %d merged, %d skipped, %d conflicting\n", summary.clean, summary.merged, summary.skipped, summary.conflicts)
+		} else {
+			fmt.Printf("\n%d clean, %d merged, %d conflicting\n", summary.clean, summary.merged, summary.conflicts)
+		}
+		if summary.conflicts > 0 {
+			return fmt.Errorf("upgrade to template %s left %d conflicting files", template, summary.conflicts)
+		}
+		PrintSuccess("Upgraded %s to template %s", answers.ProjectName, template)
+		return nil
+	},
+}
+
+func init() {
+	upgradeCmd.Flags().StringVar(&upgradeDir, "dir", ".", "directory of the project")
+	upgradeCmd.Flags().BoolVarP(&upgradeYes, "yes", "y", false, "accept defaults for every new question")
+	upgradeCmd.Flags().BoolVar(&upgradeSkipTidy, "skip-tidy", false, "don't run go mod tidy after go.mod changes")
+	upgradeCmd.Flags().StringVar(&upgradeOnConflict, "on-conflict", "", onConflictUsage)
+	upgradeCmd.Flags().StringVar(&upgradeTemplate, "template", "", templateOverrideUsage)
+	rootCmd.AddCommand(upgradeCmd)
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// Keys understood by sova in ~/.sova.yaml
+const (
+	KeyAuthor       = "defaults.author"
+	KeyLicense      = "defaults.license"
+	KeyGoVersion    = "defaults.goVersion"
+	KeyTemplate     = "defaults.template"
+	KeyModulePrefix = "defaults.modulePrefix"
+	// KeyTemplateDirectory holds one directory, several separated by the
+	// OS path list separator, or a YAML list of directories
+	KeyTemplateDirectory = "templates.directory"
+)
+
+// envBindings maps configuration keys to the environment variables that
+// override them.
+var envBindings = map[string]string{
+	KeyAuthor:       "SOVA_DEFAULT_AUTHOR",
+	KeyLicense:      "SOVA_DEFAULT_LICENSE",
+	KeyGoVersion:    "SOVA_DEFAULT_GO_VERSION",
+	KeyTemplate:     "SOVA_DEFAULT_TEMPLATE",
+	KeyModulePrefix: "SOVA_DEFAULT_MODULE_PREFIX",
+}
+
+// builtinDefaults apply when neither the config file nor the environment set a key.
+var builtinDefaults = map[string]string{
+	KeyLicense:   "MIT",
+	KeyGoVersion: "1.21",
+	KeyTemplate:  "api",
+}
+
+// Defaults are the values new projects are generated with unless a flag or
+// answers file says otherwise.
+type Defaults struct {
+	Author       string
+	License      string
+	GoVersion    string
+	Template     string
+	ModulePrefix string
+}
+
+// KnownKeys returns the configuration keys sova reads, sorted.
+func KnownKeys() []string {
+	keys := make([]string, 0, len(envBindings)+1)
+	for key := range envBindings {
+		keys = append(keys, key)
+	}
+	keys = append(keys, KeyTemplateDirectory)
+	sort.Strings(keys)
+	return keys
+}
+
+// ValidateKey returns an error listing the known keys when key, in any
+// case, is not one of them
+func ValidateKey(key string) error {
+	for _, known := range KnownKeys() {
+		if strings.EqualFold(known, key) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown configuration key %q (valid keys: %s)", key, strings.Join(KnownKeys(), ", "))
+}
+
+// EnvVar returns the environment variable that overrides key, if any.
+func EnvVar(key string) (string, bool) {
+	for known, env := range envBindings {
+		if strings.EqualFold(known, key) {
+			return env, true
+		}
+	}
+	return "", false
+}
+
+// BindEnv registers the SOVA_* environment variables and the built-in
+// defaults with v.
+func BindEnv(v *viper.Viper) {
+	for key, env := range envBindings {
+		v.BindEnv(key, env)
+	}
+	for key, value := range builtinDefaults {
+		v.SetDefault(key, value)
+	}
+}
+
+// GetDefaults reads the project defaults from v. When no author is
+// configured, the git user name is used.
+func GetDefaults(v *viper.Viper) Defaults {
+	defaults := Defaults{
+		Author:       v.GetString(KeyAuthor),
+		License:      v.GetString(KeyLicense),
+		GoVersion:    v.GetString(KeyGoVersion),
+		Template:     v.GetString(KeyTemplate),
+		ModulePrefix: v.GetString(KeyModulePrefix),
+	}
+
+	if defaults.Author == "" {
+		if out, err := exec.Command("git", "config", "--get", "user.name").Output(); err == nil {
+			defaults.Author = strings.TrimSpace(string(out))
+		}
+	}
+
+	return defaults
+}
+
+// TemplateDirs returns the template directories configured in v. A string
+// value is split with filepath.SplitList rather than on whitespace, so that
+// `sova config set templates.directory "/path/with space"` is one directory.
+func TemplateDirs(v *viper.Viper) []string {
+	var dirs []string
+	switch value := v.Get(KeyTemplateDirectory).(type) {
+	case string:
+		dirs = filepath.SplitList(value)
+	case []string:
+		dirs = value
+	case []interface{}:
+		for _, dir := range value {
+			dirs = append(dirs, fmt.Sprint(dir))
+		}
+	}
+
+	var result []string
+	for _, dir := range dirs {
+		if strings.TrimSpace(dir) != "" {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
+// DefaultPath returns the configuration file used when --config is not
+// given: $SOVA_CONFIG, or ~/.sova.yaml.
+func DefaultPath() (string, error) {
+	if path := os.Getenv("SOVA_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".sova.yaml"), nil
+}
+
+// SetValue sets a dotted key such as defaults.author in the YAML file at
+// path, creating the file and any intermediate sections as needed. Comments
+// and the order of existing keys are preserved.
+func SetValue(path, key, value string) error {
+	var doc yaml.Node
+
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if len(bytes.TrimSpace(content)) > 0 {
+		if err := yaml.Unmarshal(content, &doc); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+
+	node := doc.Content[0]
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("cannot set %s: %s is not a section", key, strings.Join(parts[:i], "."))
+		}
+
+		var child *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if strings.EqualFold(node.Content[j].Value, part) {
+				child = node.Content[j+1]
+				break
+			}
+		}
+
+		last := i == len(parts)-1
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			if last {
+				child = &yaml.Node{}
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, child)
+		}
+
+		if last {
+			if child.Kind == yaml.MappingNode && len(child.Content) > 0 {
+				return fmt.Errorf("cannot set %s: it is a section", key)
+			}
+			child.Kind = yaml.ScalarNode
+			child.Tag = "!!str"
+			child.Value = value
+			child.Content = nil
+		}
+		node = child
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
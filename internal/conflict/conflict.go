@@ -0,0 +1,169 @@
+// Package conflict decides what happens when sova is about to write a file
+// over an existing one with different content.
+package conflict
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Policy says what to do with an existing file
+type Policy string
+
+const (
+	// Skip keeps the existing file
+	Skip Policy = "skip"
+	// Overwrite replaces the existing file
+	Overwrite Policy = "overwrite"
+	// Prompt shows the changes and asks, for every file
+	Prompt Policy = "prompt"
+	// Backup copies the existing file next to it (see BackupSuffix) and
+	// replaces it
+	Backup Policy = "backup"
+)
+
+// Policies lists every policy, in the order they are offered
+var Policies = []Policy{Skip, Overwrite, Prompt, Backup}
+
+// BackupSuffix is appended to the path of a file saved by the Backup policy
+const BackupSuffix = ".bak"
+
+// ParsePolicy parses the name of a policy
+func ParsePolicy(name string) (Policy, error) {
+	for _, policy := range Policies {
+		if string(policy) == name {
+			return policy, nil
+		}
+	}
+	names := make([]string, len(Policies))
+	for i, policy := range Policies {
+		names[i] = string(policy)
+	}
+	return "", fmt.Errorf("invalid conflict policy %q (expected %s)", name, strings.Join(names, ", "))
+}
+
+// AskFunc is asked what to do with the existing file at path under the
+// Prompt policy. current is its content and next the content sova would
+// write. It returns Skip, Overwrite or Backup.
+type AskFunc func(path string, current, next []byte) (Policy, error)
+
+// Result records what WriteFile did with an existing file
+type Result struct {
+	Path string
+	// Decision is Skip, Overwrite or Backup
+	Decision Policy
+	// Backup is the path the previous content was saved to
+	Backup string
+}
+
+// Resolver applies a policy to the files written with it. A nil Resolver
+// skips every existing file.
+type Resolver struct {
+	Policy Policy
+	Ask    AskFunc
+	// Results lists the existing files WriteFile was called for, in order
+	Results []Result
+}
+
+// NewResolver returns a resolver for policy. ask is only used by the Prompt
+// policy.
+func NewResolver(policy Policy, ask AskFunc) *Resolver {
+	return &Resolver{Policy: policy, Ask: ask}
+}
+
+// Resolve decides what to do with the existing file at path: Skip,
+// Overwrite or Backup
+func (r *Resolver) Resolve(path string, current, next []byte) (Policy, error) {
+	if r == nil {
+		return Skip, nil
+	}
+	if r.Policy != Prompt {
+		return r.Policy, nil
+	}
+	if r.Ask == nil {
+		return "", fmt.Errorf("%s already exists and there is no way to ask what to do with it", path)
+	}
+	decision, err := r.Ask(path, current, next)
+	if err != nil {
+		return "", err
+	}
+	if decision != Skip && decision != Overwrite && decision != Backup {
+		return "", fmt.Errorf("invalid decision %q for %s", decision, path)
+	}
+	return decision, nil
+}
+
+// WriteFile writes content to path. A file with other content already at
+// path is handled as Resolve decides, and the outcome is returned and
+// recorded in r.Results. The returned Result has no Decision when there
+// was no such file.
+func (r *Resolver) WriteFile(path string, content []byte) (Result, error) {
+	current, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return Result{}, fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return Result{}, fmt.Errorf("failed to write file %s: %w", path, err)
+		}
+		return Result{Path: path}, nil
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if bytes.Equal(current, content) {
+		return Result{Path: path}, nil
+	}
+
+	decision, err := r.Resolve(path, current, content)
+	if err != nil {
+		return Result{}, err
+	}
+	result := Result{Path: path, Decision: decision}
+	if decision == Backup {
+		if result.Backup, err = backup(path, current); err != nil {
+			return Result{}, err
+		}
+	}
+	if decision != Skip {
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			return Result{}, fmt.Errorf("failed to write file %s: %w", path, err)
+		}
+	}
+	if r != nil {
+		r.Results = append(r.Results, result)
+	}
+	return result, nil
+}
+
+// Lookup returns what WriteFile did with the existing file at path
+func (r *Resolver) Lookup(path string) (Result, bool) {
+	if r == nil {
+		return Result{}, false
+	}
+	for _, result := range r.Results {
+		if filepath.Clean(result.Path) == filepath.Clean(path) {
+			return result, true
+		}
+	}
+	return Result{}, false
+}
+
+// backup saves content next to path under the first free name ending in
+// BackupSuffix, so that an earlier backup is never overwritten
+func backup(path string, content []byte) (string, error) {
+	backupPath := path + BackupSuffix
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(backupPath); os.IsNotExist(err) {
+			break
+		}
+		backupPath = fmt.Sprintf("%s%s.%d", path, BackupSuffix, i)
+	}
+	if err := os.WriteFile(backupPath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	return backupPath, nil
+}
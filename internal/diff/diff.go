@@ -0,0 +1,116 @@
+// Package diff compares and merges text files line by line.
+package diff
+
+import "strings"
+
+// Op is the kind of an Edit
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Edit is one line of a diff. Delete lines come from the old text, Insert
+// lines from the new one and Equal lines from both.
+type Edit struct {
+	Op   Op
+	Text string
+}
+
+// Lines splits text into lines that keep their line terminator, so that
+// joining them gives back text. The last line has no terminator when text
+// does not end with a newline.
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// Diff returns the edits that turn a into b, using a longest common
+// subsequence of their lines
+func Diff(a, b []string) []Edit {
+	matches := match(a, b)
+
+	var edits []Edit
+	j := 0
+	for i, line := range a {
+		if matches[i] < 0 {
+			edits = append(edits, Edit{Op: Delete, Text: line})
+			continue
+		}
+		for ; j < matches[i]; j++ {
+			edits = append(edits, Edit{Op: Insert, Text: b[j]})
+		}
+		edits = append(edits, Edit{Op: Equal, Text: line})
+		j++
+	}
+	for ; j < len(b); j++ {
+		edits = append(edits, Edit{Op: Insert, Text: b[j]})
+	}
+	return edits
+}
+
+// match returns, for every line of a, the index of the line of b it is
+// matched with in a longest common subsequence, or -1
+func match(a, b []string) []int {
+	matches := make([]int, len(a))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	// Common prefixes and suffixes are matched without the quadratic table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		matches[len(a)-1-suffix] = len(b) - 1 - suffix
+		suffix++
+	}
+
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(a) == 0 || len(b) == 0 {
+		return matches
+	}
+
+	// lengths[i][j] is the length of the LCS of a[i:] and b[j:]
+	lengths := make([][]int32, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			matches[prefix+i] = prefix + j
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
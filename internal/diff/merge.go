@@ -0,0 +1,101 @@
+package diff
+
+// Conflict markers written by Merge, as git writes them
+const (
+	MarkerOurs   = "<<<<<<<"
+	MarkerSep    = "======="
+	MarkerTheirs = ">>>>>>>"
+)
+
+// MergeResult is the outcome of a three-way merge
+type MergeResult struct {
+	Lines []string
+	// Conflicts is the number of regions changed differently on both sides
+	Conflicts int
+}
+
+// Text joins the merged lines
+func (r *MergeResult) Text() string {
+	n := 0
+	for _, line := range r.Lines {
+		n += len(line)
+	}
+	text := make([]byte, 0, n)
+	for _, line := range r.Lines {
+		text = append(text, line...)
+	}
+	return string(text)
+}
+
+// Merge applies the changes from base to theirs to ours. Regions changed
+// on one side only take that side's version; regions changed the same way
+// on both sides are taken once. Regions changed differently on both sides
+// are conflicts and are written with git-style markers labelled with
+// oursLabel and theirsLabel.
+func Merge(base, ours, theirs []string, oursLabel, theirsLabel string) *MergeResult {
+	toOurs := match(base, ours)
+	toTheirs := match(base, theirs)
+
+	result := &MergeResult{}
+	i, j, k := 0, 0, 0
+	for {
+		// The next base line both sides kept is where the current region
+		// ends
+		next := i
+		for next < len(base) && (toOurs[next] < 0 || toTheirs[next] < 0) {
+			next++
+		}
+		nextOurs, nextTheirs := len(ours), len(theirs)
+		if next < len(base) {
+			nextOurs, nextTheirs = toOurs[next], toTheirs[next]
+		}
+
+		result.resolve(base[i:next], ours[j:nextOurs], theirs[k:nextTheirs], oursLabel, theirsLabel)
+
+		if next == len(base) {
+			return result
+		}
+		result.Lines = append(result.Lines, base[next])
+		i, j, k = next+1, nextOurs+1, nextTheirs+1
+	}
+}
+
+func (r *MergeResult) resolve(base, ours, theirs []string, oursLabel, theirsLabel string) {
+	switch {
+	case equal(ours, theirs), equal(theirs, base):
+		r.Lines = append(r.Lines, ours...)
+	case equal(ours, base):
+		r.Lines = append(r.Lines, theirs...)
+	default:
+		r.Conflicts++
+		r.Lines = append(r.Lines, MarkerOurs+" "+oursLabel+"\n")
+		r.Lines = appendTerminated(r.Lines, ours)
+		r.Lines = append(r.Lines, MarkerSep+"\n")
+		r.Lines = appendTerminated(r.Lines, theirs)
+		r.Lines = append(r.Lines, MarkerTheirs+" "+theirsLabel+"\n")
+	}
+}
+
+// appendTerminated appends lines, adding the newline the last line of a
+// file may lack so that the marker after it starts a line of its own
+func appendTerminated(dst, lines []string) []string {
+	for _, line := range lines {
+		if len(line) == 0 || line[len(line)-1] != '\n' {
+			line += "\n"
+		}
+		dst = append(dst, line)
+	}
+	return dst
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
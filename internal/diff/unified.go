@@ -0,0 +1,67 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unified formats the edits that turn a into b as a unified diff, as diff -u
+// prints it, with context unchanged lines around every change. It returns
+// "" when a and b are equal.
+func Unified(aName, bName string, a, b []string, context int) string {
+	edits := Diff(a, b)
+
+	// aLine and bLine hold the index of every edit in a and b
+	aLine := make([]int, len(edits)+1)
+	bLine := make([]int, len(edits)+1)
+	var changes []int
+	for i, edit := range edits {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if edit.Op != Insert {
+			aLine[i+1]++
+		}
+		if edit.Op != Delete {
+			bLine[i+1]++
+		}
+		if edit.Op != Equal {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+	for i := 0; i < len(changes); {
+		last := i
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*context {
+			last++
+		}
+		start := max(changes[i]-context, 0)
+		end := min(changes[last]+context+1, len(edits))
+		i = last + 1
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(aLine[start], aLine[end]), hunkRange(bLine[start], bLine[end]))
+		for _, edit := range edits[start:end] {
+			out.WriteString(map[Op]string{Equal: " ", Delete: "-", Insert: "+"}[edit.Op])
+			out.WriteString(edit.Text)
+			if !strings.HasSuffix(edit.Text, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return out.String()
+}
+
+// hunkRange formats the lines from start up to end of a hunk header
+func hunkRange(start, end int) string {
+	switch end - start {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, end-start)
+	}
+}
@@ -0,0 +1,312 @@
+// Package golden renders project types with fixed answers and compares the
+// result with a committed tree of golden files.
+//
+// A golden directory holds one answers file per case, in the format read by
+// `sova init --answers`, next to the expected project of that case:
+//
+//	testdata/
+//	├── default.yaml
+//	├── default/
+//	│   ├── go.mod.golden
+//	│   └── main.go.golden
+//	├── minimal.yaml
+//	└── minimal/
+//	    └── ...
+//
+// Every golden file carries a .golden suffix so that generated go.mod,
+// .gitignore and Go files have no effect on the repository they are
+// committed to. A directory without answers files has a single case,
+// "default", that answers every prompt with its default.
+package golden
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+// Suffix is appended to the name of every golden file
+const Suffix = ".golden"
+
+// DefaultCase is the name of the case used when a golden directory has no
+// answers files
+const DefaultCase = "default"
+
+// Case is a set of answers a project type is rendered with
+type Case struct {
+	Name    string
+	Answers *questions.Preset
+}
+
+// Difference is a file whose generated content does not match its golden file
+type Difference struct {
+	Path    string
+	Message string
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s: %s", d.Path, d.Message)
+}
+
+// Result is the outcome of one case
+type Result struct {
+	Case        string
+	Differences []Difference
+	// Updated is set when the golden files of the case were rewritten
+	Updated bool
+}
+
+// Target is a project type and the golden directory it is tested against
+type Target struct {
+	ProjectType string
+	Dir         string
+}
+
+// LoadCases reads the answers files in dir. Files ending in .yaml, .yml or
+// .json are cases named after the file.
+func LoadCases(dir string) ([]Case, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read golden directory: %w", err)
+	}
+
+	var cases []Case
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		preset, err := questions.LoadPreset(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, Case{Name: strings.TrimSuffix(entry.Name(), ext), Answers: preset})
+	}
+
+	if len(cases) == 0 {
+		cases = []Case{{Name: DefaultCase, Answers: &questions.Preset{}}}
+	}
+	return cases, nil
+}
+
+// Render generates projectType with the answers of c into a new directory
+// below dir and returns the project directory. Prompts the case does not
+// answer get their default; the project is named "example" and its module
+// is example.com/<name> unless the case says otherwise.
+func Render(ctx context.Context, projectType string, c Case, dir string) (string, error) {
+	preset := *c.Answers
+	if preset.ProjectType != "" && preset.ProjectType != projectType {
+		return "", fmt.Errorf("case %s is for project type %s, not %s", c.Name, preset.ProjectType, projectType)
+	}
+	projectName := preset.ProjectName
+	if projectName == "" {
+		projectName = "example"
+	}
+	if preset.ModulePath == "" {
+		preset.ModulePath = "example.com/" + projectName
+	}
+
+	if err := preset.ApplyDefaults(projectType); err != nil {
+		return "", err
+	}
+	answers, err := questions.AskProjectQuestionsWithPreset(projectType, &preset)
+	if err != nil {
+		return "", fmt.Errorf("case %s: %w", c.Name, err)
+	}
+
+	plan, err := project.PlanProject(templates.GetTemplateFS(), nil, projectName, answers)
+	if err != nil {
+		return "", fmt.Errorf("case %s: %w", c.Name, err)
+	}
+
+	// The golden files cover what the templates render, not the lock
+	projectDir := filepath.Join(dir, projectName)
+	if err := plan.Write(ctx, projectDir); err != nil {
+		return "", fmt.Errorf("case %s: %w", c.Name, err)
+	}
+	return projectDir, nil
+}
+
+// Compare compares every file below projectDir with the golden files in
+// goldenDir. Empty directories are not compared.
+func Compare(projectDir, goldenDir string) ([]Difference, error) {
+	generated, err := readTree(projectDir, "")
+	if err != nil {
+		return nil, err
+	}
+	golden, err := readTree(goldenDir, Suffix)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(generated)+len(golden))
+	for filePath := range generated {
+		paths = append(paths, filePath)
+	}
+	for filePath := range golden {
+		if _, ok := generated[filePath]; !ok {
+			paths = append(paths, filePath)
+		}
+	}
+	sort.Strings(paths)
+
+	var differences []Difference
+	for _, filePath := range paths {
+		got, isGenerated := generated[filePath]
+		want, isGolden := golden[filePath]
+		switch {
+		case !isGolden:
+			differences = append(differences, Difference{Path: filePath, Message: "generated but has no golden file"})
+		case !isGenerated:
+			differences = append(differences, Difference{Path: filePath, Message: "has a golden file but was not generated"})
+		case !bytes.Equal(got, want):
+			differences = append(differences, Difference{Path: filePath, Message: lineDifference(want, got)})
+		}
+	}
+	return differences, nil
+}
+
+// Update replaces the golden files in goldenDir with the files below
+// projectDir
+func Update(projectDir, goldenDir string) error {
+	generated, err := readTree(projectDir, "")
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(goldenDir); err != nil {
+		return fmt.Errorf("failed to remove golden files: %w", err)
+	}
+	for filePath, content := range generated {
+		target := filepath.Join(goldenDir, filepath.FromSlash(filePath)+Suffix)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to write golden files: %w", err)
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return fmt.Errorf("failed to write golden files: %w", err)
+		}
+	}
+	return nil
+}
+
+// Run renders projectType with every case in dir and compares it with the
+// golden files of the case, or rewrites them when update is set
+func Run(ctx context.Context, projectType, dir string, update bool) ([]Result, error) {
+	cases, err := LoadCases(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "sova-golden-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	var results []Result
+	for _, c := range cases {
+		projectDir, err := Render(ctx, projectType, c, filepath.Join(tmp, c.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		goldenDir := filepath.Join(dir, c.Name)
+		if update {
+			if err := Update(projectDir, goldenDir); err != nil {
+				return nil, err
+			}
+			results = append(results, Result{Case: c.Name, Updated: true})
+			continue
+		}
+
+		differences, err := Compare(projectDir, goldenDir)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, Result{Case: c.Name, Differences: differences})
+	}
+	return results, nil
+}
+
+// AddSource makes the templates in dir available, like `sova init
+// --template`, and returns every project type it provides with its
+// testdata directory
+func AddSource(dir string) ([]Target, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	types, err := templates.AddTemplateSource(dir, filepath.Base(abs))
+	if err != nil {
+		return nil, err
+	}
+
+	single := false
+	if _, err := os.Stat(filepath.Join(dir, templates.ManifestFile)); err == nil {
+		single = true
+	}
+
+	targets := make([]Target, 0, len(types))
+	for _, projectType := range types {
+		typeDir := filepath.Join(dir, projectType)
+		if single {
+			typeDir = dir
+		}
+		targets = append(targets, Target{ProjectType: projectType, Dir: filepath.Join(typeDir, templates.TestDataDir)})
+	}
+	return targets, nil
+}
+
+// readTree returns the content of every file below dir by slash-separated
+// path. Files without suffix are skipped and suffix is removed from the
+// paths of the others.
+func readTree(dir, suffix string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(filePath, suffix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files[strings.TrimSuffix(filepath.ToSlash(rel), suffix)] = content
+		return nil
+	})
+	return files, err
+}
+
+// lineDifference describes the first line on which got differs from want
+func lineDifference(want, got []byte) string {
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		switch {
+		case i >= len(wantLines):
+			return fmt.Sprintf("line %d: unexpected %q", i+1, gotLines[i])
+		case i >= len(gotLines):
+			return fmt.Sprintf("line %d: missing %q", i+1, wantLines[i])
+		case wantLines[i] != gotLines[i]:
+			return fmt.Sprintf("line %d: got %q, want %q", i+1, gotLines[i], wantLines[i])
+		}
+	}
+	return "differs"
+}
@@ -0,0 +1,200 @@
+// Package gosource formats generated Go source files.
+package gosource
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Error is a generated file that does not parse
+type Error struct {
+	Line int
+	// Text is the offending line
+	Text string
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s: %s", e.Line, e.Msg, strings.TrimSpace(e.Text))
+}
+
+// Format formats src like gofmt and fixes its imports the way goimports
+// does: imports that are certainly unused are removed, and the remaining
+// ones are sorted into groups for the standard library, other modules and
+// modulePath, separated by blank lines. Imports carrying comments are left
+// where they are. It returns an *Error when src does not parse.
+func Format(src []byte, modulePath string) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, syntaxError(src, err)
+	}
+
+	if fixed, ok := fixImports(fset, file, src, modulePath); ok {
+		src = fixed
+	}
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, syntaxError(src, err)
+	}
+	return formatted, nil
+}
+
+// fixImports rewrites the import declarations of file. It reports false when
+// it leaves src alone.
+func fixImports(fset *token.FileSet, file *ast.File, src []byte, modulePath string) ([]byte, bool) {
+	var decls []*ast.GenDecl
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			decls = append(decls, gen)
+		}
+	}
+	if len(decls) == 0 {
+		return nil, false
+	}
+
+	start := fset.Position(decls[0].Pos()).Offset
+	end := fset.Position(decls[len(decls)-1].End()).Offset
+	for _, group := range file.Comments {
+		if offset := fset.Position(group.Pos()).Offset; offset >= start && offset < end {
+			return nil, false
+		}
+	}
+
+	used := usedNames(file)
+	var groups [3][]string
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, false
+		}
+		if name, certain := ImportName(spec, modulePath); certain && !used[name] && name != "_" && name != "." {
+			continue
+		}
+
+		line := spec.Path.Value
+		if spec.Name != nil {
+			line = spec.Name.Name + " " + line
+		}
+
+		switch {
+		case isLocal(importPath, modulePath):
+			groups[2] = append(groups[2], line)
+		case isStandard(importPath):
+			groups[0] = append(groups[0], line)
+		default:
+			groups[1] = append(groups[1], line)
+		}
+	}
+
+	count := 0
+	var block bytes.Buffer
+	for _, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool { return importPathOf(group[i]) < importPathOf(group[j]) })
+		if block.Len() > 0 {
+			block.WriteString("\n")
+		}
+		for _, line := range group {
+			block.WriteString("\t" + line + "\n")
+		}
+		count += len(group)
+	}
+
+	var out bytes.Buffer
+	out.Write(src[:start])
+	switch {
+	case count == 1 && !(len(file.Imports) == 1 && decls[0].Lparen.IsValid()):
+		// A single import goes on one line unless it was written in parentheses
+		out.WriteString("import " + strings.TrimSpace(block.String()))
+	case count > 0:
+		out.WriteString("import (\n")
+		out.Write(block.Bytes())
+		out.WriteString(")")
+	}
+	out.Write(src[end:])
+	return out.Bytes(), true
+}
+
+// usedNames returns every identifier used as the package of a selector
+// expression that is not declared in the file
+func usedNames(file *ast.File) map[string]bool {
+	used := make(map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+	return used
+}
+
+var majorVersionSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// ImportName returns the name an import is referred to by and whether that
+// name is certain. Only named imports and standard library packages are
+// certain: the package name of any other module cannot be known without
+// downloading it, so it is guessed from the import path.
+func ImportName(spec *ast.ImportSpec, modulePath string) (string, bool) {
+	if spec.Name != nil {
+		return spec.Name.Name, true
+	}
+
+	importPath, _ := strconv.Unquote(spec.Path.Value)
+	elements := strings.Split(importPath, "/")
+	name := elements[len(elements)-1]
+	if majorVersionSuffix.MatchString(name) && len(elements) > 1 {
+		name = elements[len(elements)-2]
+	}
+	if isStandard(importPath) && !isLocal(importPath, modulePath) {
+		return name, true
+	}
+
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	return strings.NewReplacer("-", "", ".", "").Replace(name), false
+}
+
+// isStandard reports whether importPath belongs to the standard library,
+// whose first path element never contains a dot
+func isStandard(importPath string) bool {
+	return !strings.Contains(strings.SplitN(importPath, "/", 2)[0], ".")
+}
+
+func isLocal(importPath, modulePath string) bool {
+	return modulePath != "" && (importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/"))
+}
+
+func importPathOf(line string) string {
+	return line[strings.IndexByte(line, '"'):]
+}
+
+func syntaxError(src []byte, err error) error {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) || len(list) == 0 {
+		return err
+	}
+
+	first := list[0]
+	lines := strings.Split(string(src), "\n")
+	text := ""
+	if first.Pos.Line >= 1 && first.Pos.Line <= len(lines) {
+		text = lines[first.Pos.Line-1]
+	}
+	return &Error{Line: first.Pos.Line, Text: text, Msg: first.Msg}
+}
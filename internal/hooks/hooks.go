@@ -0,0 +1,313 @@
+// Package hooks runs the pre- and post-generate steps declared in a
+// template manifest.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/go-sova/sova-cli/pkg/utils"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+// Built-in steps. Any other command is run with the system shell.
+const (
+	// StepGofmt formats every Go file in the project
+	StepGofmt = "gofmt"
+	// StepGoModTidy runs go mod tidy
+	StepGoModTidy = "go mod tidy"
+	// StepGitInit initializes a git repository and commits the project
+	StepGitInit = "git init"
+)
+
+// StateDir is the directory in which sova keeps its own files in a project,
+// such as the base render. StepGofmt leaves it alone.
+const StateDir = ".sova"
+
+// InitialCommitMessage is the message of the commit created by StepGitInit
+const InitialCommitMessage = "Initial commit from sova"
+
+// IsBuiltin reports whether step is one of the built-in steps, which run
+// without a shell
+func IsBuiltin(step templates.HookStep) bool {
+	switch normalize(step.Command) {
+	case StepGofmt, StepGoModTidy, StepGitInit:
+		return true
+	}
+	return false
+}
+
+// ShellCommands returns the commands of the steps that are run with the
+// system shell
+func ShellCommands(steps ...[]templates.HookStep) []string {
+	var commands []string
+	for _, list := range steps {
+		for _, step := range list {
+			if !IsBuiltin(step) {
+				commands = append(commands, step.Command)
+			}
+		}
+	}
+	return commands
+}
+
+// IsGitInit reports whether step is the built-in StepGitInit
+func IsGitInit(step templates.HookStep) bool {
+	return normalize(step.Command) == StepGitInit
+}
+
+func normalize(command string) string {
+	return strings.Join(strings.Fields(command), " ")
+}
+
+// Runner runs hook steps in a project directory and streams their output
+// through its logger
+type Runner struct {
+	// NoGit skips the git init step
+	NoGit bool
+	// InExistingDir runs the steps other than git init when a project is
+	// generated into a directory that already existed; see ForExistingDir
+	InExistingDir bool
+	// ConfirmShell is asked once, with every shell command, whether the
+	// steps that run them may run; they are skipped when it returns false.
+	// A nil ConfirmShell runs them.
+	ConfirmShell func(commands []string) (bool, error)
+	logger       *utils.Logger
+
+	// shellAllowed is the answer of ConfirmShell, once it has been asked
+	shellAllowed *bool
+}
+
+func NewRunner() *Runner {
+	return &Runner{logger: utils.NewLoggerWithPrefix(utils.Info, "hooks")}
+}
+
+func (r *Runner) SetLogger(logger *utils.Logger) {
+	r.logger = logger
+}
+
+// ForExistingDir returns the steps of hooks that run when a project is
+// generated into dir, a directory that existed before, and logs the ones it
+// leaves out. The steps could change or commit files sova did not write, so
+// none run unless InExistingDir is set, and git init never does.
+func (r *Runner) ForExistingDir(dir string, hooks templates.Hooks) templates.Hooks {
+	keep := func(steps []templates.HookStep) []templates.HookStep {
+		var kept []templates.HookStep
+		for _, step := range steps {
+			switch {
+			case IsGitInit(step):
+				r.logger.Info("Skipping git init: %s already existed", dir)
+			case !r.InExistingDir:
+				r.logger.Warning("Skipping %s: %s already existed", step.Command, dir)
+			default:
+				kept = append(kept, step)
+			}
+		}
+		return kept
+	}
+	return templates.Hooks{PreGenerate: keep(hooks.PreGenerate), PostGenerate: keep(hooks.PostGenerate)}
+}
+
+// Confirm asks ConfirmShell about the shell commands of steps, unless it has
+// been asked already. Callers that run several lists of steps pass them all
+// up front, so that the user is asked once and before anything runs.
+func (r *Runner) Confirm(steps ...[]templates.HookStep) error {
+	if r.ConfirmShell == nil || r.shellAllowed != nil {
+		return nil
+	}
+	commands := ShellCommands(steps...)
+	if len(commands) == 0 {
+		return nil
+	}
+	allowed, err := r.ConfirmShell(commands)
+	if err != nil {
+		return err
+	}
+	r.shellAllowed = &allowed
+	return nil
+}
+
+// Run runs steps in order in dir and stops at the first step that fails
+func (r *Runner) Run(ctx context.Context, dir string, steps []templates.HookStep) error {
+	if err := r.Confirm(steps); err != nil {
+		return err
+	}
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		switch normalize(step.Command) {
+		case StepGofmt:
+			err = r.gofmt(dir)
+		case StepGoModTidy:
+			err = r.goModTidy(ctx, dir)
+		case StepGitInit:
+			err = r.gitInit(ctx, dir)
+		default:
+			if r.shellAllowed != nil && !*r.shellAllowed {
+				r.logger.Warning("Skipping %s", step.Command)
+				continue
+			}
+			r.logger.Info("Running %s", step.Command)
+			err = r.shell(ctx, dir, step.Command)
+		}
+		if err != nil {
+			return fmt.Errorf("hook %q failed: %w", step.Command, err)
+		}
+	}
+	return nil
+}
+
+func (r *Runner) gofmt(dir string) error {
+	r.logger.Info("Formatting Go files")
+	return filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if filePath == filepath.Join(dir, StateDir) {
+				return filepath.SkipDir
+			}
+			if filePath != dir && (d.Name() == ".git" || d.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(filePath, ".go") {
+			return nil
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		formatted, err := format.Source(content)
+		if err != nil {
+			rel, _ := filepath.Rel(dir, filePath)
+			return fmt.Errorf("%s: %w", filepath.ToSlash(rel), err)
+		}
+		if bytes.Equal(content, formatted) {
+			return nil
+		}
+		return os.WriteFile(filePath, formatted, 0644)
+	})
+}
+
+func (r *Runner) goModTidy(ctx context.Context, dir string) error {
+	if _, err := exec.LookPath("go"); err != nil {
+		r.logger.Warning("Skipping go mod tidy: go is not installed")
+		return nil
+	}
+	r.logger.Info("Running go mod tidy")
+	return r.exec(ctx, dir, "go", "mod", "tidy")
+}
+
+// gitInit initializes a repository in dir and commits everything in it, so
+// it must only run in a directory sova created. Nothing happens when dir is
+// already inside a repository, and the commit is skipped when git has no
+// identity configured.
+func (r *Runner) gitInit(ctx context.Context, dir string) error {
+	if r.NoGit {
+		return nil
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		r.logger.Warning("Skipping git init: git is not installed")
+		return nil
+	}
+	if exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--is-inside-work-tree").Run() == nil {
+		r.logger.Info("Skipping git init: %s is already inside a git repository", dir)
+		return nil
+	}
+
+	r.logger.Info("Initializing git repository")
+	if err := r.exec(ctx, dir, "git", "init", "--quiet"); err != nil {
+		return err
+	}
+	if err := r.exec(ctx, dir, "git", "add", "--all"); err != nil {
+		return err
+	}
+
+	if email, _ := exec.CommandContext(ctx, "git", "-C", dir, "config", "user.email").Output(); len(bytes.TrimSpace(email)) == 0 {
+		r.logger.Warning("Skipping the initial commit: git user.email is not set")
+		return nil
+	}
+	return r.exec(ctx, dir, "git", "commit", "--quiet", "-m", InitialCommitMessage)
+}
+
+func (r *Runner) shell(ctx context.Context, dir, command string) error {
+	if runtime.GOOS == "windows" {
+		return r.exec(ctx, dir, "cmd", "/C", command)
+	}
+	return r.exec(ctx, dir, "sh", "-c", command)
+}
+
+// exec runs a command in dir and logs its output line by line
+func (r *Runner) exec(ctx context.Context, dir, name string, args ...string) error {
+	output := &lineWriter{logger: r.logger}
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	err := cmd.Run()
+	output.Flush()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%s exited with status %d", name, exitErr.ExitCode())
+	}
+	return err
+}
+
+// lineWriter logs everything written to it, one line at a time. Stdout and
+// stderr of a command share one lineWriter, so writes are serialized.
+type lineWriter struct {
+	mu     sync.Mutex
+	logger *utils.Logger
+	buf    []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush logs any output that did not end in a newline
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.log(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *lineWriter) log(line []byte) {
+	if text := strings.TrimRight(string(line), "\r"); strings.TrimSpace(text) != "" {
+		w.logger.Info("  %s", text)
+	}
+}
@@ -0,0 +1,64 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/go-sova/sova-cli/internal/hooks"
+)
+
+// BaseDir holds a copy of every file as the templates rendered it, before
+// any hook or user changed it. sova upgrade merges the changes between it
+// and a render with newer templates into the project.
+const BaseDir = hooks.StateDir + "/base"
+
+// writeBase replaces the base render in projectDir with the files of p
+func (p *Plan) writeBase(projectDir string) error {
+	dir := filepath.Join(projectDir, filepath.FromSlash(BaseDir))
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", BaseDir, err)
+	}
+
+	for _, file := range p.Files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", file.Path, err)
+		}
+		if err := os.WriteFile(fullPath, file.Content, 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", file.Path, err)
+		}
+	}
+	return nil
+}
+
+// ReadBase returns the base render of the project in projectDir as a plan.
+// The error satisfies os.IsNotExist when the project has none.
+func ReadBase(projectDir string) (*Plan, error) {
+	dir := filepath.Join(projectDir, filepath.FromSlash(BaseDir))
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{}
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		plan.AddFile(rel, "", content)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", BaseDir, err)
+	}
+	return plan, nil
+}
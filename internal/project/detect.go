@@ -0,0 +1,119 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+	"golang.org/x/mod/modfile"
+)
+
+// DetectProject works out the project type the project in dir was
+// generated from and how its prompts were answered. Every combination of
+// answers of every project type is resolved, and the one whose files and
+// dependencies match the files in dir and the requirements in its go.mod
+// best wins. Answers that do not decide which files are
+// generated, such as input prompts, get their defaults. The module path and
+// Go version are read from go.mod; the project name is the name of dir.
+func DetectProject(dir string) (*questions.ProjectAnswers, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	goModPath := filepath.Join(absDir, "go.mod")
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a Go module: %w", dir, err)
+	}
+	modFile, err := modfile.ParseLax(goModPath, content, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", goModPath, err)
+	}
+	if modFile.Module == nil {
+		return nil, fmt.Errorf("%s has no module directive", goModPath)
+	}
+
+	answers := &questions.ProjectAnswers{
+		ProjectName: filepath.Base(absDir),
+		ModulePath:  modFile.Module.Mod.Path,
+	}
+	if modFile.Go != nil {
+		answers.GoVersion = modFile.Go.Version
+	}
+
+	required := make(map[string]bool, len(modFile.Require))
+	for _, req := range modFile.Require {
+		required[req.Mod.Path] = true
+	}
+
+	bestMismatches := -1
+	for _, projectType := range questions.ProjectTypes() {
+		manifest, err := templates.LoadManifest(projectType)
+		if err != nil {
+			continue
+		}
+		combinations, err := promptCombinations(manifest.Prompts)
+		if err != nil {
+			continue
+		}
+
+		exists := make(map[string]bool, len(manifest.Files))
+		for _, file := range manifest.Files {
+			_, err := os.Stat(filepath.Join(absDir, filepath.FromSlash(file.Target)))
+			exists[file.Target] = err == nil
+		}
+
+		for _, values := range combinations {
+			for _, prompt := range manifest.Prompts {
+				if prompt.Type == templates.PromptInput && values[prompt.Name] != prompt.ZeroValue() {
+					values[prompt.Name] = prompt.DefaultValue()
+				}
+			}
+
+			resolved, err := manifest.Resolve(projectType, AddPromptValues(map[string]interface{}{}, values))
+			if err != nil {
+				continue
+			}
+
+			// A project type only matches when most of the files it
+			// generates are there
+			found, mismatches := 0, 0
+			for target, present := range exists {
+				_, generated := resolved.Files[target]
+				if generated && present {
+					found++
+				}
+				if generated != present {
+					mismatches++
+				}
+			}
+			if found*2 <= len(resolved.Files) {
+				continue
+			}
+
+			dependencies := make(map[string]bool, len(resolved.Dependencies))
+			for _, dep := range resolved.Dependencies {
+				dependencies[dep.Name] = true
+			}
+			for _, dep := range manifest.Dependencies {
+				if dependencies[dep.Name] != required[dep.Name] {
+					mismatches++
+				}
+			}
+
+			if bestMismatches < 0 || mismatches < bestMismatches {
+				bestMismatches = mismatches
+				answers.ProjectType = projectType
+				answers.Values = values
+			}
+		}
+	}
+
+	if answers.ProjectType == "" {
+		return nil, fmt.Errorf("%s does not look like a project generated by sova", dir)
+	}
+	return answers, nil
+}
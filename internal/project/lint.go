@@ -0,0 +1,437 @@
+package project
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-sova/sova-cli/internal/gosource"
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+)
+
+// maxLintCombinations bounds the answer matrix a template is linted against
+const maxLintCombinations = 4096
+
+// LintProblem is a problem in one rendered file
+type LintProblem struct {
+	File     string
+	Template string
+	Message  string
+}
+
+func (p LintProblem) String() string {
+	switch {
+	case p.File == "":
+		return p.Message
+	case p.Template == "":
+		return fmt.Sprintf("%s: %s", p.File, p.Message)
+	default:
+		return fmt.Sprintf("%s (%s): %s", p.File, p.Template, p.Message)
+	}
+}
+
+// LintCombination is one set of answers and the problems found in the
+// project rendered with it
+type LintCombination struct {
+	Values   map[string]interface{}
+	Label    string
+	Problems []LintProblem
+}
+
+// LintReport is the result of linting a template
+type LintReport struct {
+	Template     string
+	Combinations int
+	Failures     []LintCombination
+}
+
+// LintTemplate lints the project type templateName of the current templates
+func LintTemplate(templateName string) (*LintReport, error) {
+	return LintTemplateFS(templates.GetTemplateFS(), templateName)
+}
+
+// LintTemplateFS renders the project type templateName of fsys with every
+// combination of answers to its prompts and checks the output: Go files
+// must parse and import every package they use;
+// YAML files must parse and have no empty blocks; go.mod must parse.
+func LintTemplateFS(fsys fs.FS, templateName string) (*LintReport, error) {
+	manifest, err := templates.LoadManifestFS(fsys, templateName)
+	if err != nil {
+		return nil, err
+	}
+	if err := manifest.Validate(fsys, templateName); err != nil {
+		return nil, err
+	}
+
+	combinations, err := promptCombinations(manifest.Prompts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", templateName, err)
+	}
+
+	loader, err := newLoader(fsys, templateName, manifest, nil)
+	if err != nil {
+		return nil, err
+	}
+	generator := templates.NewFileGenerator(loader)
+	report := &LintReport{Template: templateName, Combinations: len(combinations)}
+
+	for _, values := range combinations {
+		problems := lintRender(manifest, generator, templateName, values)
+		if len(problems) > 0 {
+			report.Failures = append(report.Failures, LintCombination{
+				Values:   values,
+				Label:    lintLabel(manifest.Prompts, values),
+				Problems: problems,
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// promptCombinations returns every combination of answers to prompts. Confirm
+// prompts are tried with both answers, select prompts with every option,
+// multiselect prompts with every subset of their options (or, with more than
+// four options, none, each one and all of them) and input prompts with their
+// default. A prompt whose condition is false only gets its zero value.
+func promptCombinations(prompts []templates.Prompt) ([]map[string]interface{}, error) {
+	combinations := []map[string]interface{}{{}}
+
+	for _, prompt := range prompts {
+		var next []map[string]interface{}
+		for _, values := range combinations {
+			ok, err := templates.EvalCondition(prompt.When, values)
+			if err != nil {
+				return nil, fmt.Errorf("prompt %s: %w", prompt.Name, err)
+			}
+
+			answers := []interface{}{prompt.ZeroValue()}
+			if ok {
+				answers = lintAnswers(prompt)
+			}
+			for _, answer := range answers {
+				combination := make(map[string]interface{}, len(values)+1)
+				for name, value := range values {
+					combination[name] = value
+				}
+				combination[prompt.Name] = answer
+				next = append(next, combination)
+			}
+		}
+
+		combinations = next
+		if len(combinations) > maxLintCombinations {
+			return nil, fmt.Errorf("more than %d answer combinations", maxLintCombinations)
+		}
+	}
+
+	return combinations, nil
+}
+
+func lintAnswers(prompt templates.Prompt) []interface{} {
+	switch prompt.Type {
+	case templates.PromptConfirm:
+		return []interface{}{false, true}
+
+	case templates.PromptSelect:
+		answers := make([]interface{}, len(prompt.Options))
+		for i, option := range prompt.Options {
+			answers[i] = option
+		}
+		return answers
+
+	case templates.PromptMultiSelect:
+		options := prompt.Options
+		if len(options) > 4 {
+			answers := []interface{}{[]string{}}
+			for _, option := range options {
+				answers = append(answers, []string{option})
+			}
+			return append(answers, append([]string(nil), options...))
+		}
+
+		var answers []interface{}
+		for mask := 0; mask < 1<<len(options); mask++ {
+			subset := []string{}
+			for i, option := range options {
+				if mask&(1<<i) != 0 {
+					subset = append(subset, option)
+				}
+			}
+			answers = append(answers, subset)
+		}
+		return answers
+
+	default:
+		answer := prompt.DefaultValue()
+		if answer == "" {
+			answer = "example"
+		}
+		return []interface{}{answer}
+	}
+}
+
+func lintLabel(prompts []templates.Prompt, values map[string]interface{}) string {
+	if len(prompts) == 0 {
+		return "(no prompts)"
+	}
+
+	parts := make([]string, 0, len(prompts))
+	for _, prompt := range prompts {
+		var value string
+		switch v := values[prompt.Name].(type) {
+		case []string:
+			value = "[" + strings.Join(v, ",") + "]"
+		case string:
+			value = strconv.Quote(v)
+		default:
+			value = fmt.Sprint(v)
+		}
+		parts = append(parts, prompt.Name+"="+value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// lintData returns the data a template is rendered with while linting
+func lintData(templateName string, values map[string]interface{}) map[string]interface{} {
+	return TemplateData("example", "An example project", &questions.ProjectAnswers{
+		ProjectType: templateName,
+		ModulePath:  "example.com/example",
+		GoVersion:   "1.21",
+		Author:      "Example Author",
+		License:     "MIT",
+		Values:      values,
+	})
+}
+
+func lintRender(manifest *templates.Manifest, generator *templates.FileGenerator, templateName string, values map[string]interface{}) []LintProblem {
+	data := lintData(templateName, values)
+	resolved, err := resolveManifest(manifest, templateName, data)
+	if err != nil {
+		return []LintProblem{{Message: err.Error()}}
+	}
+
+	targets := make([]string, 0, len(resolved.Files))
+	for target := range resolved.Files {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	var problems []LintProblem
+	rendered := make(map[string][]byte)
+	for _, target := range targets {
+		templatePath := resolved.Files[target]
+		content, err := generator.RenderFile(templatePath, target, data)
+		if err != nil {
+			problems = append(problems, LintProblem{File: target, Template: templatePath, Message: err.Error()})
+			continue
+		}
+		rendered[target] = content
+	}
+
+	goProblems := lintGoFiles(rendered, data["ModuleName"].(string))
+	for _, target := range targets {
+		content, ok := rendered[target]
+		if !ok {
+			continue
+		}
+
+		var messages []string
+		switch {
+		case strings.HasSuffix(target, ".go"):
+			messages = goProblems[target]
+		case strings.HasSuffix(target, ".yml") || strings.HasSuffix(target, ".yaml"):
+			messages = lintYAML(content)
+		case path.Base(target) == "go.mod":
+			messages = lintGoMod(target, content)
+		}
+		for _, message := range messages {
+			problems = append(problems, LintProblem{File: target, Template: resolved.Files[target], Message: message})
+		}
+	}
+
+	return problems
+}
+
+type goFile struct {
+	path string
+	file *ast.File
+}
+
+// lintGoFiles checks every .go file of a rendered project. Files are checked
+// together because an identifier may be declared in another file of the same
+// package.
+func lintGoFiles(rendered map[string][]byte, modulePath string) map[string][]string {
+	problems := make(map[string][]string)
+	fset := token.NewFileSet()
+
+	var files []goFile
+	packageNames := make(map[string]string)
+	declared := make(map[string]map[string]bool)
+
+	for target, content := range rendered {
+		if !strings.HasSuffix(target, ".go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, target, content, parser.ParseComments)
+		if err != nil {
+			problems[target] = append(problems[target], err.Error())
+			continue
+		}
+
+		dir := path.Dir(target)
+		packageNames[dir] = file.Name.Name
+		if declared[dir] == nil {
+			declared[dir] = make(map[string]bool)
+		}
+		for _, decl := range file.Decls {
+			for _, name := range declaredNames(decl) {
+				declared[dir][name] = true
+			}
+		}
+		files = append(files, goFile{path: target, file: file})
+	}
+
+	for _, f := range files {
+		problems[f.path] = append(problems[f.path], lintImports(f, modulePath, packageNames, declared[path.Dir(f.path)])...)
+	}
+	return problems
+}
+
+// lintImports reports imports a file does not use and package selectors it
+// uses without importing the package
+func lintImports(f goFile, modulePath string, packageNames map[string]string, declared map[string]bool) []string {
+	used := make(map[string]bool)
+	ast.Inspect(f.file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Obj == nil {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	var problems []string
+	imported := make(map[string]bool)
+	uncertain := false
+
+	for _, spec := range f.file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		if spec.Name != nil && (spec.Name.Name == "_" || spec.Name.Name == ".") {
+			uncertain = uncertain || spec.Name.Name == "."
+			continue
+		}
+
+		name, certain := importName(spec, importPath, modulePath, packageNames)
+		imported[name] = true
+		if used[name] {
+			continue
+		}
+		if certain {
+			problems = append(problems, fmt.Sprintf("%q imported and not used", importPath))
+		} else {
+			// The package name of a third-party module cannot be known for
+			// sure without downloading it
+			uncertain = true
+		}
+	}
+
+	if uncertain {
+		return problems
+	}
+
+	var undefined []string
+	for name := range used {
+		if !imported[name] && !declared[name] {
+			undefined = append(undefined, name)
+		}
+	}
+	sort.Strings(undefined)
+	for _, name := range undefined {
+		problems = append(problems, fmt.Sprintf("undefined: %s (missing import?)", name))
+	}
+	return problems
+}
+
+// importName returns the name an import is referred to by, and whether that
+// name is certain. Packages of the rendered project are looked up in
+// packageNames.
+func importName(spec *ast.ImportSpec, importPath, modulePath string, packageNames map[string]string) (string, bool) {
+	if spec.Name == nil && (importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")) {
+		dir := strings.TrimPrefix(strings.TrimPrefix(importPath, modulePath), "/")
+		if dir == "" {
+			dir = "."
+		}
+		if name, ok := packageNames[dir]; ok {
+			return name, true
+		}
+		return path.Base(importPath), false
+	}
+	return gosource.ImportName(spec, modulePath)
+}
+
+func declaredNames(decl ast.Decl) []string {
+	var names []string
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil {
+			names = append(names, d.Name.Name)
+		}
+	case *ast.GenDecl:
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					names = append(names, name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// lintYAML checks that content parses and that no top-level key is empty,
+// which is what a block whose entries were all left out by conditions
+// looks like
+func lintYAML(content []byte) []string {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return []string{err.Error()}
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var problems []string
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if value.Kind == yaml.ScalarNode && value.Tag == "!!null" && value.Value == "" {
+			problems = append(problems, fmt.Sprintf("line %d: %s is empty", key.Line, key.Value))
+		}
+	}
+	return problems
+}
+
+func lintGoMod(target string, content []byte) []string {
+	file, err := modfile.Parse(target, content, nil)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if file.Module == nil {
+		return []string{"missing module directive"}
+	}
+	return nil
+}
@@ -0,0 +1,329 @@
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-sova/sova-cli/internal/conflict"
+	"github.com/go-sova/sova-cli/internal/remote"
+	"github.com/go-sova/sova-cli/internal/version"
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/pkg/utils"
+	"github.com/go-sova/sova-cli/templates"
+	"gopkg.in/yaml.v3"
+)
+
+// LockFile is written into every generated project and records how it was
+// generated
+const LockFile = ".sova.lock"
+
+const lockHeader = "# Generated by sova, which reads this file to know how the project was\n# generated and which files were changed since. Do not edit it by hand.\n"
+
+// Lock is the content of LockFile
+type Lock struct {
+	// SovaVersion is the version of sova that generated the project
+	SovaVersion string       `yaml:"sovaVersion"`
+	Template    LockTemplate `yaml:"template"`
+	Project     LockProject  `yaml:"project"`
+	// Answers are the answers to the prompts of the template, by prompt name
+	Answers map[string]interface{} `yaml:"answers,omitempty"`
+	// Files maps the path of every generated file to the hash of the
+	// content sova wrote, see HashContent
+	Files map[string]string `yaml:"files"`
+}
+
+// LockTemplate identifies the template a project was generated from
+type LockTemplate struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version,omitempty"`
+	// Source is SourceEmbedded, SourceLocal or SourceRemote
+	Source string `yaml:"source,omitempty"`
+	// Location is the template source the template was fetched from, e.g.
+	// git+https://github.com/acme/templates.git@v1.2.0, or its directory
+	Location string `yaml:"location,omitempty"`
+	// Revision is the commit or archive hash that was fetched from Location
+	Revision string `yaml:"revision,omitempty"`
+}
+
+// LockProject holds the project metadata the templates were rendered with
+type LockProject struct {
+	Name      string `yaml:"name"`
+	Module    string `yaml:"module,omitempty"`
+	GoVersion string `yaml:"goVersion,omitempty"`
+	Author    string `yaml:"author,omitempty"`
+	License   string `yaml:"license,omitempty"`
+}
+
+// NewLock returns the lock of a project generated with answers by this
+// version of sova. Its files are recorded when the project is written.
+func NewLock(answers *questions.ProjectAnswers) *Lock {
+	lock := &Lock{
+		SovaVersion: version.GetInfo().Version,
+		Template:    LockTemplate{Name: answers.ProjectType},
+		Project: LockProject{
+			Name:      answers.ProjectName,
+			Module:    answers.ModulePath,
+			GoVersion: answers.GoVersion,
+			Author:    answers.Author,
+			License:   answers.License,
+		},
+		Answers: make(map[string]interface{}, len(answers.Values)),
+		Files:   make(map[string]string),
+	}
+	for name, value := range answers.Values {
+		lock.Answers[name] = value
+	}
+
+	if info, err := NewTemplateManager().Template(answers.ProjectType); err == nil {
+		lock.Template.Version = info.Version
+		lock.Template.Source = info.Source
+		if info.Source == SourceRemote {
+			lock.Template.Location = info.Location
+		}
+	}
+	return lock
+}
+
+// SetSource records that the template was fetched from the template source
+// ref, as given to sova init --template, at revision. Local paths are made
+// absolute so that the source can be found again from the project.
+func (l *Lock) SetSource(ref, revision string) error {
+	source, err := remote.Parse(ref)
+	if err != nil {
+		return err
+	}
+	if !source.IsRemote() && source.Kind != remote.KindGit {
+		location, err := filepath.Abs(utils.ExpandPath(source.Location))
+		if err != nil {
+			return err
+		}
+		source.Location = location
+	}
+
+	l.Template.Source = SourceLocal
+	if source.IsRemote() {
+		l.Template.Source = SourceRemote
+	}
+	l.Template.Location = source.String()
+	l.Template.Revision = revision
+	return nil
+}
+
+// ReadLock reads the LockFile of the project in projectDir. The error
+// satisfies os.IsNotExist when the project has none.
+func ReadLock(projectDir string) (*Lock, error) {
+	content, err := os.ReadFile(filepath.Join(projectDir, LockFile))
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lock{}
+	if err := yaml.Unmarshal(content, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", LockFile, err)
+	}
+	if lock.Template.Name == "" {
+		return nil, fmt.Errorf("invalid %s: no template name", LockFile)
+	}
+	if lock.Files == nil {
+		lock.Files = make(map[string]string)
+	}
+	return lock, nil
+}
+
+// Write writes the lock into projectDir
+func (l *Lock) Write(projectDir string) error {
+	content, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, LockFile), append([]byte(lockHeader), content...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", LockFile, err)
+	}
+	return nil
+}
+
+// ProjectAnswers returns the answers the project was generated with. The
+// answers are converted back to the types of the template prompts, since a
+// YAML list is read back as []interface{}.
+func (l *Lock) ProjectAnswers() (*questions.ProjectAnswers, error) {
+	answers := &questions.ProjectAnswers{
+		ProjectName: l.Project.Name,
+		ProjectType: l.Template.Name,
+		ModulePath:  l.Project.Module,
+		Author:      l.Project.Author,
+		License:     l.Project.License,
+		GoVersion:   l.Project.GoVersion,
+		Values:      make(map[string]interface{}, len(l.Answers)),
+	}
+
+	manifest, err := templates.LoadManifest(l.Template.Name)
+	if err != nil {
+		return nil, fmt.Errorf("template %s of the project is not available: %w", l.Template.Name, err)
+	}
+	for name, value := range l.Answers {
+		for _, prompt := range manifest.Prompts {
+			if prompt.Name != name {
+				continue
+			}
+			if coerced, err := prompt.Coerce(value); err == nil {
+				value = coerced
+			} else {
+				// Skipped prompts were answered with their zero value,
+				// which a select prompt does not accept
+				value = prompt.ZeroValue()
+			}
+		}
+		answers.Values[name] = value
+	}
+	return answers, nil
+}
+
+// HashFiles records the hash of every file in paths as it is in projectDir
+// now. Files that do not exist are left out.
+func (l *Lock) HashFiles(projectDir string, paths []string) error {
+	for _, filePath := range paths {
+		content, err := os.ReadFile(filepath.Join(projectDir, filepath.FromSlash(filePath)))
+		if os.IsNotExist(err) {
+			delete(l.Files, filePath)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		l.Files[filePath] = HashContent(content)
+	}
+	return nil
+}
+
+// Generated reports whether content is what sova generated for filePath
+func (l *Lock) Generated(filePath string, content []byte) bool {
+	hash, ok := l.Files[filePath]
+	return ok && hash == HashContent(content)
+}
+
+// FileState describes a generated file that is no longer as sova wrote it
+type FileState string
+
+const (
+	FileModified FileState = "modified"
+	FileDeleted  FileState = "deleted"
+)
+
+// FileStatus is a generated file found by Check
+type FileStatus struct {
+	Path  string
+	State FileState
+}
+
+// Check compares the generated files in projectDir with the hashes in the
+// lock and returns the files that were modified or deleted since
+func (l *Lock) Check(projectDir string) ([]FileStatus, error) {
+	paths := make([]string, 0, len(l.Files))
+	for filePath := range l.Files {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+
+	var statuses []FileStatus
+	for _, filePath := range paths {
+		content, err := os.ReadFile(filepath.Join(projectDir, filepath.FromSlash(filePath)))
+		switch {
+		case os.IsNotExist(err):
+			statuses = append(statuses, FileStatus{Path: filePath, State: FileDeleted})
+		case err != nil:
+			return nil, err
+		case !l.Generated(filePath, content):
+			statuses = append(statuses, FileStatus{Path: filePath, State: FileModified})
+		}
+	}
+	return statuses, nil
+}
+
+// HashContent returns the hash recorded in a lock for a file with content
+func HashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// RecordUpdate records p.Lock and the base render in projectDir after
+// Update or Upgrade applied p to a project generated from base. Files that
+// were created or overwritten are hashed as they are now; the others keep
+// the hash base recorded, so that edits made to them are still recognized
+// later. Every file gets p as its new base render, except files whose new
+// content was written next to them, which the user has yet to merge, and
+// files that were skipped. The source of the template recorded by base is
+// kept, so that it can be fetched again next time.
+func (p *Plan) RecordUpdate(projectDir string, base *Plan, changes []FileChange) error {
+	if p.Lock == nil {
+		return nil
+	}
+	if base.Lock != nil && base.Lock.Template.Location != "" {
+		p.Lock.Template.Source = base.Lock.Template.Source
+		p.Lock.Template.Location = base.Lock.Template.Location
+		p.Lock.Template.Revision = base.Lock.Template.Revision
+	}
+
+	byPath := make(map[string]FileChange, len(changes))
+	for _, change := range changes {
+		byPath[change.Path] = change
+	}
+
+	var hash []string
+	render := &Plan{}
+	for _, file := range p.Files {
+		change, changed := byPath[file.Path]
+		baseFile, inBase := base.File(file.Path)
+
+		switch {
+		case changed && (change.Action == FileCreated || change.Action == FileUpdated):
+			hash = append(hash, file.Path)
+		case base.Lock != nil:
+			if previous, ok := base.Lock.Files[file.Path]; ok {
+				p.Lock.Files[file.Path] = previous
+			}
+		case inBase:
+			p.Lock.Files[file.Path] = HashContent(baseFile.Content)
+		}
+
+		pending := change.NewPath != "" || change.Action == FileSkipped
+		switch {
+		case changed && pending && inBase:
+			render.AddFile(file.Path, baseFile.Template, baseFile.Content)
+		case changed && pending:
+		default:
+			render.AddFile(file.Path, file.Template, file.Content)
+		}
+	}
+
+	if err := p.Lock.HashFiles(projectDir, hash); err != nil {
+		return err
+	}
+	if err := render.writeBase(projectDir); err != nil {
+		return err
+	}
+	return p.Lock.Write(projectDir)
+}
+
+func (p *Plan) writeLock(projectDir string) error {
+	if p.Lock == nil {
+		return nil
+	}
+	paths := make([]string, 0, len(p.Files))
+	for _, file := range p.Files {
+		// A file kept by the conflict resolver is not what sova generated
+		result, ok := p.OnConflict.Lookup(filepath.Join(projectDir, filepath.FromSlash(file.Path)))
+		if ok && result.Decision == conflict.Skip {
+			p.Lock.Files[file.Path] = HashContent(file.Content)
+			continue
+		}
+		paths = append(paths, file.Path)
+	}
+	if err := p.Lock.HashFiles(projectDir, paths); err != nil {
+		return err
+	}
+	return p.Lock.Write(projectDir)
+}
@@ -0,0 +1,117 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"text/template"
+
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+func resolveManifest(manifest *templates.Manifest, projectType string, data map[string]interface{}) (*templates.ResolvedManifest, error) {
+	resolved, err := manifest.Resolve(projectType, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve manifest for %s: %w", projectType, err)
+	}
+
+	data["Dependencies"] = resolved.Dependencies
+	return resolved, nil
+}
+
+// newLoader returns a loader for the templates of projectType, described by
+// manifest, in fsys. The templates get the default functions, funcs and the
+// functions the manifest declares, in that order, and the partials of the
+// project types projectType extends.
+func newLoader(fsys fs.FS, projectType string, manifest *templates.Manifest, funcs template.FuncMap) (*templates.TemplateLoader, error) {
+	base := templates.FuncMap()
+	for name, fn := range funcs {
+		base[name] = fn
+	}
+	all, err := manifest.TemplateFuncs(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest for %s: %w", manifest.Name, err)
+	}
+	loader := templates.NewTemplateLoaderFS(fsys).Funcs(all)
+	loader.SetPartialDirs(manifest.PartialDirs(projectType))
+	return loader, nil
+}
+
+// PlanProject renders the project answers describe from the templates in
+// fsys, which holds one directory per project type like the embedded
+// templates, and returns what would be created without touching the disk.
+// The files are those the manifest of answers.ProjectType selects; the plan
+// carries the hooks of the template and the lock of the project. funcs are
+// added to the functions of every template.
+func PlanProject(fsys fs.FS, funcs template.FuncMap, projectName string, answers *questions.ProjectAnswers) (*Plan, error) {
+	answers.ProjectName = projectName
+	manifest, err := templates.LoadManifestFS(fsys, answers.ProjectType)
+	if err != nil {
+		return nil, err
+	}
+
+	data := TemplateData(projectName, manifest.Description, answers)
+	resolved, err := resolveManifest(manifest, answers.ProjectType, data)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{
+		ProjectName: projectName,
+		Directories: resolved.Directories,
+		Hooks:       resolved.Hooks,
+		Lock:        NewLock(answers),
+	}
+	plan.Lock.Template.Version = manifest.Version
+
+	loader, err := newLoader(fsys, answers.ProjectType, manifest, funcs)
+	if err != nil {
+		return nil, err
+	}
+	generator := templates.NewFileGenerator(loader)
+	for filePath, templateName := range resolved.Files {
+		content, err := generator.RenderFile(templateName, filePath, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render file %s from template %s: %v", filePath, templateName, err)
+		}
+		plan.AddFile(filePath, templateName, content)
+	}
+
+	return plan, nil
+}
+
+// TemplateData returns the values every template of a project is rendered
+// with: the project metadata from answers, the description of its template
+// and the answers to the manifest prompts
+func TemplateData(projectName, description string, answers *questions.ProjectAnswers) map[string]interface{} {
+	moduleName := answers.ModulePath
+	if moduleName == "" {
+		moduleName = projectName
+	}
+
+	goVersion := answers.GoVersion
+	if goVersion == "" {
+		goVersion = "1.21"
+	}
+
+	return AddPromptValues(map[string]interface{}{
+		"ProjectName":        projectName,
+		"ProjectDescription": description,
+		"ProjectType":        answers.ProjectType,
+		"ModuleName":         moduleName,
+		"GoVersion":          goVersion,
+		"Author":             answers.Author,
+		"License":            answers.License,
+	}, answers.Values)
+}
+
+// AddPromptValues copies the answers to the manifest prompts into data.
+// Answers never replace built-in values such as ProjectName.
+func AddPromptValues(data map[string]interface{}, values map[string]interface{}) map[string]interface{} {
+	for name, value := range values {
+		if _, ok := data[name]; !ok {
+			data[name] = value
+		}
+	}
+	return data
+}
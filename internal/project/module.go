@@ -0,0 +1,87 @@
+package project
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// ValidateModulePath checks a module path with the rules `go mod init` applies.
+func ValidateModulePath(modulePath string) error {
+	if err := module.CheckImportPath(modulePath); err != nil {
+		return fmt.Errorf("invalid module path: %w", err)
+	}
+	return nil
+}
+
+// IsFetchableModulePath reports whether the first element of a module path
+// looks like a domain name, which `go get` needs to download the module.
+func IsFetchableModulePath(modulePath string) bool {
+	return module.CheckPath(modulePath) == nil
+}
+
+// DefaultModulePath infers the module path for a new project created in dir.
+// Inside a git checkout with an origin remote the path is derived from the
+// remote URL and the location of dir within the repository; otherwise the
+// configured prefix is used. The bare project name is the last resort.
+func DefaultModulePath(dir, projectName, prefix string) string {
+	if remote, err := gitOutput(dir, "config", "--get", "remote.origin.url"); err == nil {
+		if repoPath, ok := ModulePathFromRemote(remote); ok {
+			subdir, _ := gitOutput(dir, "rev-parse", "--show-prefix")
+			return path.Join(repoPath, subdir, projectName)
+		}
+	}
+
+	if prefix = strings.Trim(prefix, "/ "); prefix != "" {
+		return prefix + "/" + projectName
+	}
+
+	return projectName
+}
+
+// ModulePathFromRemote converts a git remote URL such as
+// git@github.com:org/repo.git or https://github.com/org/repo.git into the
+// module path github.com/org/repo.
+func ModulePathFromRemote(remote string) (string, bool) {
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return "", false
+	}
+
+	var host, repoPath string
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		host, repoPath = u.Hostname(), u.Path
+	} else if at := strings.Index(remote, "@"); at >= 0 && strings.Contains(remote[at:], ":") {
+		// scp-like syntax: user@host:org/repo.git
+		hostAndPath := remote[at+1:]
+		colon := strings.Index(hostAndPath, ":")
+		host, repoPath = hostAndPath[:colon], hostAndPath[colon+1:]
+	} else {
+		return "", false
+	}
+
+	repoPath = strings.TrimSuffix(strings.Trim(repoPath, "/"), ".git")
+	if host == "" || repoPath == "" {
+		return "", false
+	}
+
+	modulePath := host + "/" + repoPath
+	if !IsFetchableModulePath(modulePath) {
+		return "", false
+	}
+	return modulePath, true
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
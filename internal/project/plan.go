@@ -0,0 +1,372 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-sova/sova-cli/internal/conflict"
+	"github.com/go-sova/sova-cli/internal/hooks"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+// PlannedFile is a file that has been rendered in memory but not yet written.
+type PlannedFile struct {
+	Path     string
+	Template string
+	Content  []byte
+}
+
+// Plan describes everything a generator is going to create, relative to the
+// project directory. Nothing in a plan touches the disk until it is written.
+type Plan struct {
+	ProjectName string
+	Directories []string
+	Files       []PlannedFile
+	// Hooks are the steps to run around writing the plan; see Generate
+	Hooks templates.Hooks
+	// Lock is written into the project by Generate, if set
+	Lock *Lock
+	// OnConflict decides what happens to existing files the plan would
+	// overwrite. Generate only writes into an existing directory when it is
+	// set; see Update and Upgrade for what they do without one.
+	OnConflict *conflict.Resolver
+}
+
+// AddFile records a rendered file and keeps the files sorted by path.
+func (p *Plan) AddFile(filePath, templateName string, content []byte) {
+	p.Files = append(p.Files, PlannedFile{
+		Path:     filepath.ToSlash(filePath),
+		Template: templateName,
+		Content:  content,
+	})
+	sort.Slice(p.Files, func(i, j int) bool { return p.Files[i].Path < p.Files[j].Path })
+}
+
+// File looks up a planned file by its path relative to the project directory.
+func (p *Plan) File(filePath string) (*PlannedFile, bool) {
+	filePath = path.Clean(filepath.ToSlash(filePath))
+	for i := range p.Files {
+		if p.Files[i].Path == filePath {
+			return &p.Files[i], true
+		}
+	}
+	return nil, false
+}
+
+// Write materializes the plan as projectDir. Everything is written into a
+// staging directory next to projectDir first and only renamed into place once
+// every file has been written, so a failure or cancellation never leaves a
+// half-generated project behind.
+func (p *Plan) Write(ctx context.Context, projectDir string) error {
+	return p.write(ctx, projectDir, nil)
+}
+
+// Generate writes the plan like Write and runs its hooks with runner. The
+// pre-generate steps run in the staging directory before any file is
+// written, so that a failing step leaves nothing behind; the post-generate
+// steps run in projectDir once it is in place. A nil runner skips the hooks.
+// The runner confirms the shell steps of both before anything is written.
+//
+// The lock of the plan records the files as the post-generate steps leave
+// them, but is written before a git init step so that the initial commit
+// includes it. Projects with a lock also get their base render (see
+// BaseDir).
+//
+// When projectDir already exists and the plan has an OnConflict resolver,
+// the files are written into it directly instead, and the resolver decides
+// about every file that is already there with other content. The hooks run
+// there only as far as runner.ForExistingDir allows.
+func (p *Plan) Generate(ctx context.Context, projectDir string, runner *hooks.Runner) error {
+	_, statErr := os.Stat(projectDir)
+	existing := statErr == nil && p.OnConflict != nil
+
+	steps := p.Hooks
+	if runner != nil {
+		if existing {
+			steps = runner.ForExistingDir(projectDir, steps)
+		}
+		if err := runner.Confirm(steps.PreGenerate, steps.PostGenerate); err != nil {
+			return err
+		}
+	}
+
+	prepare := func(dir string) error {
+		if runner != nil {
+			if err := runner.Run(ctx, dir, steps.PreGenerate); err != nil {
+				return err
+			}
+		}
+		if p.Lock == nil {
+			return nil
+		}
+		return p.writeBase(dir)
+	}
+
+	var err error
+	if existing {
+		err = p.writeInto(ctx, projectDir, prepare)
+	} else {
+		err = p.write(ctx, projectDir, prepare)
+	}
+	if err != nil {
+		return err
+	}
+	if runner == nil {
+		return p.writeLock(projectDir)
+	}
+
+	before, after := steps.PostGenerate, []templates.HookStep(nil)
+	for i, step := range steps.PostGenerate {
+		if hooks.IsGitInit(step) {
+			before, after = steps.PostGenerate[:i], steps.PostGenerate[i:]
+			break
+		}
+	}
+
+	err = runner.Run(ctx, projectDir, before)
+	if lockErr := p.writeLock(projectDir); lockErr != nil && err == nil {
+		err = lockErr
+	}
+	if err == nil {
+		err = runner.Run(ctx, projectDir, after)
+	}
+	if err != nil {
+		return &HookError{ProjectDir: projectDir, Err: err}
+	}
+	return nil
+}
+
+// HookError is returned by Generate when a post-generate step fails. The
+// project has been written by then.
+type HookError struct {
+	ProjectDir string
+	Err        error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("project created in %s, but %v", e.ProjectDir, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+func (p *Plan) write(ctx context.Context, projectDir string, before func(staging string) error) (err error) {
+	if _, err := os.Stat(projectDir); !os.IsNotExist(err) {
+		return fmt.Errorf("directory %s already exists", projectDir)
+	}
+
+	parent := filepath.Dir(projectDir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", parent, err)
+	}
+
+	staging, err := os.MkdirTemp(parent, "."+filepath.Base(projectDir)+".sova-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(staging)
+		}
+	}()
+
+	if before != nil {
+		if err := before(staging); err != nil {
+			return err
+		}
+	}
+
+	for _, dir := range p.Directories {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Join(staging, dir), 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	for _, file := range p.Files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		fullPath := filepath.Join(staging, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", file.Path, err)
+		}
+		if err := os.WriteFile(fullPath, file.Content, 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", file.Path, err)
+		}
+	}
+
+	// MkdirTemp creates the directory with 0700
+	if err := os.Chmod(staging, 0755); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", projectDir, err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.Rename(staging, projectDir); err != nil {
+		return fmt.Errorf("failed to move project into %s: %w", projectDir, err)
+	}
+
+	return nil
+}
+
+// writeInto writes the plan into the existing projectDir, leaving the files
+// that are not part of it alone
+func (p *Plan) writeInto(ctx context.Context, projectDir string, before func(dir string) error) error {
+	if err := before(projectDir); err != nil {
+		return err
+	}
+
+	for _, dir := range p.Directories {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Join(projectDir, dir), 0755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	for _, file := range p.Files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := p.OnConflict.WriteFile(filepath.Join(projectDir, filepath.FromSlash(file.Path)), file.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DescribeFile says what Generate did with the file at filePath in
+// projectDir, for printing
+func (p *Plan) DescribeFile(projectDir, filePath string) string {
+	fullPath := filepath.Join(projectDir, filepath.FromSlash(filePath))
+	result, ok := p.OnConflict.Lookup(fullPath)
+	switch {
+	case !ok:
+		return "Created file: " + fullPath
+	case result.Decision == conflict.Skip:
+		return "Kept existing file: " + fullPath
+	case result.Decision == conflict.Backup:
+		return fmt.Sprintf("Overwrote file: %s (previous version saved to %s)", fullPath, result.Backup)
+	default:
+		return "Overwrote file: " + fullPath
+	}
+}
+
+// Print writes the plan as a directory tree, annotating every file with the
+// template that renders it and the rendered size.
+func (p *Plan) Print(w io.Writer) {
+	root := &planNode{children: map[string]*planNode{}}
+	for _, dir := range p.Directories {
+		root.insert(filepath.ToSlash(dir), nil)
+	}
+	for i := range p.Files {
+		root.insert(p.Files[i].Path, &p.Files[i])
+	}
+
+	fmt.Fprintf(w, "%s/\n", p.ProjectName)
+	root.print(w, "")
+
+	var total int
+	for _, file := range p.Files {
+		total += len(file.Content)
+	}
+	fmt.Fprintf(w, "\n%d directories, %d files, %s\n", root.countDirs(), len(p.Files), formatSize(total))
+
+	for _, stage := range []struct {
+		name  string
+		steps []templates.HookStep
+	}{
+		{"pre-generate", p.Hooks.PreGenerate},
+		{"post-generate", p.Hooks.PostGenerate},
+	} {
+		if len(stage.steps) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s hooks:\n", stage.name)
+		for _, step := range stage.steps {
+			fmt.Fprintf(w, "  %s\n", step.Command)
+		}
+	}
+}
+
+type planNode struct {
+	name     string
+	file     *PlannedFile
+	children map[string]*planNode
+}
+
+func (n *planNode) insert(filePath string, file *PlannedFile) {
+	parts := strings.Split(path.Clean(filePath), "/")
+	node := n
+	for _, part := range parts {
+		child, ok := node.children[part]
+		if !ok {
+			child = &planNode{name: part, children: map[string]*planNode{}}
+			node.children[part] = child
+		}
+		node = child
+	}
+	node.file = file
+}
+
+func (n *planNode) sortedChildren() []*planNode {
+	children := make([]*planNode, 0, len(n.children))
+	for _, child := range n.children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool {
+		iDir, jDir := children[i].file == nil, children[j].file == nil
+		if iDir != jDir {
+			return iDir
+		}
+		return children[i].name < children[j].name
+	})
+	return children
+}
+
+func (n *planNode) print(w io.Writer, indent string) {
+	children := n.sortedChildren()
+	for i, child := range children {
+		branch, next := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		if child.file == nil {
+			fmt.Fprintf(w, "%s%s%s/\n", indent, branch, child.name)
+			child.print(w, indent+next)
+			continue
+		}
+
+		fmt.Fprintf(w, "%s%s%s  (%s, %s)\n", indent, branch, child.name, child.file.Template, formatSize(len(child.file.Content)))
+	}
+}
+
+func (n *planNode) countDirs() int {
+	count := 0
+	for _, child := range n.children {
+		if child.file == nil {
+			count += 1 + child.countDirs()
+		}
+	}
+	return count
+}
+
+func formatSize(size int) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.1f KB", float64(size)/1024)
+}
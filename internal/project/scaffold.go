@@ -0,0 +1,90 @@
+package project
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+// Scaffold renders the generator templates of a project type, the templates
+// in its templates.GeneratorsDir that sova generate adds to existing
+// projects. They are rendered with the data of the project, like the
+// templates the project was generated from.
+type Scaffold struct {
+	fsys fs.FS
+	// types are the project type and the ones it extends, nearest first
+	types     []string
+	generator *templates.FileGenerator
+	data      map[string]interface{}
+}
+
+// NewScaffold returns the scaffold of the project answers describe, with
+// the templates in fsys
+func NewScaffold(fsys fs.FS, answers *questions.ProjectAnswers) (*Scaffold, error) {
+	manifest, err := templates.LoadManifestFS(fsys, answers.ProjectType)
+	if err != nil {
+		return nil, err
+	}
+
+	data := TemplateData(answers.ProjectName, manifest.Description, answers)
+	if _, err := resolveManifest(manifest, answers.ProjectType, data); err != nil {
+		return nil, err
+	}
+	loader, err := newLoader(fsys, answers.ProjectType, manifest, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Scaffold{
+		fsys:      fsys,
+		types:     append([]string{answers.ProjectType}, manifest.Parents()...),
+		generator: templates.NewFileGenerator(loader),
+		data:      data,
+	}, nil
+}
+
+// ModulePath returns the module path of the project
+func (s *Scaffold) ModulePath() string {
+	modulePath, _ := s.data["ModuleName"].(string)
+	return modulePath
+}
+
+// Value returns the value of the template variable name for the project,
+// such as the answer to one of its prompts
+func (s *Scaffold) Value(name string) interface{} {
+	return s.data[name]
+}
+
+// Template returns the path of the generator template name. A project type
+// that has no such template takes the one of the type it extends.
+func (s *Scaffold) Template(name string) (string, bool) {
+	for _, projectType := range s.types {
+		templateName := path.Join(projectType, templates.GeneratorsDir, name)
+		if _, err := fs.Stat(s.fsys, templateName); err == nil {
+			return templateName, true
+		}
+	}
+	return "", false
+}
+
+// Render renders the generator template name for the file at targetPath,
+// with values added to the data of the project. Go files are formatted as
+// when a project is generated.
+func (s *Scaffold) Render(name, targetPath string, values map[string]interface{}) ([]byte, error) {
+	templateName, ok := s.Template(name)
+	if !ok {
+		return nil, fmt.Errorf("project type %s has no generator template %s", s.types[0], name)
+	}
+
+	data := make(map[string]interface{}, len(s.data)+len(values))
+	for key, value := range s.data {
+		data[key] = value
+	}
+	for key, value := range values {
+		data[key] = value
+	}
+	return s.generator.RenderFile(templateName, targetPath, data)
+}
@@ -2,22 +2,49 @@ package project
 
 import (
 	"fmt"
+	"io/fs"
+	"path/filepath"
 
+	"github.com/go-sova/sova-cli/internal/remote"
 	"github.com/go-sova/sova-cli/pkg/utils"
 	"github.com/go-sova/sova-cli/templates"
 )
 
+// Template sources reported by TemplateManager
+const (
+	SourceEmbedded = "embedded"
+	SourceLocal    = "local"
+	SourceRemote   = "remote"
+)
+
+// TemplateInfo describes an available template
+type TemplateInfo struct {
+	Name        string
+	Description string
+	Version     string
+	// Source is SourceEmbedded, SourceLocal or SourceRemote
+	Source string
+	// Location is the template directory for local templates and the
+	// source reference for remote ones
+	Location string
+}
+
 type TemplateManager struct {
 	logger         *utils.Logger
 	templateLoader *templates.TemplateLoader
+	store          *TemplateStore
 }
 
 func NewTemplateManager() *TemplateManager {
 	loader := templates.NewTemplateLoader()
-	return &TemplateManager{
+	manager := &TemplateManager{
 		logger:         utils.NewLoggerWithPrefix(utils.Info, "TemplateManager"),
 		templateLoader: loader,
 	}
+	if dir, err := DefaultTemplateStoreDir(); err == nil {
+		manager.store = NewTemplateStore(dir)
+	}
+	return manager
 }
 
 func (m *TemplateManager) SetLogger(logger *utils.Logger) {
@@ -25,31 +52,123 @@ func (m *TemplateManager) SetLogger(logger *utils.Logger) {
 	m.templateLoader.SetLogger(logger)
 }
 
+// SetStore sets the store used to tell installed templates apart
+func (m *TemplateManager) SetStore(store *TemplateStore) {
+	m.store = store
+}
+
 func (m *TemplateManager) ListTemplates() ([]string, error) {
 	m.logger.Debug("Listing templates")
-	return []string{"api", "cli"}, nil
+	return templates.ProjectTypes()
+}
+
+// Templates describes every available template
+func (m *TemplateManager) Templates() ([]TemplateInfo, error) {
+	names, err := m.ListTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]TemplateInfo, 0, len(names))
+	for _, name := range names {
+		info, err := m.Template(name)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, *info)
+	}
+	return infos, nil
+}
+
+// Template describes a single template. Templates with an invalid manifest
+// are still described, with the error as description.
+func (m *TemplateManager) Template(templateName string) (*TemplateInfo, error) {
+	if !m.exists(templateName) {
+		return nil, fmt.Errorf("unknown template: %s", templateName)
+	}
+
+	info := &TemplateInfo{Name: templateName, Source: SourceEmbedded}
+	if manifest, err := templates.LoadManifest(templateName); err == nil {
+		info.Description = manifest.Description
+		info.Version = manifest.Version
+	} else {
+		info.Description = fmt.Sprintf("invalid template: %v", err)
+	}
+
+	dir, ok := templates.TemplateDir(templateName)
+	if !ok {
+		return info, nil
+	}
+	info.Source = SourceLocal
+	info.Location = filepath.Join(dir, templateName)
+
+	if m.store != nil && filepath.Clean(dir) == filepath.Clean(m.store.Dir) {
+		installed, err := m.store.Installed()
+		if err != nil {
+			return nil, err
+		}
+		if entry, ok := installed[templateName]; ok {
+			if source, err := remote.Parse(entry.Source); err == nil && source.IsRemote() {
+				info.Source = SourceRemote
+				info.Location = entry.Source
+			}
+		}
+	}
+	return info, nil
 }
 
 func (m *TemplateManager) GetTemplateDescription(templateName string) (string, error) {
 	m.logger.Debug("Getting description for template: %s", templateName)
 
-	switch templateName {
-	case "api":
-		return "A Go API project with a complete structure for API development", nil
-	case "cli":
-		return "A command-line interface application with Cobra", nil
+	info, err := m.Template(templateName)
+	if err != nil {
+		return "", err
 	}
-
-	return "", fmt.Errorf("unknown template: %s", templateName)
+	return info.Description, nil
 }
 
+// ValidateTemplate checks that the manifest of a template is valid and that
+// every file it refers to exists and parses
 func (m *TemplateManager) ValidateTemplate(templateName string) error {
 	m.logger.Debug("Validating template: %s", templateName)
 
-	switch templateName {
-	case "api", "cli":
-		return nil
+	if !m.exists(templateName) {
+		return fmt.Errorf("unknown template: %s", templateName)
+	}
+	return ValidateTemplateFS(templates.GetTemplateFS(), templateName)
+}
+
+func (m *TemplateManager) exists(templateName string) bool {
+	names, err := m.ListTemplates()
+	if err != nil {
+		return false
+	}
+	for _, name := range names {
+		if name == templateName {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTemplateFS validates the project type templateName of fsys
+func ValidateTemplateFS(fsys fs.FS, templateName string) error {
+	manifest, err := templates.LoadManifestFS(fsys, templateName)
+	if err != nil {
+		return err
+	}
+	if err := manifest.Validate(fsys, templateName); err != nil {
+		return err
 	}
 
-	return fmt.Errorf("unknown template: %s", templateName)
+	loader, err := newLoader(fsys, templateName, manifest, nil)
+	if err != nil {
+		return err
+	}
+	for _, file := range manifest.Files {
+		if _, err := loader.LoadTemplate(file.Template(templateName)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,276 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/go-sova/sova-cli/internal/remote"
+	"github.com/go-sova/sova-cli/pkg/utils"
+	"github.com/go-sova/sova-cli/templates"
+	"gopkg.in/yaml.v3"
+)
+
+// storeIndexFile records where every installed template came from. Its name
+// starts with a dot so that it is never mistaken for a project type.
+const storeIndexFile = ".installed.yaml"
+
+// TemplateStore manages templates installed with `sova template install`.
+// Every template is a project type directory below Dir, which is used as a
+// template directory like any other.
+type TemplateStore struct {
+	Dir string
+}
+
+// InstalledTemplate describes a template in the store
+type InstalledTemplate struct {
+	Name        string    `yaml:"-"`
+	Source      string    `yaml:"source"`
+	Revision    string    `yaml:"revision,omitempty"`
+	InstalledAt time.Time `yaml:"installedAt"`
+}
+
+// DefaultTemplateStoreDir returns ~/.sova/templates
+func DefaultTemplateStoreDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".sova", "templates"), nil
+}
+
+func NewTemplateStore(dir string) *TemplateStore {
+	return &TemplateStore{Dir: dir}
+}
+
+// Installed returns the installed templates by name
+func (s *TemplateStore) Installed() (map[string]InstalledTemplate, error) {
+	installed := make(map[string]InstalledTemplate)
+
+	content, err := os.ReadFile(filepath.Join(s.Dir, storeIndexFile))
+	if os.IsNotExist(err) {
+		return installed, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template store: %w", err)
+	}
+	if err := yaml.Unmarshal(content, &installed); err != nil {
+		return nil, fmt.Errorf("failed to read template store: %w", err)
+	}
+
+	for name, entry := range installed {
+		entry.Name = name
+		installed[name] = entry
+	}
+	return installed, nil
+}
+
+// Install fetches source and copies the templates it provides into the
+// store. A source with a template.yaml at its root is installed as name, or
+// under the name from its manifest when name is empty; any other source
+// installs one template per project type directory. Existing templates are
+// only replaced when force is set.
+func (s *TemplateStore) Install(ctx context.Context, source *remote.Source, cacheDir, name string, force bool) ([]InstalledTemplate, error) {
+	fetched, err := source.Fetch(ctx, cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs, err := sourceTemplates(fetched.Dir, source, name)
+	if err != nil {
+		return nil, err
+	}
+
+	installed, err := s.Installed()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(dirs))
+	for templateName, dir := range dirs {
+		if err := validateTemplateDir(templateName, dir); err != nil {
+			return nil, fmt.Errorf("template %s from %s is invalid: %w", templateName, source, err)
+		}
+		if _, err := os.Stat(filepath.Join(s.Dir, templateName)); err == nil && !force {
+			return nil, fmt.Errorf("template %s is already installed (use --force to replace it)", templateName)
+		}
+		names = append(names, templateName)
+	}
+	sort.Strings(names)
+
+	var result []InstalledTemplate
+	for _, templateName := range names {
+		if err := s.copyTemplate(dirs[templateName], templateName); err != nil {
+			return nil, err
+		}
+
+		entry := InstalledTemplate{
+			Name:        templateName,
+			Source:      source.String(),
+			Revision:    fetched.Revision,
+			InstalledAt: time.Now().UTC().Truncate(time.Second),
+		}
+		if !source.IsRemote() && source.Kind != remote.KindGit {
+			if abs, err := filepath.Abs(source.Location); err == nil {
+				entry.Source = abs
+			}
+		}
+		installed[templateName] = entry
+		result = append(result, entry)
+	}
+
+	if err := s.writeIndex(installed); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Remove deletes an installed template
+func (s *TemplateStore) Remove(name string) error {
+	if !templates.IsProjectTypeName(name) {
+		return fmt.Errorf("invalid template name %q", name)
+	}
+
+	dir := filepath.Join(s.Dir, name)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return fmt.Errorf("template %s is not installed in %s", name, s.Dir)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove template %s: %w", name, err)
+	}
+
+	installed, err := s.Installed()
+	if err != nil {
+		return err
+	}
+	delete(installed, name)
+	return s.writeIndex(installed)
+}
+
+// sourceTemplates maps the name of every template a fetched source provides
+// to its directory
+func sourceTemplates(dir string, source *remote.Source, name string) (map[string]string, error) {
+	if content, err := os.ReadFile(filepath.Join(dir, templates.ManifestFile)); err == nil {
+		if name == "" {
+			if manifest, err := templates.ParseManifest(content); err == nil && templates.IsProjectTypeName(manifest.Name) {
+				name = manifest.Name
+			} else {
+				name = source.Name()
+			}
+		}
+		if !templates.IsProjectTypeName(name) {
+			return nil, fmt.Errorf("invalid template name %q (use --name)", name)
+		}
+		return map[string]string{name: dir}, nil
+	}
+
+	if name != "" {
+		return nil, fmt.Errorf("%s contains several templates; --name only applies to a single template", source)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	dirs := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() && templates.IsProjectTypeName(entry.Name()) {
+			dirs[entry.Name()] = filepath.Join(dir, entry.Name())
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("no templates found in %s", source)
+	}
+	return dirs, nil
+}
+
+// copyTemplate copies dir into the store as name, replacing any previous
+// version only once the copy is complete
+func (s *TemplateStore) copyTemplate(dir, name string) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create template store: %w", err)
+	}
+
+	staging, err := os.MkdirTemp(s.Dir, "."+name+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to install template %s: %w", name, err)
+	}
+	defer os.RemoveAll(staging)
+
+	copied := filepath.Join(staging, name)
+	if err := utils.CopyDir(dir, copied); err != nil {
+		return fmt.Errorf("failed to install template %s: %w", name, err)
+	}
+	os.RemoveAll(filepath.Join(copied, ".git"))
+
+	target := filepath.Join(s.Dir, name)
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("failed to replace template %s: %w", name, err)
+	}
+	if err := os.Rename(copied, target); err != nil {
+		return fmt.Errorf("failed to install template %s: %w", name, err)
+	}
+	return nil
+}
+
+func (s *TemplateStore) writeIndex(installed map[string]InstalledTemplate) error {
+	var buf bytes.Buffer
+	buf.WriteString("# Managed by `sova template install` and `sova template remove`\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(installed); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create template store: %w", err)
+	}
+	return os.WriteFile(filepath.Join(s.Dir, storeIndexFile), buf.Bytes(), 0644)
+}
+
+// LoadSource fetches source and returns a filesystem for every template it
+// provides, by name. Each filesystem holds its template in a directory named
+// after the template, like the embedded templates.
+func LoadSource(ctx context.Context, source *remote.Source, cacheDir string) (map[string]fs.FS, error) {
+	fetched, err := source.Fetch(ctx, cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs, err := sourceTemplates(fetched.Dir, source, "")
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]fs.FS, len(dirs))
+	for name, dir := range dirs {
+		sources[name] = templates.MountFS(name, os.DirFS(dir))
+	}
+	return sources, nil
+}
+
+// ValidateSource fetches source and validates every template it provides.
+// It returns the validation result of each template by name.
+func ValidateSource(ctx context.Context, source *remote.Source, cacheDir string) (map[string]error, error) {
+	sources, err := LoadSource(ctx, source, cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]error, len(sources))
+	for name, fsys := range sources {
+		results[name] = ValidateTemplateFS(fsys, name)
+	}
+	return results, nil
+}
+
+// validateTemplateDir checks the manifest of the template in dir and that
+// every file it refers to exists and parses
+func validateTemplateDir(name, dir string) error {
+	return ValidateTemplateFS(templates.MountFS(name, os.DirFS(dir)), name)
+}
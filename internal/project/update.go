@@ -0,0 +1,251 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-sova/sova-cli/internal/conflict"
+	"github.com/go-sova/sova-cli/internal/diff"
+	"golang.org/x/mod/modfile"
+)
+
+// NewFileSuffix is appended to the path of a file that was edited since it
+// was generated, to hold the content sova would have written instead
+const NewFileSuffix = ".sova-new"
+
+// FileAction says what Update or Upgrade did with a file
+type FileAction string
+
+const (
+	FileCreated FileAction = "created"
+	FileUpdated FileAction = "updated"
+	// FileMerged means both the template and the user changed the file and
+	// the changes were merged without conflicts
+	FileMerged FileAction = "merged"
+	// FileConflict means the file was edited since it was generated and
+	// the new content could not be merged into it. The new content was
+	// written to NewPath, or merged with conflict markers when NewPath is
+	// empty.
+	FileConflict FileAction = "conflict"
+	// FileSkipped means the file was edited since it was generated and the
+	// OnConflict resolver of the plan decided to keep it as it is
+	FileSkipped FileAction = "skipped"
+	FileRemoved FileAction = "removed"
+)
+
+// FileChange is a file of an existing project touched by Update or Upgrade
+type FileChange struct {
+	Path   string
+	Action FileAction
+	// NewPath is the path, relative to the project directory, the new
+	// content of a conflicting file was written to instead
+	NewPath string
+	// Backup is the path, relative to the project directory, the previous
+	// content of an overwritten file was saved to
+	Backup string
+}
+
+// Update writes the plan into the existing project at projectDir, which
+// was generated from base. Only files whose content differs between base
+// and p are touched: missing files are created, files that still match base
+// or the hash recorded in base.Lock are overwritten, and files the user has
+// edited are left alone with the new content written next to them (see
+// NewFileSuffix). Requirements added to go.mod are merged into the existing
+// go.mod, which go mod tidy has usually rewritten since. When the plan has an
+// OnConflict resolver, it decides about the edited files instead.
+func (p *Plan) Update(ctx context.Context, projectDir string, base *Plan) ([]FileChange, error) {
+	return p.apply(ctx, projectDir, base, false)
+}
+
+// Upgrade works like Update, but three-way merges the changes from base to
+// p into the files the user has edited. Conflicting changes are written
+// into the file between git-style conflict markers. Files that are no
+// longer generated are removed unless they were edited, and files that
+// were deleted but changed by the template get their new content written
+// next to where they were. When the plan has an OnConflict resolver, it
+// decides about the files that cannot be merged without conflicts instead.
+func (p *Plan) Upgrade(ctx context.Context, projectDir string, base *Plan) ([]FileChange, error) {
+	return p.apply(ctx, projectDir, base, true)
+}
+
+func (p *Plan) apply(ctx context.Context, projectDir string, base *Plan, merge bool) ([]FileChange, error) {
+	for _, dir := range p.Directories {
+		if err := os.MkdirAll(filepath.Join(projectDir, dir), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory %s: %w", dir, err)
+		}
+	}
+
+	theirsLabel := "template"
+	if p.Lock != nil {
+		theirsLabel = fmt.Sprintf("template %s %s", p.Lock.Template.Name, p.Lock.Template.Version)
+	}
+
+	var changes []FileChange
+	for _, file := range p.Files {
+		if err := ctx.Err(); err != nil {
+			return changes, err
+		}
+
+		baseFile, inBase := base.File(file.Path)
+		if inBase && bytes.Equal(baseFile.Content, file.Content) {
+			continue
+		}
+
+		fullPath := filepath.Join(projectDir, filepath.FromSlash(file.Path))
+		current, err := os.ReadFile(fullPath)
+		if err != nil && !os.IsNotExist(err) {
+			return changes, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+
+		change := FileChange{Path: file.Path, Action: FileUpdated}
+		content := file.Content
+		switch {
+		case os.IsNotExist(err) && inBase && merge:
+			change.Action, change.NewPath = FileConflict, file.Path+NewFileSuffix
+		case os.IsNotExist(err):
+			change.Action = FileCreated
+		case bytes.Equal(current, file.Content):
+			continue
+		case file.Path == "go.mod":
+			var baseContent []byte
+			if inBase {
+				baseContent = baseFile.Content
+			}
+			merged, err := mergeGoMod(fullPath, current, baseContent, file.Content)
+			if err != nil {
+				change.Action, change.NewPath = FileConflict, file.Path+NewFileSuffix
+			} else if bytes.Equal(merged, current) {
+				continue
+			} else {
+				content = merged
+			}
+		case inBase && bytes.Equal(current, baseFile.Content):
+		// A file that matches the lock but not its base render may have been
+		// changed by sova generate, so Upgrade merges rather than replaces it
+		case !(inBase && merge) && base.Lock != nil && base.Lock.Generated(file.Path, current):
+		case inBase && merge:
+			result := diff.Merge(diff.Lines(string(baseFile.Content)), diff.Lines(string(current)), diff.Lines(string(file.Content)), "current", theirsLabel)
+			content = []byte(result.Text())
+			change.Action = FileMerged
+			if result.Conflicts > 0 {
+				change.Action = FileConflict
+			}
+		default:
+			change.Action, change.NewPath = FileConflict, file.Path+NewFileSuffix
+		}
+
+		if change.Action == FileConflict && err == nil && p.OnConflict != nil {
+			change, err := p.resolve(projectDir, file)
+			if err != nil {
+				return changes, err
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		writePath := fullPath
+		if change.NewPath != "" {
+			writePath = filepath.Join(projectDir, filepath.FromSlash(change.NewPath))
+		}
+		if err := os.MkdirAll(filepath.Dir(writePath), 0755); err != nil {
+			return changes, fmt.Errorf("failed to create directory for %s: %w", file.Path, err)
+		}
+		if err := os.WriteFile(writePath, content, 0644); err != nil {
+			return changes, fmt.Errorf("failed to write file %s: %w", file.Path, err)
+		}
+		changes = append(changes, change)
+	}
+
+	for _, baseFile := range base.Files {
+		if _, ok := p.File(baseFile.Path); ok || !merge {
+			continue
+		}
+		fullPath := filepath.Join(projectDir, filepath.FromSlash(baseFile.Path))
+		current, err := os.ReadFile(fullPath)
+		if err != nil {
+			continue
+		}
+		if !bytes.Equal(current, baseFile.Content) && (base.Lock == nil || !base.Lock.Generated(baseFile.Path, current)) {
+			continue
+		}
+		if err := os.Remove(fullPath); err != nil {
+			return changes, fmt.Errorf("failed to remove %s: %w", baseFile.Path, err)
+		}
+		changes = append(changes, FileChange{Path: baseFile.Path, Action: FileRemoved})
+	}
+
+	return changes, nil
+}
+
+// resolve lets the OnConflict resolver of p decide what happens to the
+// edited file, instead of writing its new content next to it or merging it
+// with conflict markers
+func (p *Plan) resolve(projectDir string, file PlannedFile) (FileChange, error) {
+	result, err := p.OnConflict.WriteFile(filepath.Join(projectDir, filepath.FromSlash(file.Path)), file.Content)
+	if err != nil {
+		return FileChange{}, err
+	}
+
+	change := FileChange{Path: file.Path, Action: FileUpdated}
+	switch result.Decision {
+	case conflict.Skip:
+		change.Action = FileSkipped
+	case conflict.Backup:
+		backup, err := filepath.Rel(projectDir, result.Backup)
+		if err != nil {
+			return FileChange{}, err
+		}
+		change.Backup = filepath.ToSlash(backup)
+	}
+	return change, nil
+}
+
+// mergeGoMod applies the requirements next adds or changes compared to base
+// to current. A requirement the user has changed since is left alone.
+func mergeGoMod(goModPath string, current, base, next []byte) ([]byte, error) {
+	currentFile, err := modfile.Parse(goModPath, current, nil)
+	if err != nil {
+		return nil, err
+	}
+	nextFile, err := modfile.ParseLax(goModPath, next, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	baseVersions := make(map[string]string)
+	if base != nil {
+		baseFile, err := modfile.ParseLax(goModPath, base, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, req := range baseFile.Require {
+			baseVersions[req.Mod.Path] = req.Mod.Version
+		}
+	}
+	currentVersions := make(map[string]string)
+	for _, req := range currentFile.Require {
+		currentVersions[req.Mod.Path] = req.Mod.Version
+	}
+
+	for _, req := range nextFile.Require {
+		baseVersion, inBase := baseVersions[req.Mod.Path]
+		currentVersion, inCurrent := currentVersions[req.Mod.Path]
+		switch {
+		case inBase && baseVersion == req.Mod.Version:
+			continue
+		case inCurrent && inBase && currentVersion != baseVersion:
+			continue
+		case inCurrent && !inBase:
+			continue
+		}
+		if err := currentFile.AddRequire(req.Mod.Path, req.Mod.Version); err != nil {
+			return nil, err
+		}
+	}
+
+	currentFile.Cleanup()
+	return currentFile.Format()
+}
@@ -0,0 +1,276 @@
+package remote
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func fetchArchive(ctx context.Context, s *Source, cacheDir string) (*Fetched, error) {
+	remote := isURL(s.Location)
+
+	var content []byte
+	var entry string
+	if remote {
+		// Remote archives are cached by URL and downloaded once
+		entry = filepath.Join(cacheDir, "archive", cacheKey(s.Location))
+		if fetched, ok := readCacheEntry(entry); ok {
+			return fetched, nil
+		}
+		var err error
+		if content, err = download(ctx, s.Location); err != nil {
+			return nil, err
+		}
+	} else {
+		// Local archives are cached by content, so editing the file is
+		// picked up on the next run
+		var err error
+		if content, err = os.ReadFile(s.Location); err != nil {
+			return nil, fmt.Errorf("failed to read template archive: %w", err)
+		}
+	}
+
+	sum := sha256.Sum256(content)
+	revision := hex.EncodeToString(sum[:])
+	if entry == "" {
+		entry = filepath.Join(cacheDir, "archive", revision[:16])
+		if fetched, ok := readCacheEntry(entry); ok {
+			return fetched, nil
+		}
+	}
+
+	err := populate(entry, func(tmp string) error {
+		extracted := filepath.Join(tmp, "extract")
+		if err := extract(s.Location, content, extracted); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", s.Location, err)
+		}
+		if err := os.Rename(archiveRoot(extracted), filepath.Join(tmp, "src")); err != nil {
+			return err
+		}
+		os.RemoveAll(extracted)
+		return os.WriteFile(filepath.Join(tmp, "revision"), []byte(revision+"\n"), 0644)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fetched, ok := readCacheEntry(entry)
+	if !ok {
+		return nil, fmt.Errorf("failed to read cached template %s", s)
+	}
+	return fetched, nil
+}
+
+// MaxArchiveSize is the size in bytes of the largest template archive sova
+// downloads. Archives are extracted from memory, so this bounds the memory
+// a download takes.
+var MaxArchiveSize int64 = 64 << 20
+
+// httpClient downloads template archives. Its timeout covers the whole
+// download, so a stalled server cannot hang sova.
+var httpClient = &http.Client{Timeout: 2 * time.Minute}
+
+func download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	tooLarge := fmt.Errorf("failed to download %s: the archive is larger than %d bytes", url, MaxArchiveSize)
+	if resp.ContentLength > MaxArchiveSize {
+		return nil, tooLarge
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, MaxArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	if int64(len(content)) > MaxArchiveSize {
+		return nil, tooLarge
+	}
+	return content, nil
+}
+
+// MaxExtractedFileSize, MaxExtractedSize and MaxArchiveEntries bound what
+// extracting a template archive writes to disk: the size in bytes of a
+// single file, the size of all files together and the number of entries.
+// A small archive can otherwise unpack to more than the disk holds.
+var (
+	MaxExtractedFileSize int64 = 64 << 20
+	MaxExtractedSize     int64 = 256 << 20
+	MaxArchiveEntries          = 10000
+)
+
+func extract(name string, content []byte, dest string) error {
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+	e := &extractor{dest: dest}
+	if strings.HasSuffix(strings.ToLower(name), ".zip") {
+		return e.zip(content)
+	}
+	return e.tarGz(content)
+}
+
+// extractor writes the entries of an archive into dest and keeps count of
+// them and of the bytes written, for the limits above
+type extractor struct {
+	dest    string
+	entries int
+	size    int64
+}
+
+func (e *extractor) tarGz(content []byte) error {
+	gz, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := e.dir(header.Name); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := e.file(header.Name, tr); err != nil {
+				return err
+			}
+		}
+		// Links and special files are not needed by templates and are skipped
+	}
+}
+
+func (e *extractor) zip(content []byte) error {
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range zr.File {
+		if strings.HasPrefix(file.Name, "__MACOSX/") {
+			continue
+		}
+		if file.FileInfo().IsDir() {
+			if err := e.dir(file.Name); err != nil {
+				return err
+			}
+			continue
+		}
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		r, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = e.file(file.Name, r)
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// safePath joins an archive entry name to dest, rejecting names that would
+// end up outside of it
+func safePath(dest, name string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("invalid path %q in archive", name)
+	}
+	return filepath.Join(dest, filepath.FromSlash(cleaned)), nil
+}
+
+// entry counts an entry of the archive and returns where to extract it
+func (e *extractor) entry(name string) (string, error) {
+	e.entries++
+	if e.entries > MaxArchiveEntries {
+		return "", fmt.Errorf("the archive has more than %d entries", MaxArchiveEntries)
+	}
+	return safePath(e.dest, name)
+}
+
+func (e *extractor) dir(name string) error {
+	target, err := e.entry(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(target, 0755)
+}
+
+func (e *extractor) file(name string, r io.Reader) error {
+	target, err := e.entry(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	// The sizes recorded in the archive can lie, so the limits are enforced
+	// on what is actually read
+	limit, tooLarge := MaxExtractedFileSize, fmt.Errorf("%s is larger than %d bytes", name, MaxExtractedFileSize)
+	if remaining := MaxExtractedSize - e.size; remaining < limit {
+		limit, tooLarge = remaining, fmt.Errorf("the archive extracts to more than %d bytes", MaxExtractedSize)
+	}
+
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, limit+1))
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if n > limit {
+		f.Close()
+		return tooLarge
+	}
+	e.size += n
+	return f.Close()
+}
+
+// archiveRoot returns the single top-level directory of an extracted
+// archive, as in archives created from a directory or downloaded from a git
+// host, or dir itself
+func archiveRoot(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return dir
+	}
+	return filepath.Join(dir, entries[0].Name())
+}
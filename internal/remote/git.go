@@ -0,0 +1,137 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func fetchGit(ctx context.Context, s *Source, cacheDir string) (*Fetched, error) {
+	if err := checkRef(s.Ref); err != nil {
+		return nil, err
+	}
+	entry := filepath.Join(cacheDir, "git", cacheKey(s.Location, s.Ref))
+
+	// A tag or a commit never changes, so its cached checkout is reused. A
+	// branch is reused only while the repository still has it at the cached
+	// revision.
+	if fetched, ok := readCacheEntry(entry); ok {
+		if _, err := os.Stat(filepath.Join(entry, pinnedFile)); err == nil {
+			return fetched, nil
+		}
+		if revision, err := remoteRevision(ctx, s); err == nil && revision == fetched.Revision {
+			return fetched, nil
+		}
+	}
+
+	err := populate(entry, func(tmp string) error {
+		src := filepath.Join(tmp, "src")
+		if _, err := git(ctx, "", "clone", "--quiet", "--", s.Location, src); err != nil {
+			return fmt.Errorf("failed to clone %s: %w", s.Location, err)
+		}
+		if s.Ref != "" {
+			if _, err := git(ctx, src, "checkout", "--quiet", s.Ref, "--"); err != nil {
+				return fmt.Errorf("failed to check out %s of %s: %w", s.Ref, s.Location, err)
+			}
+		}
+
+		revision, err := git(ctx, src, "rev-parse", "HEAD")
+		if err != nil {
+			return fmt.Errorf("failed to resolve revision of %s: %w", s.Location, err)
+		}
+		if s.Ref != "" && isPinned(ctx, src, s.Ref, revision) {
+			if err := os.WriteFile(filepath.Join(tmp, pinnedFile), nil, 0644); err != nil {
+				return err
+			}
+		}
+		if err := os.RemoveAll(filepath.Join(src, ".git")); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(tmp, "revision"), []byte(revision+"\n"), 0644)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fetched, ok := readCacheEntry(entry)
+	if !ok {
+		return nil, fmt.Errorf("failed to read cached template %s", s)
+	}
+	return fetched, nil
+}
+
+// pinnedFile marks a cache entry of a tag or a commit
+const pinnedFile = "pinned"
+
+// isPinned reports whether ref, checked out at revision in the clone in
+// dir, is a tag or a commit rather than a branch
+func isPinned(ctx context.Context, dir, ref, revision string) bool {
+	if len(ref) >= 7 && strings.HasPrefix(revision, strings.ToLower(ref)) {
+		return true
+	}
+	_, err := git(ctx, dir, "rev-parse", "--verify", "--quiet", "refs/tags/"+ref)
+	return err == nil
+}
+
+// remoteRevision returns the commit the branch s.Ref, or the default branch,
+// is at in the repository of s
+func remoteRevision(ctx context.Context, s *Source) (string, error) {
+	name := "HEAD"
+	if s.Ref != "" {
+		name = "refs/heads/" + s.Ref
+	}
+	out, err := git(ctx, "", "ls-remote", "--", s.Location, name)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[1] == name {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("%s has no ref %s", s.Location, name)
+}
+
+// checkRef rejects refs that git would parse as an option
+func checkRef(ref string) error {
+	if strings.HasPrefix(ref, "-") {
+		return fmt.Errorf("invalid git ref %q: refs must not start with -", ref)
+	}
+	return nil
+}
+
+// readCacheEntry returns a cache entry holding the template files in src/
+// and the fetched revision in a revision file
+func readCacheEntry(entry string) (*Fetched, bool) {
+	revision, err := os.ReadFile(filepath.Join(entry, "revision"))
+	if err != nil {
+		return nil, false
+	}
+	src := filepath.Join(entry, "src")
+	if info, err := os.Stat(src); err != nil || !info.IsDir() {
+		return nil, false
+	}
+	return &Fetched{Dir: src, Revision: strings.TrimSpace(string(revision))}, true
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Fail instead of waiting for credentials that nobody is going to type
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%v: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
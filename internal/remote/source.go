@@ -0,0 +1,218 @@
+package remote
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Source kinds
+const (
+	KindDir     = "dir"
+	KindGit     = "git"
+	KindArchive = "archive"
+)
+
+// Source is a template location given on the command line:
+//
+//	git+https://github.com/acme/templates.git@v1.2.0
+//	git+ssh://git@github.com/acme/templates.git@3f2c1ab
+//	./path/to/template.tar.gz
+//	https://example.com/template.zip
+//	./path/to/template
+type Source struct {
+	Kind string
+	// Location is the repository URL, archive path or URL, or directory
+	Location string
+	// Ref is the git tag, branch or commit to check out; empty means the
+	// default branch
+	Ref string
+}
+
+// Fetched is a source that has been made available on disk
+type Fetched struct {
+	// Dir contains the template files
+	Dir string
+	// Revision identifies the fetched content: the commit for git sources
+	// and the SHA-256 of the file for archives
+	Revision string
+}
+
+// IsSource reports whether s refers to a template source rather than the
+// name of a project type.
+func IsSource(s string) bool {
+	return strings.HasPrefix(s, "git+") || isArchive(s) ||
+		strings.HasPrefix(s, ".") || strings.HasPrefix(s, "/") || strings.HasPrefix(s, "~") ||
+		strings.ContainsRune(s, os.PathSeparator)
+}
+
+// Parse parses a template source reference
+func Parse(s string) (*Source, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("empty template source")
+	}
+
+	if strings.HasPrefix(s, "git+") {
+		location, ref := splitRef(strings.TrimPrefix(s, "git+"))
+		if !strings.Contains(location, "://") {
+			return nil, fmt.Errorf("invalid git source %q: expected git+<scheme>://<repository>[@<ref>]", s)
+		}
+		if err := checkRef(ref); err != nil {
+			return nil, fmt.Errorf("invalid git source %q: %w", s, err)
+		}
+		return &Source{Kind: KindGit, Location: location, Ref: ref}, nil
+	}
+
+	if isArchive(s) {
+		return &Source{Kind: KindArchive, Location: s}, nil
+	}
+
+	if strings.Contains(s, "://") {
+		return nil, fmt.Errorf("unsupported template source %q: use git+<url> for repositories or a .tar.gz, .tgz or .zip archive", s)
+	}
+
+	return &Source{Kind: KindDir, Location: s}, nil
+}
+
+// splitRef splits "https://host/repo.git@v1.0.0" into the URL and the ref.
+// An "@" before the path, as in ssh://git@host/repo.git, is part of the URL.
+func splitRef(s string) (string, string) {
+	at := strings.LastIndex(s, "@")
+	if at < 0 {
+		return s, ""
+	}
+
+	pathStart := 0
+	if scheme := strings.Index(s, "://"); scheme >= 0 {
+		pathStart = scheme + 3
+	}
+	if slash := strings.Index(s[pathStart:], "/"); slash >= 0 {
+		pathStart += slash
+	} else {
+		return s, ""
+	}
+
+	if at < pathStart {
+		return s, ""
+	}
+	return s[:at], s[at+1:]
+}
+
+func isArchive(s string) bool {
+	lower := strings.ToLower(s)
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Name returns a name for the template derived from its location, such as
+// "templates" for git+https://github.com/acme/templates.git
+func (s *Source) Name() string {
+	name := path.Base(filepath.ToSlash(strings.TrimRight(s.Location, "/")))
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip", ".git"} {
+		if strings.HasSuffix(lower, ext) {
+			name = name[:len(name)-len(ext)]
+			break
+		}
+	}
+	return name
+}
+
+// IsRemote reports whether the source is fetched over the network rather
+// than read from the local filesystem
+func (s *Source) IsRemote() bool {
+	if s.Kind == KindGit {
+		return !strings.HasPrefix(s.Location, "file://")
+	}
+	return s.Kind == KindArchive && isURL(s.Location)
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+func (s *Source) String() string {
+	switch s.Kind {
+	case KindGit:
+		if s.Ref != "" {
+			return "git+" + s.Location + "@" + s.Ref
+		}
+		return "git+" + s.Location
+	default:
+		return s.Location
+	}
+}
+
+// Fetch makes the source available on disk. Git repositories and archives
+// are unpacked into cacheDir; a git source pinned to a ref and a remote
+// archive are only downloaded once, while the default branch of a
+// repository is fetched again every time. Directories are used in place.
+func (s *Source) Fetch(ctx context.Context, cacheDir string) (*Fetched, error) {
+	switch s.Kind {
+	case KindGit:
+		return fetchGit(ctx, s, cacheDir)
+	case KindArchive:
+		return fetchArchive(ctx, s, cacheDir)
+	default:
+		info, err := os.Stat(s.Location)
+		if err != nil {
+			return nil, fmt.Errorf("template source %s: %w", s.Location, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("template source %s is not a directory or a .tar.gz, .tgz or .zip archive", s.Location)
+		}
+		return &Fetched{Dir: s.Location}, nil
+	}
+}
+
+// DefaultCacheDir returns ~/.sova/cache
+func DefaultCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".sova", "cache"), nil
+}
+
+func cacheKey(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// populate fills the cache entry dir by calling fill on a temporary
+// directory and renaming it into place, so an interrupted fetch never leaves
+// a partial entry behind
+func populate(dir string, fill func(tmp string) error) error {
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), "."+filepath.Base(dir)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := fill(tmp); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return err
+	}
+
+	os.RemoveAll(dir)
+	if err := os.Rename(tmp, dir); err != nil {
+		return fmt.Errorf("failed to update cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package scaffold
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-sova/sova-cli/pkg/utils"
+)
+
+// HandlersDir is the package of an api project that holds its handlers
+const HandlersDir = "internal/handlers"
+
+// HandlersPackage returns the import path of the handlers of the module
+// modulePath
+func HandlersPackage(modulePath string) string {
+	return modulePath + "/" + HandlersDir
+}
+
+// Handler is a handler function and the route it is registered for
+type Handler struct {
+	// Name is the name of the function, e.g. GetUserHandler
+	Name string
+	// File and TestFile are the paths of its file and test, relative to
+	// the project directory
+	File     string
+	TestFile string
+	Route    Route
+}
+
+// NewHandler returns the handler name, e.g. "get-user" or "GetUser", for
+// method and path, which is relative to the router group
+func NewHandler(name, method, path string) (*Handler, error) {
+	base := strings.TrimSuffix(utils.PascalCase(name), "Handler")
+	if base == "" || !unicode.IsLetter([]rune(base)[0]) {
+		return nil, fmt.Errorf("invalid handler name %q", name)
+	}
+	fileName := utils.SnakeCase(base)
+	if strings.HasSuffix(fileName, "_test") {
+		return nil, fmt.Errorf("invalid handler name %q: its file would be a test file", name)
+	}
+
+	funcName := base + "Handler"
+	return &Handler{
+		Name:     funcName,
+		File:     filepath.ToSlash(filepath.Join(HandlersDir, fileName+".go")),
+		TestFile: filepath.ToSlash(filepath.Join(HandlersDir, fileName+"_test.go")),
+		Route:    Route{Method: method, Path: path, Handler: "handlers." + funcName},
+	}, nil
+}
+
+// Values returns the values the handler templates are rendered with.
+// prefix is the path of the router group.
+func (h *Handler) Values(prefix string) map[string]interface{} {
+	return map[string]interface{}{
+		"Handler":     h.Name,
+		"Method":      h.Route.Method,
+		"Path":        h.Route.Path,
+		"URL":         JoinPath(prefix, h.Route.Path),
+		"Params":      params(h.Route.Path),
+		"ExamplePath": ExamplePath(h.Route.Path),
+	}
+}
+
+// JoinPath joins the path of a router group and a path relative to it
+func JoinPath(prefix, relative string) string {
+	if prefix == "" || prefix == "/" {
+		return relative
+	}
+	if relative == "/" {
+		return prefix
+	}
+	return path.Join(prefix, relative)
+}
+
+// PathParams returns the names of the parameters of a route path, such as
+// id for /users/:id and filepath for /files/*filepath
+func PathParams(routePath string) []string {
+	var params []string
+	for _, segment := range strings.Split(routePath, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+		}
+	}
+	return params
+}
+
+// Param is a parameter of a route path and the variable a handler keeps it in
+type Param struct {
+	Name string
+	Var  string
+}
+
+func params(routePath string) []Param {
+	var result []Param
+	for _, name := range PathParams(routePath) {
+		result = append(result, Param{Name: name, Var: varName(name, "Param")})
+	}
+	return result
+}
+
+// varName returns name as a Go variable of a handler, with suffix added when
+// it would be a keyword or clash with c, the *gin.Context of the handler
+func varName(name, suffix string) string {
+	variable := utils.CamelCase(name)
+	if variable == "" || variable == "c" || token.IsKeyword(variable) || !token.IsIdentifier(variable) {
+		variable += suffix
+	}
+	return variable
+}
+
+// ExamplePath returns a request path that matches routePath, with 1 for
+// every parameter
+func ExamplePath(routePath string) string {
+	segments := strings.Split(routePath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "1"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// DeclaredFuncs maps the name of every function declared in the Go files of
+// dir to the name of its file. A missing directory declares nothing.
+func DeclaredFuncs(dir string) (map[string]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+
+	declared := make(map[string]string)
+	fset := token.NewFileSet()
+	for _, match := range matches {
+		content, err := os.ReadFile(match)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(fset, match, content, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", match, err)
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+				declared[fn.Name.Name] = filepath.Base(match)
+			}
+		}
+	}
+	return declared, nil
+}
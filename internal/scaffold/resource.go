@@ -0,0 +1,316 @@
+package scaffold
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-sova/sova-cli/pkg/utils"
+)
+
+// Directories of an api project that resources are added to
+const (
+	ModelsDir     = "internal/models"
+	RepositoryDir = "internal/repository"
+	MigrationsDir = "migrations"
+)
+
+// FieldType is a type a resource field can have, as a Go and a PostgreSQL
+// type
+type FieldType struct {
+	Go  string
+	SQL string
+}
+
+// FieldTypes are the types of resource fields, by the name used on the
+// command line
+var FieldTypes = map[string]FieldType{
+	"string": {Go: "string", SQL: "TEXT"},
+	"text":   {Go: "string", SQL: "TEXT"},
+	"int":    {Go: "int", SQL: "INTEGER"},
+	"int64":  {Go: "int64", SQL: "BIGINT"},
+	"float":  {Go: "float64", SQL: "DOUBLE PRECISION"},
+	"bool":   {Go: "bool", SQL: "BOOLEAN"},
+	"time":   {Go: "time.Time", SQL: "TIMESTAMPTZ"},
+}
+
+// reservedVars are the names the generated code of a resource uses for its
+// own variables and imports, which the variables holding the resource and
+// its list must not shadow
+var reservedVars = map[string]bool{
+	"c": true, "r": true, "ctx": true, "db": true, "id": true, "ok": true, "err": true,
+	"input": true, "rows": true, "result": true, "affected": true,
+	"errors": true, "gin": true, "http": true, "models": true, "repository": true,
+	"service": true, "sql": true, "strconv": true, "time": true,
+}
+
+// reservedColumns are the columns every resource table has
+var reservedColumns = []string{"id", "created_at", "updated_at"}
+
+// sqlKeywords are the PostgreSQL keywords that cannot name a column or a
+// table without quoting, which the generated queries don't do
+var sqlKeywords = map[string]bool{
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true, "array": true,
+	"as": true, "asc": true, "asymmetric": true, "authorization": true, "binary": true,
+	"both": true, "case": true, "cast": true, "check": true, "collate": true,
+	"collation": true, "column": true, "concurrently": true, "constraint": true,
+	"create": true, "cross": true, "current_catalog": true, "current_date": true,
+	"current_role": true, "current_schema": true, "current_time": true,
+	"current_timestamp": true, "current_user": true, "default": true,
+	"deferrable": true, "desc": true, "distinct": true, "do": true, "else": true,
+	"end": true, "except": true, "false": true, "fetch": true, "for": true,
+	"foreign": true, "freeze": true, "from": true, "full": true, "grant": true,
+	"group": true, "having": true, "ilike": true, "in": true, "initially": true,
+	"inner": true, "intersect": true, "into": true, "is": true, "isnull": true,
+	"join": true, "lateral": true, "leading": true, "left": true, "like": true,
+	"limit": true, "localtime": true, "localtimestamp": true, "natural": true,
+	"not": true, "notnull": true, "null": true, "offset": true, "on": true,
+	"only": true, "or": true, "order": true, "outer": true, "overlaps": true,
+	"placing": true, "primary": true, "references": true, "returning": true,
+	"right": true, "select": true, "session_user": true, "similar": true,
+	"some": true, "symmetric": true, "system_user": true, "table": true,
+	"tablesample": true, "then": true, "to": true, "trailing": true, "true": true,
+	"union": true, "unique": true, "user": true, "using": true, "variadic": true,
+	"verbose": true, "when": true, "where": true, "window": true, "with": true,
+}
+
+// Field is a field of a resource, a column of its table
+type Field struct {
+	// Name is the name of the struct field, e.g. FirstName
+	Name string
+	// Column is the column and JSON name, e.g. first_name
+	Column string
+	GoType string
+	SQL    string
+	Unique bool
+}
+
+// ParseField parses a field given as name:type[:unique], e.g.
+// email:string:unique
+func ParseField(spec string) (Field, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Field{}, fmt.Errorf("invalid field %q (expected name:type or name:type:unique)", spec)
+	}
+
+	column := utils.SnakeCase(parts[0])
+	if column == "" || !unicode.IsLetter([]rune(column)[0]) {
+		return Field{}, fmt.Errorf("invalid field %q: invalid name %q", spec, parts[0])
+	}
+	for _, reserved := range reservedColumns {
+		if column == reserved {
+			return Field{}, fmt.Errorf("invalid field %q: every resource has the column %s", spec, reserved)
+		}
+	}
+	if sqlKeywords[column] {
+		return Field{}, fmt.Errorf("invalid field %q: %s is a reserved word in SQL", spec, column)
+	}
+
+	fieldType, ok := FieldTypes[strings.ToLower(parts[1])]
+	if !ok {
+		return Field{}, fmt.Errorf("invalid field %q: unknown type %q (expected %s)", spec, parts[1], strings.Join(fieldTypeNames(), ", "))
+	}
+
+	field := Field{Name: utils.PascalCase(column), Column: column, GoType: fieldType.Go, SQL: fieldType.SQL}
+	if len(parts) == 3 {
+		if parts[2] != "unique" {
+			return Field{}, fmt.Errorf("invalid field %q: unknown modifier %q (expected unique)", spec, parts[2])
+		}
+		field.Unique = true
+	}
+	return field, nil
+}
+
+func fieldTypeNames() []string {
+	names := make([]string, 0, len(FieldTypes))
+	for name := range FieldTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resource is a model stored in PostgreSQL, with a repository, CRUD
+// handlers and routes, and the migration that creates its table
+type Resource struct {
+	// Model is the name of the struct, e.g. User, and Var the variable that
+	// holds one, e.g. user
+	Model string
+	Var   string
+	// Plural is the plural of Model, e.g. Users
+	Plural string
+	Table  string
+	// Path is the path of the collection relative to the router group,
+	// e.g. /users
+	Path   string
+	Fields []Field
+
+	// The handlers of the resource, e.g. ListUsersHandler and GetUserHandler
+	ListHandler   string
+	GetHandler    string
+	CreateHandler string
+	UpdateHandler string
+	DeleteHandler string
+}
+
+// NewResource returns the resource name, e.g. "user" or "BlogPost", with
+// fields given as name:type[:unique]
+func NewResource(name string, fields []string) (*Resource, error) {
+	model := utils.Singular(utils.PascalCase(name))
+	if model == "" || !unicode.IsLetter([]rune(model)[0]) {
+		return nil, fmt.Errorf("invalid resource name %q", name)
+	}
+	if strings.HasSuffix(utils.SnakeCase(model), "_test") {
+		return nil, fmt.Errorf("invalid resource name %q: its files would be test files", name)
+	}
+	plural := utils.Plural(model)
+	if plural == model {
+		return nil, fmt.Errorf("invalid resource name %q: its plural is the same word", name)
+	}
+	if table := utils.SnakeCase(plural); sqlKeywords[table] {
+		return nil, fmt.Errorf("invalid resource name %q: its table %s is a reserved word in SQL", name, table)
+	}
+
+	r := &Resource{
+		Model:         model,
+		Var:           varName(model, "Item"),
+		Plural:        plural,
+		Table:         utils.SnakeCase(plural),
+		Path:          "/" + utils.KebabCase(plural),
+		ListHandler:   "List" + plural + "Handler",
+		GetHandler:    "Get" + model + "Handler",
+		CreateHandler: "Create" + model + "Handler",
+		UpdateHandler: "Update" + model + "Handler",
+		DeleteHandler: "Delete" + model + "Handler",
+	}
+	if reservedVars[r.Var] || reservedVars[utils.Plural(r.Var)] {
+		r.Var += "Item"
+	}
+
+	seen := make(map[string]bool)
+	for _, spec := range fields {
+		field, err := ParseField(spec)
+		if err != nil {
+			return nil, err
+		}
+		if seen[field.Column] {
+			return nil, fmt.Errorf("duplicate field %s", field.Column)
+		}
+		seen[field.Column] = true
+		r.Fields = append(r.Fields, field)
+	}
+	if len(r.Fields) == 0 {
+		return nil, fmt.Errorf("resource %s has no fields; give them as name:type, e.g. name:string", model)
+	}
+	return r, nil
+}
+
+// Routes returns the routes of the handlers of the resource
+func (r *Resource) Routes() []Route {
+	item := r.Path + "/:id"
+	return []Route{
+		{Method: "GET", Path: r.Path, Handler: "handlers." + r.ListHandler},
+		{Method: "GET", Path: item, Handler: "handlers." + r.GetHandler},
+		{Method: "POST", Path: r.Path, Handler: "handlers." + r.CreateHandler},
+		{Method: "PUT", Path: item, Handler: "handlers." + r.UpdateHandler},
+		{Method: "DELETE", Path: item, Handler: "handlers." + r.DeleteHandler},
+	}
+}
+
+// Funcs returns the names of the functions the handlers of the resource
+// declare: the handlers and their helpers
+func (r *Resource) Funcs() []string {
+	return []string{r.ListHandler, r.GetHandler, r.CreateHandler, r.UpdateHandler, r.DeleteHandler, r.Var + "ID", r.Var + "Error"}
+}
+
+// Columns returns the columns of the fields, e.g. "name, email"
+func (r *Resource) Columns() string {
+	columns := make([]string, len(r.Fields))
+	for i, field := range r.Fields {
+		columns[i] = field.Column
+	}
+	return strings.Join(columns, ", ")
+}
+
+// Placeholders returns a query placeholder for every field, e.g. "$1, $2"
+func (r *Resource) Placeholders() string {
+	placeholders := make([]string, len(r.Fields))
+	for i := range r.Fields {
+		placeholders[i] = placeholder(i)
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// Assignments returns the SET clause of an update of the fields, e.g.
+// "name = $1, email = $2"
+func (r *Resource) Assignments() string {
+	assignments := make([]string, len(r.Fields))
+	for i, field := range r.Fields {
+		assignments[i] = field.Column + " = " + placeholder(i)
+	}
+	return strings.Join(assignments, ", ")
+}
+
+// IDPlaceholder returns the placeholder of the id in an update, which comes
+// after the fields
+func (r *Resource) IDPlaceholder() string {
+	return placeholder(len(r.Fields))
+}
+
+func placeholder(i int) string {
+	return "$" + strconv.Itoa(i+1)
+}
+
+// ResourceFile is a file of a resource and the generator template it is
+// rendered from
+type ResourceFile struct {
+	Template string
+	// Path is relative to the project directory
+	Path string
+}
+
+// Files returns the files of the resource. version is the version of its
+// migration, e.g. 20240102150405.
+func (r *Resource) Files(version string) []ResourceFile {
+	file := utils.SnakeCase(r.Model)
+	migration := path.Join(MigrationsDir, version+"_create_"+r.Table)
+	return []ResourceFile{
+		{Template: "model.go.tpl", Path: path.Join(ModelsDir, file+".go")},
+		{Template: "repository.go.tpl", Path: path.Join(RepositoryDir, file+".go")},
+		{Template: "resource_handlers.go.tpl", Path: path.Join(HandlersDir, file+".go")},
+		{Template: "resource_handlers_test.go.tpl", Path: path.Join(HandlersDir, file+"_test.go")},
+		{Template: "migration.up.sql.tpl", Path: migration + ".up.sql"},
+		{Template: "migration.down.sql.tpl", Path: migration + ".down.sql"},
+	}
+}
+
+// MigrationVersion returns the version of a migration created at t
+func MigrationVersion(t time.Time) string {
+	return t.UTC().Format("20060102150405")
+}
+
+// FindMigration returns the version of the migration in dir that creates
+// table, so that generating a resource again rewrites its migration instead
+// of adding another one
+func FindMigration(dir, table string) (string, bool) {
+	matches, err := filepath.Glob(filepath.Join(dir, MigrationsDir, "*_create_"+table+".up.sql"))
+	if err != nil || len(matches) == 0 {
+		return "", false
+	}
+	sort.Strings(matches)
+	return strings.TrimSuffix(filepath.Base(matches[0]), "_create_"+table+".up.sql"), true
+}
+
+// Values returns the values the resource templates are rendered with.
+// prefix is the path of the router group.
+func (r *Resource) Values(prefix string) map[string]interface{} {
+	return map[string]interface{}{
+		"Resource": r,
+		"URL":      JoinPath(prefix, r.Path),
+	}
+}
@@ -0,0 +1,312 @@
+// Package scaffold adds code to projects generated from the api template:
+// handlers, their routes and the resources built from them.
+package scaffold
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// RoutesFile is the file of an api project that registers its routes
+const RoutesFile = "internal/routes/routes.go"
+
+// SetupFunc is the function of RoutesFile that registers the routes
+const SetupFunc = "SetupRoutes"
+
+// Methods are the HTTP methods a route can be registered for
+var Methods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// Route is a route of a router group
+type Route struct {
+	Method string
+	// Path is relative to the group
+	Path string
+	// Handler is the expression of the handler, e.g. handlers.GetUserHandler.
+	// Routes.Add qualifies it with the name routes.go imports the handlers
+	// package under instead, when that is another one.
+	Handler string
+}
+
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+// ParseMethod returns method in upper case, or an error when it is not one
+// of Methods
+func ParseMethod(method string) (string, error) {
+	upper := strings.ToUpper(method)
+	for _, m := range Methods {
+		if m == upper {
+			return upper, nil
+		}
+	}
+	return "", fmt.Errorf("invalid method %q (expected %s)", method, strings.Join(Methods, ", "))
+}
+
+// Routes is a parsed routes.go. Routes are added to the router group that
+// SetupRoutes creates, such as api := router.Group("/api"). The group and
+// the place to add routes are found in the syntax tree, so that the file
+// may have been edited since it was generated.
+type Routes struct {
+	src  []byte
+	fset *token.FileSet
+	file *ast.File
+	body *ast.BlockStmt
+	// group is the variable of the router group and prefix its path
+	group  string
+	prefix string
+	// insert is the offset in src new routes are inserted at, and newline
+	// reports whether they have to start on a new line
+	insert  int
+	newline bool
+}
+
+// ParseRoutes parses src, the content of RoutesFile
+func ParseRoutes(src []byte) (*Routes, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, RoutesFile, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Routes{src: src, fset: fset, file: file}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == SetupFunc && fn.Body != nil {
+			r.body = fn.Body
+		}
+	}
+	if r.body == nil {
+		return nil, fmt.Errorf("%s has no function %s", RoutesFile, SetupFunc)
+	}
+	if err := r.findGroup(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// findGroup finds the router group of SetupRoutes and where its routes end:
+// at the end of the block that follows the group, as in the generated file,
+// or after the last statement that uses the group
+func (r *Routes) findGroup() error {
+	index := -1
+	for i, stmt := range r.body.List {
+		name, prefix, ok := groupAssignment(stmt)
+		if !ok {
+			continue
+		}
+		if index < 0 || name == "api" {
+			index, r.group, r.prefix = i, name, prefix
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("%s in %s creates no router group", SetupFunc, RoutesFile)
+	}
+
+	if index+1 < len(r.body.List) {
+		if block, ok := r.body.List[index+1].(*ast.BlockStmt); ok {
+			last := r.offset(block.Lbrace) + 1
+			if len(block.List) > 0 {
+				last = r.offset(block.List[len(block.List)-1].End())
+			}
+			rbrace := r.offset(block.Rbrace)
+			if bytes.IndexByte(r.src[last:rbrace], '\n') >= 0 {
+				r.insert = r.lineStart(rbrace)
+			} else {
+				r.insert, r.newline = rbrace, true
+			}
+			return nil
+		}
+	}
+
+	last := r.body.List[index]
+	for _, stmt := range r.body.List[index+1:] {
+		if usesIdent(stmt, r.group) {
+			last = stmt
+		}
+	}
+	r.insert = r.nextLine(r.offset(last.End()))
+	return nil
+}
+
+// groupAssignment reports whether stmt is name := x.Group("prefix")
+func groupAssignment(stmt ast.Stmt) (name, prefix string, ok bool) {
+	assign, isAssign := stmt.(*ast.AssignStmt)
+	if !isAssign || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return "", "", false
+	}
+	ident, isIdent := assign.Lhs[0].(*ast.Ident)
+	call, isCall := assign.Rhs[0].(*ast.CallExpr)
+	if !isIdent || !isCall || len(call.Args) == 0 {
+		return "", "", false
+	}
+	if sel, isSel := call.Fun.(*ast.SelectorExpr); !isSel || sel.Sel.Name != "Group" {
+		return "", "", false
+	}
+	lit, isLit := call.Args[0].(*ast.BasicLit)
+	if !isLit || lit.Kind != token.STRING {
+		return "", "", false
+	}
+	prefix, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", "", false
+	}
+	return ident.Name, prefix, true
+}
+
+func usesIdent(node ast.Node, name string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func (r *Routes) offset(pos token.Pos) int {
+	return r.fset.Position(pos).Offset
+}
+
+// lineStart returns the offset of the start of the line offset is on
+func (r *Routes) lineStart(offset int) int {
+	return bytes.LastIndexByte(r.src[:offset], '\n') + 1
+}
+
+// nextLine returns the offset of the start of the line after offset
+func (r *Routes) nextLine(offset int) int {
+	i := bytes.IndexByte(r.src[offset:], '\n')
+	if i < 0 {
+		return len(r.src)
+	}
+	return offset + i + 1
+}
+
+// Prefix returns the path of the router group
+func (r *Routes) Prefix() string {
+	return r.prefix
+}
+
+// RelativePath returns path relative to the router group. A path that
+// starts with the prefix of the group is taken to include it.
+func (r *Routes) RelativePath(path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if r.prefix != "" && r.prefix != "/" {
+		if path == r.prefix {
+			return "/"
+		}
+		if strings.HasPrefix(path, r.prefix+"/") {
+			return strings.TrimPrefix(path, r.prefix)
+		}
+	}
+	return path
+}
+
+// Has reports whether the group already has a route for method and path
+func (r *Routes) Has(method, path string) bool {
+	found := false
+	ast.Inspect(r.body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || found || len(call.Args) == 0 {
+			return !found
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != method {
+			return true
+		}
+		if ident, ok := sel.X.(*ast.Ident); !ok || ident.Name != r.group {
+			return true
+		}
+		if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if value, err := strconv.Unquote(lit.Value); err == nil && value == path {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// Add returns the file with routes registered at the end of the router
+// group and importPath imported, formatted like gofmt. It fails when the
+// group already has one of the routes.
+func (r *Routes) Add(importPath string, routes ...Route) ([]byte, error) {
+	offset, text, qualifier := r.importSpec(importPath)
+
+	var stmts strings.Builder
+	if r.newline {
+		stmts.WriteString("\n")
+	}
+	for _, route := range routes {
+		if r.Has(route.Method, route.Path) {
+			return nil, fmt.Errorf("%s already registers %s %s", RoutesFile, route.Method, r.prefix+route.Path)
+		}
+		handler := route.Handler
+		if rest, ok := strings.CutPrefix(handler, path.Base(importPath)+"."); ok {
+			handler = qualifier + rest
+		}
+		fmt.Fprintf(&stmts, "%s.%s(%s, %s)\n", r.group, route.Method, strconv.Quote(route.Path), handler)
+	}
+
+	// The import comes before the routes
+	var buf bytes.Buffer
+	buf.Write(r.src[:offset])
+	buf.WriteString(text)
+	buf.Write(r.src[offset:r.insert])
+	buf.WriteString(stmts.String())
+	buf.Write(r.src[r.insert:])
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to add routes to %s: %w", RoutesFile, err)
+	}
+	return formatted, nil
+}
+
+// importSpec returns where to add an import of importPath to the file and
+// what to add, or nothing when the file imports it already, and the
+// qualifier of the identifiers of the package, such as "handlers."
+func (r *Routes) importSpec(importPath string) (int, string, string) {
+	quoted := strconv.Quote(importPath)
+	qualifier := path.Base(importPath) + "."
+	for _, spec := range r.file.Imports {
+		if value, err := strconv.Unquote(spec.Path.Value); err != nil || value != importPath {
+			continue
+		}
+		switch {
+		case spec.Name == nil:
+			return 0, "", qualifier
+		case spec.Name.Name == ".":
+			return 0, "", ""
+		case spec.Name.Name != "_":
+			return 0, "", spec.Name.Name + "."
+		}
+	}
+
+	var last *ast.GenDecl
+	for _, decl := range r.file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			last = gen
+		}
+	}
+	switch {
+	case last == nil:
+		return r.nextLine(r.offset(r.file.Name.End())), "\nimport " + quoted + "\n", qualifier
+	case !last.Rparen.IsValid():
+		return r.nextLine(r.offset(last.End())), "import " + quoted + "\n", qualifier
+	}
+	rparen := r.offset(last.Rparen)
+	if start := r.lineStart(rparen); strings.TrimSpace(string(r.src[start:rparen])) == "" {
+		return start, quoted + "\n", qualifier
+	}
+	return rparen, "\n" + quoted + "\n", qualifier
+}
@@ -0,0 +1,261 @@
+// Package generator generates projects from sova templates, the way sova
+// init, add and upgrade do: the templates are read from an fs.FS, rendered
+// in memory with the project answers and handed to a Sink.
+//
+//	result, err := generator.Generate(ctx, generator.Spec{
+//		ProjectName: "shop",
+//		Answers:     answers,
+//		Sink:        &generator.DirSink{Dir: "shop"},
+//	})
+package generator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"text/template"
+
+	"github.com/go-sova/sova-cli/internal/conflict"
+	"github.com/go-sova/sova-cli/internal/hooks"
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+// Spec describes a project to generate
+type Spec struct {
+	// ProjectName names the project; it defaults to Answers.ProjectName
+	ProjectName string
+	// Answers holds the project type, the project metadata and the answers
+	// to the prompts of its template, as questions.AskProjectQuestions
+	// returns them
+	Answers *questions.ProjectAnswers
+	// Source holds the templates, one directory per project type. It
+	// defaults to the embedded templates overlaid with the configured
+	// template directories.
+	Source fs.FS
+	// Funcs are added to the functions of every template, after those of
+	// templates.FuncMap and before those the template declares in its
+	// manifest
+	Funcs template.FuncMap
+	// TemplateSource is the template source the template was fetched from,
+	// as given to sova init --template, and TemplateRevision the revision
+	// that was fetched. The lock of the project records them, so that the
+	// template can be fetched again to add components or upgrade.
+	TemplateSource   string
+	TemplateRevision string
+	// Sink receives the rendered project. Without one the project is only
+	// rendered, as for a dry run.
+	Sink Sink
+}
+
+// File is a rendered file of a project
+type File struct {
+	// Path is relative to the project directory and uses forward slashes
+	Path string
+	// Template is the template the file was rendered from
+	Template string
+	Content  []byte
+}
+
+// Project is a rendered project, which a Sink writes somewhere
+type Project struct {
+	Name string
+	// Template and TemplateVersion identify the template the project was
+	// rendered from
+	Template        string
+	TemplateVersion string
+	// Directories are the directories to create, including empty ones,
+	// relative to the project directory
+	Directories []string
+	// Files are sorted by path
+	Files []File
+	// Hooks are the pre- and post-generate steps of the template
+	Hooks templates.Hooks
+
+	// lock is the lock of the project, written by DirSink
+	lock *project.Lock
+}
+
+// File looks up a file of the project by its path
+func (p *Project) File(filePath string) (*File, bool) {
+	for i := range p.Files {
+		if p.Files[i].Path == filePath {
+			return &p.Files[i], true
+		}
+	}
+	return nil, false
+}
+
+func newProject(plan *project.Plan) *Project {
+	p := &Project{
+		Name:        plan.ProjectName,
+		Directories: plan.Directories,
+		Files:       make([]File, len(plan.Files)),
+		Hooks:       plan.Hooks,
+		lock:        plan.Lock,
+	}
+	if plan.Lock != nil {
+		p.Template = plan.Lock.Template.Name
+		p.TemplateVersion = plan.Lock.Template.Version
+	}
+	for i, file := range plan.Files {
+		p.Files[i] = File{Path: file.Path, Template: file.Template, Content: file.Content}
+	}
+	return p
+}
+
+// plan returns the project as a plan for the internal writer
+func (p *Project) plan() *project.Plan {
+	plan := &project.Plan{ProjectName: p.Name, Directories: p.Directories, Hooks: p.Hooks, Lock: p.lock}
+	for _, file := range p.Files {
+		plan.AddFile(file.Path, file.Template, file.Content)
+	}
+	return plan
+}
+
+// Result is a generated project
+type Result struct {
+	// Project holds every rendered file and the hooks of the template
+	Project *Project
+}
+
+// Sink writes a rendered project somewhere
+type Sink interface {
+	Write(ctx context.Context, p *Project) error
+}
+
+// ConflictPolicy says what DirSink does with an existing file that differs
+// from the rendered one
+type ConflictPolicy string
+
+const (
+	// ConflictSkip keeps the existing file
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces it
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictBackup saves it with a .bak suffix first and replaces it
+	ConflictBackup ConflictPolicy = "backup"
+	// ConflictPrompt calls DirSink.Ask for every file
+	ConflictPrompt ConflictPolicy = "prompt"
+)
+
+// HookOptions configure how DirSink runs the hooks of the template
+type HookOptions struct {
+	// NoGit skips the git init step
+	NoGit bool
+	// InExistingDir runs the steps other than git init when the project is
+	// written into a directory that already existed; by default none run
+	// there
+	InExistingDir bool
+	// ConfirmShell is asked once, with every shell command of the hooks,
+	// whether they may run; they are skipped when it returns false. A nil
+	// ConfirmShell runs them.
+	ConfirmShell func(commands []string) (bool, error)
+}
+
+// DirSink writes the project into the directory Dir, together with its
+// lock, and runs the hooks of the template. Everything is written into a
+// staging directory first, so a failure leaves nothing behind.
+type DirSink struct {
+	Dir string
+	// Hooks runs the pre- and post-generate steps; nil skips them
+	Hooks *HookOptions
+	// OnConflict lets the project be written into an existing directory and
+	// says what to do with the files that are already there; without it an
+	// existing Dir is an error
+	OnConflict ConflictPolicy
+	// Ask decides about an existing file with ConflictPrompt; it must not
+	// return ConflictPrompt
+	Ask func(path string, current, next []byte) (ConflictPolicy, error)
+}
+
+// HookError is returned by DirSink when a post-generate step fails. The
+// project has been written by then.
+type HookError struct {
+	ProjectDir string
+	Err        error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("project created in %s, but %v", e.ProjectDir, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+func (s *DirSink) Write(ctx context.Context, p *Project) error {
+	plan := p.plan()
+	if s.OnConflict != "" {
+		policy, err := conflict.ParsePolicy(string(s.OnConflict))
+		if err != nil {
+			return err
+		}
+		var ask conflict.AskFunc
+		if s.Ask != nil {
+			ask = func(path string, current, next []byte) (conflict.Policy, error) {
+				decision, err := s.Ask(path, current, next)
+				return conflict.Policy(decision), err
+			}
+		}
+		plan.OnConflict = conflict.NewResolver(policy, ask)
+	}
+
+	var runner *hooks.Runner
+	if s.Hooks != nil {
+		runner = hooks.NewRunner()
+		runner.NoGit = s.Hooks.NoGit
+		runner.InExistingDir = s.Hooks.InExistingDir
+		runner.ConfirmShell = s.Hooks.ConfirmShell
+	}
+
+	err := plan.Generate(ctx, s.Dir, runner)
+	var hookErr *project.HookError
+	if errors.As(err, &hookErr) {
+		return &HookError{ProjectDir: hookErr.ProjectDir, Err: hookErr.Err}
+	}
+	return err
+}
+
+// Generate renders the project spec describes and writes it to its sink.
+// Every file is rendered before the sink is called, so a broken template
+// fails before anything is written; the returned Result has no Project
+// then.
+func Generate(ctx context.Context, spec Spec) (Result, error) {
+	if spec.Answers == nil {
+		return Result{}, fmt.Errorf("no answers to generate a project from")
+	}
+	projectName := spec.ProjectName
+	if projectName == "" {
+		projectName = spec.Answers.ProjectName
+	}
+	if projectName == "" {
+		return Result{}, fmt.Errorf("project name is required")
+	}
+	source := spec.Source
+	if source == nil {
+		source = templates.GetTemplateFS()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	plan, err := project.PlanProject(source, spec.Funcs, projectName, spec.Answers)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if spec.TemplateSource != "" {
+		if err := plan.Lock.SetSource(spec.TemplateSource, spec.TemplateRevision); err != nil {
+			return Result{}, err
+		}
+	}
+
+	result := Result{Project: newProject(plan)}
+	if spec.Sink == nil {
+		return result, nil
+	}
+	return result, spec.Sink.Write(ctx, result.Project)
+}
@@ -1,21 +1,297 @@
 package questions
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/go-sova/sova-cli/internal/conflict"
+	"github.com/go-sova/sova-cli/templates"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
 )
 
+// ProjectAnswers holds the project metadata and the answers to the prompts
+// declared in the template manifest, keyed by prompt name.
 type ProjectAnswers struct {
 	ProjectName string
 	ProjectType string
-	UseZap      bool
-	UsePostgres bool
-	UseRedis    bool
-	UseRabbitMQ bool
+	ModulePath  string
+	Author      string
+	License     string
+	GoVersion   string
+	Values      map[string]interface{}
+}
+
+// Preset holds answers supplied before any prompt is shown, for example from
+// command-line flags or an answers file. Empty fields and prompts without a
+// value are asked interactively.
+type Preset struct {
+	ProjectName string `yaml:"name" json:"name"`
+	ProjectType string `yaml:"type" json:"type"`
+	ModulePath  string `yaml:"module" json:"module"`
+	Author      string `yaml:"author" json:"author"`
+	License     string `yaml:"license" json:"license"`
+	GoVersion   string `yaml:"goVersion" json:"goVersion"`
+	// Components answers confirm prompts by alias, e.g. postgres: true
+	Components map[string]bool `yaml:"components" json:"components"`
+	// Values answers prompts by name or alias
+	Values map[string]interface{} `yaml:"values" json:"values"`
+
+	useDefaults bool
+}
+
+// Bool returns a boolean answer, or false when the prompt was not answered
+// or is not a confirm prompt.
+func (a *ProjectAnswers) Bool(name string) bool {
+	b, _ := a.Values[name].(bool)
+	return b
+}
+
+// IsInteractive reports whether stdin is attached to a terminal, i.e. whether
+// survey prompts can be answered.
+func IsInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// ProjectTypes returns the available project types.
+func ProjectTypes() []string {
+	types, _ := templates.ProjectTypes()
+	return types
+}
+
+// Components returns the aliases of the confirm prompts of a project type,
+// i.e. the optional components that can be enabled with --with.
+func Components(projectType string) ([]string, error) {
+	manifest, err := loadManifest(projectType)
+	if err != nil {
+		return nil, err
+	}
+
+	var components []string
+	for _, prompt := range manifest.Prompts {
+		if prompt.Type == templates.PromptConfirm && prompt.Alias != "" {
+			components = append(components, prompt.Alias)
+		}
+	}
+	return components, nil
+}
+
+// Component returns the confirm prompt of a project type whose alias is
+// component.
+func Component(projectType, component string) (templates.Prompt, error) {
+	manifest, err := loadManifest(projectType)
+	if err != nil {
+		return templates.Prompt{}, err
+	}
+
+	var available []string
+	for _, prompt := range manifest.Prompts {
+		if prompt.Type != templates.PromptConfirm || prompt.Alias == "" {
+			continue
+		}
+		if prompt.Alias == strings.ToLower(strings.TrimSpace(component)) {
+			return prompt, nil
+		}
+		available = append(available, prompt.Alias)
+	}
+	return templates.Prompt{}, fmt.Errorf("unknown component %q for %s projects (available: %s)", component, projectType, strings.Join(available, ", "))
+}
+
+// PresetFromAnswers returns a preset that answers the prompts of a project
+// type the way answers does. Prompts that were skipped because their
+// condition was false are left open, so they are asked when a changed
+// answer makes their condition true.
+func PresetFromAnswers(answers *ProjectAnswers) (*Preset, error) {
+	manifest, err := loadManifest(answers.ProjectType)
+	if err != nil {
+		return nil, err
+	}
+
+	preset := &Preset{
+		ProjectName: answers.ProjectName,
+		ProjectType: answers.ProjectType,
+		ModulePath:  answers.ModulePath,
+		Author:      answers.Author,
+		License:     answers.License,
+		GoVersion:   answers.GoVersion,
+	}
+	for _, prompt := range manifest.Prompts {
+		value, ok := answers.Values[prompt.Name]
+		if !ok {
+			continue
+		}
+		if asked, err := templates.EvalCondition(prompt.When, answers.Values); err != nil || !asked {
+			continue
+		}
+		preset.Set(prompt.Name, value)
+	}
+	return preset, nil
+}
+
+func loadManifest(projectType string) (*templates.Manifest, error) {
+	for _, known := range ProjectTypes() {
+		if known == projectType {
+			return templates.LoadManifest(projectType)
+		}
+	}
+	return nil, fmt.Errorf("unsupported project type: %s", projectType)
+}
+
+// LoadPreset reads an answers file. Files ending in .json are decoded as
+// JSON, everything else as YAML. Unknown keys are rejected so that typos do
+// not silently fall back to prompts or defaults.
+func LoadPreset(path string) (*Preset, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read answers file: %w", err)
+	}
+
+	preset := &Preset{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(preset)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(preset)
+		if err == io.EOF {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse answers file %s: %w", path, err)
+	}
+
+	for component := range preset.Components {
+		if lower := strings.ToLower(component); lower != component {
+			preset.Components[lower] = preset.Components[component]
+			delete(preset.Components, component)
+		}
+	}
+
+	if preset.ProjectType != "" {
+		manifest, err := loadManifest(preset.ProjectType)
+		if err != nil {
+			return nil, fmt.Errorf("invalid answers file %s: %w", path, err)
+		}
+		if err := preset.check(manifest); err != nil {
+			return nil, fmt.Errorf("invalid answers file %s: %w", path, err)
+		}
+	}
+
+	return preset, nil
+}
+
+// check rejects components and values that no prompt of the manifest accepts
+func (p *Preset) check(manifest *templates.Manifest) error {
+	for component := range p.Components {
+		prompt, ok := findPrompt(manifest.Prompts, component)
+		if !ok || prompt.Type != templates.PromptConfirm {
+			return fmt.Errorf("unknown component %q for %s projects", component, manifest.Name)
+		}
+	}
+	for name, value := range p.Values {
+		prompt, ok := findPrompt(manifest.Prompts, name)
+		if !ok {
+			return fmt.Errorf("unknown value %q for %s projects", name, manifest.Name)
+		}
+		if _, err := prompt.Coerce(value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ApplyMetadataDefaults fills the metadata fields the preset leaves empty.
+func (p *Preset) ApplyMetadataDefaults(author, license, goVersion string) {
+	if p.Author == "" {
+		p.Author = author
+	}
+	if p.License == "" {
+		p.License = license
+	}
+	if p.GoVersion == "" {
+		p.GoVersion = goVersion
+	}
+}
+
+// With enables exactly the given components and disables every other
+// component of the project type.
+func (p *Preset) With(projectType string, components []string) error {
+	available, err := Components(projectType)
+	if err != nil {
+		return err
+	}
+
+	if p.Components == nil {
+		p.Components = make(map[string]bool)
+	}
+	for _, component := range available {
+		p.Components[component] = false
+	}
+
+	for _, component := range components {
+		component = strings.ToLower(strings.TrimSpace(component))
+		if component == "" {
+			continue
+		}
+		if _, ok := p.Components[component]; !ok {
+			return fmt.Errorf("unknown component %q for %s projects (available: %s)", component, projectType, strings.Join(available, ", "))
+		}
+		p.Components[component] = true
+	}
+
+	return nil
+}
+
+// Set answers the prompt with the given name or alias, e.g. from --set.
+func (p *Preset) Set(name string, value interface{}) {
+	if p.Values == nil {
+		p.Values = make(map[string]interface{})
+	}
+	p.Values[name] = value
+}
+
+// ApplyDefaults answers every prompt the preset leaves open with its default
+// instead of asking.
+func (p *Preset) ApplyDefaults(projectType string) error {
+	if _, err := loadManifest(projectType); err != nil {
+		return err
+	}
+	p.useDefaults = true
+	return nil
+}
+
+// lookup returns the preset answer for a prompt, if any
+func (p *Preset) lookup(prompt templates.Prompt) (interface{}, bool) {
+	for _, key := range []string{prompt.Name, prompt.Alias} {
+		if key == "" {
+			continue
+		}
+		if value, ok := p.Values[key]; ok {
+			return value, true
+		}
+	}
+	if prompt.Type == templates.PromptConfirm && prompt.Alias != "" {
+		if value, ok := p.Components[prompt.Alias]; ok {
+			return value, true
+		}
+	}
+	return nil, false
 }
 
 func AskProjectName() (string, error) {
+	if !IsInteractive() {
+		return "", fmt.Errorf("project name is required when stdin is not a terminal")
+	}
+
 	var name string
 	prompt := &survey.Input{
 		Message: "What is your project name?",
@@ -35,11 +311,20 @@ func AskProjectName() (string, error) {
 }
 
 func AskProjectType() (string, error) {
+	return AskProjectTypeWithDefault("api")
+}
+
+// AskProjectTypeWithDefault asks for the project type, preselecting defaultType.
+func AskProjectTypeWithDefault(defaultType string) (string, error) {
+	if !IsInteractive() {
+		return "", fmt.Errorf("project type is required when stdin is not a terminal")
+	}
+
 	var projectType string
 	prompt := &survey.Select{
 		Message: "What type of project are you building?",
-		Options: []string{"api", "cli"},
-		Default: "api",
+		Options: ProjectTypes(),
+		Default: defaultType,
 	}
 
 	err := survey.AskOne(prompt, &projectType)
@@ -50,65 +335,192 @@ func AskProjectType() (string, error) {
 	return projectType, nil
 }
 
-func AskProjectQuestions(projectType string) (*ProjectAnswers, error) {
-	answers := &ProjectAnswers{
-		ProjectType: projectType,
+// conflictChoices are the answers AskConflict offers, with the decision
+// they stand for and whether it applies to every remaining file
+var conflictChoices = []struct {
+	label    string
+	decision conflict.Policy
+	all      bool
+}{
+	{"Overwrite", conflict.Overwrite, false},
+	{"Skip (keep the existing file)", conflict.Skip, false},
+	{"Back up and overwrite", conflict.Backup, false},
+	{"Overwrite all remaining files", conflict.Overwrite, true},
+	{"Skip all remaining files", conflict.Skip, true},
+}
+
+// AskConflict asks what to do with the existing file at path, which differs
+// from what sova is about to write. all reports whether the decision should
+// be applied to every remaining file without asking again.
+func AskConflict(path string) (decision conflict.Policy, all bool, err error) {
+	if !IsInteractive() {
+		return "", false, fmt.Errorf("%s already exists; cannot ask what to do with it when stdin is not a terminal", path)
 	}
 
-	switch projectType {
-	case "api":
-		prompt := &survey.Confirm{
-			Message: "Would you like to use zap as a logger?",
-			Default: true,
-		}
-		err := survey.AskOne(prompt, &answers.UseZap)
-		if err != nil {
-			return nil, err
-		}
+	options := make([]string, len(conflictChoices))
+	for i, choice := range conflictChoices {
+		options[i] = choice.label
+	}
+
+	var answer string
+	prompt := &survey.Select{
+		Message: fmt.Sprintf("%s already exists. What do you want to do with it?", path),
+		Options: options,
+	}
+	if err := survey.AskOne(prompt, &answer); err != nil {
+		return "", false, fmt.Errorf("failed to get an answer for %s: %v", path, err)
+	}
 
-		prompt = &survey.Confirm{
-			Message: "Would you like to use PostgreSQL?",
-			Default: true,
+	for _, choice := range conflictChoices {
+		if choice.label == answer {
+			return choice.decision, choice.all, nil
 		}
-		err = survey.AskOne(prompt, &answers.UsePostgres)
+	}
+	return "", false, fmt.Errorf("unexpected answer %q for %s", answer, path)
+}
+
+// AskRunCommands asks whether the shell commands of the hooks of template may
+// run. The answer defaults to no.
+func AskRunCommands(template string) (bool, error) {
+	if !IsInteractive() {
+		return false, fmt.Errorf("cannot ask whether to run the hooks of %s when stdin is not a terminal", template)
+	}
+
+	var answer bool
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf("Run the commands of template %s?", template),
+		Default: false,
+	}
+	if err := survey.AskOne(prompt, &answer); err != nil {
+		return false, fmt.Errorf("failed to get an answer for the hooks of %s: %v", template, err)
+	}
+	return answer, nil
+}
+
+func AskProjectQuestions(projectType string) (*ProjectAnswers, error) {
+	return AskProjectQuestionsWithPreset(projectType, &Preset{})
+}
+
+// AskProjectQuestionsWithPreset asks the prompts declared in the manifest of
+// projectType, skipping those the preset already answers. It fails instead
+// of prompting when stdin is not a terminal.
+func AskProjectQuestionsWithPreset(projectType string, preset *Preset) (*ProjectAnswers, error) {
+	manifest, err := loadManifest(projectType)
+	if err != nil {
+		return nil, err
+	}
+	if err := preset.check(manifest); err != nil {
+		return nil, err
+	}
+
+	values, err := AskPrompts(manifest.Prompts, preset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProjectAnswers{
+		ProjectType: projectType,
+		ModulePath:  preset.ModulePath,
+		Author:      preset.Author,
+		License:     preset.License,
+		GoVersion:   preset.GoVersion,
+		Values:      values,
+	}, nil
+}
+
+// AskPrompts answers prompts in order. A prompt whose when condition is
+// false given the earlier answers is skipped and gets its zero value.
+func AskPrompts(prompts []templates.Prompt, preset *Preset) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+
+	for _, prompt := range prompts {
+		ok, err := templates.EvalCondition(prompt.When, values)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%s: %w", prompt.Name, err)
+		}
+		if !ok {
+			values[prompt.Name] = prompt.ZeroValue()
+			continue
 		}
 
-		prompt = &survey.Confirm{
-			Message: "Would you like to use Redis?",
-			Default: false,
+		if value, ok := preset.lookup(prompt); ok {
+			coerced, err := prompt.Coerce(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", prompt.Name, err)
+			}
+			values[prompt.Name] = coerced
+			continue
 		}
-		err = survey.AskOne(prompt, &answers.UseRedis)
-		if err != nil {
-			return nil, err
+
+		if preset.useDefaults {
+			values[prompt.Name] = prompt.DefaultValue()
+			continue
 		}
 
-		prompt = &survey.Confirm{
-			Message: "Would you like to use RabbitMQ?",
-			Default: false,
+		if !IsInteractive() {
+			name := prompt.Name
+			if prompt.Alias != "" {
+				name = prompt.Alias
+			}
+			return nil, fmt.Errorf("no answer for %q and stdin is not a terminal", name)
 		}
-		err = survey.AskOne(prompt, &answers.UseRabbitMQ)
+
+		value, err := ask(prompt)
 		if err != nil {
 			return nil, err
 		}
-	case "cli":
+		values[prompt.Name] = value
+	}
 
-		prompt := &survey.Confirm{
-			Message: "Would you like to use zap as a logger?",
-			Default: false,
+	return values, nil
+}
+
+func ask(prompt templates.Prompt) (interface{}, error) {
+	message := prompt.Message
+	if message == "" {
+		message = prompt.Name
+	}
+
+	switch prompt.Type {
+	case templates.PromptConfirm:
+		value, _ := prompt.DefaultValue().(bool)
+		err := survey.AskOne(&survey.Confirm{Message: message, Help: prompt.Help, Default: value}, &value)
+		return value, err
+
+	case templates.PromptSelect:
+		var value string
+		question := &survey.Select{Message: message, Help: prompt.Help, Options: prompt.Options}
+		if def, _ := prompt.DefaultValue().(string); def != "" {
+			question.Default = def
 		}
-		err := survey.AskOne(prompt, &answers.UseZap)
-		if err != nil {
-			return nil, err
+		err := survey.AskOne(question, &value)
+		return value, err
+
+	case templates.PromptMultiSelect:
+		value := []string{}
+		question := &survey.MultiSelect{Message: message, Help: prompt.Help, Options: prompt.Options}
+		if def, _ := prompt.DefaultValue().([]string); len(def) > 0 {
+			question.Default = def
 		}
+		err := survey.AskOne(question, &value)
+		return value, err
 
-		answers.UsePostgres = false
-		answers.UseRedis = false
-		answers.UseRabbitMQ = false
 	default:
-		return nil, fmt.Errorf("unsupported project type: %s", projectType)
+		var value string
+		def, _ := prompt.DefaultValue().(string)
+		validator := func(answer interface{}) error {
+			return prompt.ValidateInput(fmt.Sprint(answer))
+		}
+		err := survey.AskOne(&survey.Input{Message: message, Help: prompt.Help, Default: def}, &value, survey.WithValidator(validator))
+		return value, err
 	}
+}
 
-	return answers, nil
+func findPrompt(prompts []templates.Prompt, name string) (templates.Prompt, bool) {
+	for _, prompt := range prompts {
+		if prompt.Name == name || (prompt.Alias != "" && prompt.Alias == name) {
+			return prompt, true
+		}
+	}
+	return templates.Prompt{}, false
 }
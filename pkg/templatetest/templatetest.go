@@ -0,0 +1,102 @@
+// Package templatetest checks sova templates against golden files from Go
+// tests. A template repository can test every template it provides with
+//
+//	func TestTemplates(t *testing.T) {
+//		templatetest.RunDir(t, ".")
+//	}
+//
+// and review changes to the generated projects as changes to the golden
+// files. The golden files are rewritten when the test binary has a boolean
+// -update flag that is set, or when SOVA_UPDATE_GOLDEN is true. The package
+// does not define the flag itself, so that it does not clash with a test
+// package that does:
+//
+//	var update = flag.Bool("update", false, "rewrite the golden files")
+//
+// The testdata directory of a template holds one answers file per case, in
+// the format read by `sova init --answers`, next to the expected project of
+// that case, with a .golden suffix on every file:
+//
+//	worker/
+//	├── template.yaml
+//	├── main.tpl
+//	└── testdata/
+//	    ├── default.yaml
+//	    └── default/
+//	        ├── go.mod.golden
+//	        └── main.go.golden
+package templatetest
+
+import (
+	"context"
+	"flag"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/golden"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+// UpdateEnv is the environment variable that rewrites the golden files when
+// set to true, for test binaries without an -update flag
+const UpdateEnv = "SOVA_UPDATE_GOLDEN"
+
+// updating reports whether the golden files should be rewritten
+func updating() bool {
+	if f := flag.Lookup("update"); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			if update, ok := getter.Get().(bool); ok && update {
+				return true
+			}
+		}
+	}
+	update, _ := strconv.ParseBool(os.Getenv(UpdateEnv))
+	return update
+}
+
+// Run renders projectType with every case in dir and reports every generated
+// file that does not match its golden file. With -update or UpdateEnv the
+// golden files are rewritten instead.
+func Run(t testing.TB, projectType, dir string) {
+	t.Helper()
+
+	results, err := golden.Run(context.Background(), projectType, dir, updating())
+	if err != nil {
+		t.Fatalf("Failed to render %s: %v", projectType, err)
+	}
+
+	failed := false
+	for _, result := range results {
+		if result.Updated {
+			t.Logf("Updated golden files of %s case %s", projectType, result.Case)
+		}
+		for _, difference := range result.Differences {
+			failed = true
+			t.Errorf("%s case %s: %s", projectType, result.Case, difference)
+		}
+	}
+	if failed {
+		t.Logf("Run the test with -update or %s=1 to accept the generated files as the new golden files", UpdateEnv)
+	}
+}
+
+// RunDir makes the templates in dir available, as `sova init --template dir`
+// would, and runs every project type it provides against its testdata
+// directory. The template directories and sources in use before are
+// restored when the test finishes.
+func RunDir(t *testing.T, dir string) {
+	t.Helper()
+
+	t.Cleanup(templates.SaveTemplateSources())
+	targets, err := golden.AddSource(dir)
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+
+	for _, target := range targets {
+		t.Run(target.ProjectType, func(t *testing.T) {
+			Run(t, target.ProjectType, target.Dir)
+		})
+	}
+}
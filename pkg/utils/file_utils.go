@@ -119,6 +119,19 @@ func CopyDir(src, dst string) error {
 	return nil
 }
 
+// ExpandPath replaces a leading ~ with the home directory of the current user
+func ExpandPath(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
 func GetFileExtension(filename string) string {
 	return strings.TrimPrefix(filepath.Ext(filename), ".")
 }
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// words splits an identifier or phrase into its words: at every character
+// that is not a letter or digit, and where the case changes, so that
+// "HTTPServer", "http_server" and "http-server" all become "HTTP"/"http"
+// and "Server"/"server"
+func words(s string) []string {
+	var result []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, string(current))
+			current = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && len(current) > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || nextLower {
+				flush()
+			}
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	return result
+}
+
+func title(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// CamelCase converts s to camelCase: "user_name" becomes "userName"
+func CamelCase(s string) string {
+	parts := words(s)
+	for i, word := range parts {
+		if i == 0 {
+			parts[i] = strings.ToLower(word)
+		} else {
+			parts[i] = title(word)
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// PascalCase converts s to PascalCase: "user_name" becomes "UserName"
+func PascalCase(s string) string {
+	parts := words(s)
+	for i, word := range parts {
+		parts[i] = title(word)
+	}
+	return strings.Join(parts, "")
+}
+
+// SnakeCase converts s to snake_case: "UserName" becomes "user_name"
+func SnakeCase(s string) string {
+	return strings.ToLower(strings.Join(words(s), "_"))
+}
+
+// KebabCase converts s to kebab-case: "UserName" becomes "user-name"
+func KebabCase(s string) string {
+	return strings.ToLower(strings.Join(words(s), "-"))
+}
+
+// inflection replaces the suffix from of a word with to
+type inflection struct {
+	from, to string
+}
+
+// pluralRules and singularRules are tried in order; the first rule whose
+// suffix matches wins. A word that already has the target form matches a
+// rule that leaves it alone.
+var pluralRules = []inflection{
+	{"quiz", "quizzes"},
+	{"sis", "ses"},
+	{"ay", "ays"}, {"ey", "eys"}, {"oy", "oys"}, {"uy", "uys"},
+	{"y", "ies"},
+	{"ss", "sses"}, {"us", "uses"},
+	{"s", "s"},
+	{"x", "xes"}, {"z", "zes"}, {"ch", "ches"}, {"sh", "shes"},
+	{"", "s"},
+}
+
+var singularRules = []inflection{
+	{"quizzes", "quiz"},
+	{"yses", "ysis"},
+	{"ies", "y"},
+	{"sses", "ss"},
+	{"tuses", "tus"}, {"ruses", "rus"}, {"buses", "bus"}, {"nuses", "nus"},
+	{"aches", "ache"}, {"xes", "x"}, {"ches", "ch"}, {"shes", "sh"},
+	{"ss", "ss"}, {"us", "us"}, {"is", "is"},
+	{"s", ""},
+}
+
+// irregularWords maps singular words to their plural. They are matched
+// against the last word only, so "human" does not become "humen".
+var irregularWords = map[string]string{
+	"person": "people", "child": "children", "man": "men", "woman": "women",
+	"mouse": "mice", "goose": "geese", "tooth": "teeth", "foot": "feet", "ox": "oxen",
+	"leaf": "leaves", "life": "lives", "knife": "knives", "wife": "wives",
+	"half": "halves", "wolf": "wolves", "shelf": "shelves", "thief": "thieves",
+	"hero": "heroes", "potato": "potatoes", "tomato": "tomatoes", "echo": "echoes",
+	"movie": "movies", "criterion": "criteria",
+}
+
+// uncountableWords have no separate plural
+var uncountableWords = map[string]bool{
+	"data": true, "metadata": true, "information": true, "equipment": true,
+	"feedback": true, "software": true, "hardware": true, "money": true,
+	"news": true, "rice": true, "series": true, "species": true,
+	"sheep": true, "fish": true, "deer": true,
+}
+
+// Plural returns the English plural of the last word of s: "Category"
+// becomes "Categories" and "user_address" becomes "user_addresses"
+func Plural(s string) string {
+	return inflect(s, pluralRules, irregularWords)
+}
+
+// Singular returns the English singular of the last word of s: "Categories"
+// becomes "Category" and "people" becomes "person"
+func Singular(s string) string {
+	singulars := make(map[string]string, len(irregularWords))
+	for one, many := range irregularWords {
+		singulars[many] = one
+	}
+	return inflect(s, singularRules, singulars)
+}
+
+func inflect(s string, rules []inflection, irregular map[string]string) string {
+	parts := words(s)
+	if len(parts) == 0 {
+		return s
+	}
+	last := parts[len(parts)-1]
+	lower := strings.ToLower(last)
+	if uncountableWords[lower] {
+		return s
+	}
+	if to, ok := irregular[lower]; ok {
+		return replaceSuffix(s, last, to)
+	}
+
+	lowerS := strings.ToLower(s)
+	for _, rule := range rules {
+		if strings.HasSuffix(lowerS, rule.from) && len(rule.from) < len(lowerS) {
+			return replaceSuffix(s, s[len(s)-len(rule.from):], rule.to)
+		}
+	}
+	return s
+}
+
+// replaceSuffix replaces the suffix old of s with its new form, which is
+// lower case, in the case of old
+func replaceSuffix(s, old, new string) string {
+	prefix := s[:len(s)-len(old)]
+	switch {
+	case len(old) > 1 && old == strings.ToUpper(old) && old != strings.ToLower(old):
+		new = strings.ToUpper(new)
+	case old != "" && unicode.IsUpper([]rune(old)[0]):
+		new = title(new)
+	case old == "" && prefix != "" && strings.ToUpper(prefix) == prefix && strings.ToLower(prefix) != prefix:
+		new = strings.ToUpper(new)
+	}
+	return prefix + new
+}
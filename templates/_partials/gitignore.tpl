@@ -0,0 +1,48 @@
+{{define "gitignore-go" -}}
+# Binaries for programs and plugins
+*.exe
+*.exe~
+*.dll
+*.so
+*.dylib
+{{.ProjectName}}
+
+# Test binary, built with `go test -c`
+*.test
+
+# Output of the go coverage tool, specifically when used with LiteIDE
+*.out
+
+# Dependency directories (remove the comment below to include it)
+vendor/
+
+# Go workspace file
+go.work
+{{- end}}
+
+{{define "gitignore-editor" -}}
+# IDE specific files
+.idea/
+.vscode/
+*.swp
+*.swo
+
+# OS specific files
+.DS_Store
+.DS_Store?
+._*
+.Spotlight-V100
+.Trashes
+ehthumbs.db
+Thumbs.db
+
+# Logs
+*.log
+logs/
+{{- end}}
+
+{{define "gitignore-temp" -}}
+# Temporary files
+tmp/
+temp/
+{{- end}}
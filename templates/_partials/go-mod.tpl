@@ -0,0 +1,11 @@
+{{define "go-mod" -}}
+module {{.ModuleName}}
+
+go {{.GoVersion}}
+
+require (
+{{- range .Dependencies}}
+	{{.Name}} {{.Version}}
+{{- end}}
+)
+{{end}}
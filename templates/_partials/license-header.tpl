@@ -0,0 +1,7 @@
+{{define "license-header" -}}
+{{if .License -}}
+// Copyright (c) {{if .Author}}{{.Author}}{{else}}the {{.ProjectName}} authors{{end}}.
+// Licensed under the {{.License}} License. See LICENSE for details.
+
+{{end -}}
+{{end}}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// {{.Handler}} handles {{.Method}} {{.URL}}
+func {{.Handler}}(c *gin.Context) {
+{{- range .Params}}
+	{{.Var}} := c.Param("{{.Name}}")
+{{- end}}
+
+	// TODO: implement {{.Handler}}
+	c.JSON(http.StatusNotImplemented, gin.H{
+		"error": "not implemented",
+{{- range .Params}}
+		"{{.Name}}": {{.Var}},
+{{- end}}
+	})
+}
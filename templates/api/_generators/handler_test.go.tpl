@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func Test{{.Handler}}(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.{{.Method}}("{{.Path}}", {{.Handler}})
+
+	req := httptest.NewRequest(http.Method{{.Method | lower | pascal}}, "{{.ExamplePath}}", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	// TODO: check the response {{.Handler}} is meant to send
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status %d, got %d", http.StatusNotImplemented, rec.Code)
+	}
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS {{.Resource.Table}};
@@ -0,0 +1,9 @@
+{{- $r := .Resource -}}
+CREATE TABLE {{$r.Table}} (
+    id BIGSERIAL PRIMARY KEY,
+{{- range $r.Fields}}
+    {{.Column}} {{.SQL}} NOT NULL{{if .Unique}} UNIQUE{{end}},
+{{- end}}
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
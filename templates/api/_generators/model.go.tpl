@@ -0,0 +1,15 @@
+package models
+
+import (
+	"time"
+)
+
+// {{.Resource.Model}} is a row of the {{.Resource.Table}} table
+type {{.Resource.Model}} struct {
+	ID int64 `json:"id" db:"id"`
+{{- range .Resource.Fields}}
+	{{.Name}} {{.GoType}} `json:"{{.Column}}" db:"{{.Column}}"`
+{{- end}}
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
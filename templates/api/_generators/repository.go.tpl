@@ -0,0 +1,83 @@
+{{- $r := .Resource -}}
+{{- $noun := $r.Model | snake | replace "_" " " -}}
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"{{.ModuleName}}/internal/models"
+)
+
+// {{$r.Model}}Repository stores {{$noun | plural}} in the {{$r.Table}} table. Get, Update
+// and Delete return sql.ErrNoRows when there is no {{$noun}} with the id.
+type {{$r.Model}}Repository struct {
+	db *sql.DB
+}
+
+// New{{$r.Model}}Repository returns a repository using db, e.g. service.DB
+func New{{$r.Model}}Repository(db *sql.DB) *{{$r.Model}}Repository {
+	return &{{$r.Model}}Repository{db: db}
+}
+
+// List returns every {{$noun}}, ordered by id
+func (r *{{$r.Model}}Repository) List(ctx context.Context) ([]models.{{$r.Model}}, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, {{$r.Columns}}, created_at, updated_at FROM {{$r.Table}} ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	{{$r.Var | plural}} := []models.{{$r.Model}}{}
+	for rows.Next() {
+		var {{$r.Var}} models.{{$r.Model}}
+		if err := rows.Scan(&{{$r.Var}}.ID{{range $r.Fields}}, &{{$r.Var}}.{{.Name}}{{end}}, &{{$r.Var}}.CreatedAt, &{{$r.Var}}.UpdatedAt); err != nil {
+			return nil, err
+		}
+		{{$r.Var | plural}} = append({{$r.Var | plural}}, {{$r.Var}})
+	}
+	return {{$r.Var | plural}}, rows.Err()
+}
+
+// Get returns the {{$noun}} with id
+func (r *{{$r.Model}}Repository) Get(ctx context.Context, id int64) (*models.{{$r.Model}}, error) {
+	var {{$r.Var}} models.{{$r.Model}}
+	err := r.db.QueryRowContext(ctx, `SELECT id, {{$r.Columns}}, created_at, updated_at FROM {{$r.Table}} WHERE id = $1`, id).
+		Scan(&{{$r.Var}}.ID{{range $r.Fields}}, &{{$r.Var}}.{{.Name}}{{end}}, &{{$r.Var}}.CreatedAt, &{{$r.Var}}.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &{{$r.Var}}, nil
+}
+
+// Create inserts {{$r.Var}} and sets its id and timestamps
+func (r *{{$r.Model}}Repository) Create(ctx context.Context, {{$r.Var}} *models.{{$r.Model}}) error {
+	return r.db.QueryRowContext(ctx,
+		`INSERT INTO {{$r.Table}} ({{$r.Columns}}) VALUES ({{$r.Placeholders}}) RETURNING id, created_at, updated_at`,
+		{{range $i, $f := $r.Fields}}{{if $i}}, {{end}}{{$r.Var}}.{{$f.Name}}{{end}},
+	).Scan(&{{$r.Var}}.ID, &{{$r.Var}}.CreatedAt, &{{$r.Var}}.UpdatedAt)
+}
+
+// Update saves the fields of {{$r.Var}} and sets its timestamps
+func (r *{{$r.Model}}Repository) Update(ctx context.Context, {{$r.Var}} *models.{{$r.Model}}) error {
+	return r.db.QueryRowContext(ctx,
+		`UPDATE {{$r.Table}} SET {{$r.Assignments}}, updated_at = NOW() WHERE id = {{$r.IDPlaceholder}} RETURNING created_at, updated_at`,
+		{{range $r.Fields}}{{$r.Var}}.{{.Name}}, {{end}}{{$r.Var}}.ID,
+	).Scan(&{{$r.Var}}.CreatedAt, &{{$r.Var}}.UpdatedAt)
+}
+
+// Delete deletes the {{$noun}} with id
+func (r *{{$r.Model}}Repository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM {{$r.Table}} WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+{{- $r := .Resource -}}
+{{- $noun := $r.Model | snake | replace "_" " " -}}
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"{{.ModuleName}}/internal/models"
+	"{{.ModuleName}}/internal/repository"
+	"{{.ModuleName}}/internal/service"
+)
+
+// {{$r.Var}}Input is the body of a request that creates or updates a {{$noun}}
+type {{$r.Var}}Input struct {
+{{- range $r.Fields}}
+	{{.Name}} {{.GoType}} `json:"{{.Column}}"`
+{{- end}}
+}
+
+func {{$r.Var}}ID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func {{$r.Var}}Error(c *gin.Context, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "{{$noun}} not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// {{$r.ListHandler}} handles GET {{.URL}}
+func {{$r.ListHandler}}(c *gin.Context) {
+	{{$r.Var | plural}}, err := repository.New{{$r.Model}}Repository(service.DB).List(c.Request.Context())
+	if err != nil {
+		{{$r.Var}}Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, {{$r.Var | plural}})
+}
+
+// {{$r.GetHandler}} handles GET {{.URL}}/:id
+func {{$r.GetHandler}}(c *gin.Context) {
+	id, ok := {{$r.Var}}ID(c)
+	if !ok {
+		return
+	}
+	{{$r.Var}}, err := repository.New{{$r.Model}}Repository(service.DB).Get(c.Request.Context(), id)
+	if err != nil {
+		{{$r.Var}}Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, {{$r.Var}})
+}
+
+// {{$r.CreateHandler}} handles POST {{.URL}}
+func {{$r.CreateHandler}}(c *gin.Context) {
+	var input {{$r.Var}}Input
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	{{$r.Var}} := models.{{$r.Model}}{
+{{- range $r.Fields}}
+		{{.Name}}: input.{{.Name}},
+{{- end}}
+	}
+	if err := repository.New{{$r.Model}}Repository(service.DB).Create(c.Request.Context(), &{{$r.Var}}); err != nil {
+		{{$r.Var}}Error(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, {{$r.Var}})
+}
+
+// {{$r.UpdateHandler}} handles PUT {{.URL}}/:id
+func {{$r.UpdateHandler}}(c *gin.Context) {
+	id, ok := {{$r.Var}}ID(c)
+	if !ok {
+		return
+	}
+	var input {{$r.Var}}Input
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	{{$r.Var}} := models.{{$r.Model}}{
+		ID: id,
+{{- range $r.Fields}}
+		{{.Name}}: input.{{.Name}},
+{{- end}}
+	}
+	if err := repository.New{{$r.Model}}Repository(service.DB).Update(c.Request.Context(), &{{$r.Var}}); err != nil {
+		{{$r.Var}}Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, {{$r.Var}})
+}
+
+// {{$r.DeleteHandler}} handles DELETE {{.URL}}/:id
+func {{$r.DeleteHandler}}(c *gin.Context) {
+	id, ok := {{$r.Var}}ID(c)
+	if !ok {
+		return
+	}
+	if err := repository.New{{$r.Model}}Repository(service.DB).Delete(c.Request.Context(), id); err != nil {
+		{{$r.Var}}Error(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,48 @@
+{{- $r := .Resource -}}
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The requests below are rejected before the database is used. Tests that
+// reach {{$r.Table}} need a database at DATABASE_URL.
+func Test{{$r.Plural}}HandlersRejectInvalidRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("{{$r.Path}}/:id", {{$r.GetHandler}})
+	router.POST("{{$r.Path}}", {{$r.CreateHandler}})
+	router.PUT("{{$r.Path}}/:id", {{$r.UpdateHandler}})
+	router.DELETE("{{$r.Path}}/:id", {{$r.DeleteHandler}})
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{name: "Get with invalid id", method: http.MethodGet, path: "{{$r.Path}}/abc"},
+		{name: "Create with invalid body", method: http.MethodPost, path: "{{$r.Path}}", body: "{"},
+		{name: "Update with invalid id", method: http.MethodPut, path: "{{$r.Path}}/abc", body: "{}"},
+		{name: "Update with invalid body", method: http.MethodPut, path: "{{$r.Path}}/1", body: "{"},
+		{name: "Delete with invalid id", method: http.MethodDelete, path: "{{$r.Path}}/abc"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+			}
+		})
+	}
+}
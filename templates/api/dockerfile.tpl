@@ -30,4 +30,4 @@ COPY .env .
 EXPOSE 8080
 
 # Run the application
-CMD ["./main"] 
\ No newline at end of file
+CMD ["./main"]
@@ -1,44 +1,9 @@
-# Binaries for programs and plugins
-*.exe
-*.exe~
-*.dll
-*.so
-*.dylib
-{{.ProjectName}}
-
-# Test binary, built with `go test -c`
-*.test
-
-# Output of the go coverage tool, specifically when used with LiteIDE
-*.out
-
-# Dependency directories (remove the comment below to include it)
-vendor/
-
-# Go workspace file
-go.work
+{{template "gitignore-go" .}}
 
 # Environment variables
 .env
 
-# IDE specific files
-.idea/
-.vscode/
-*.swp
-*.swo
-
-# OS specific files
-.DS_Store
-.DS_Store?
-._*
-.Spotlight-V100
-.Trashes
-ehthumbs.db
-Thumbs.db
-
-# Logs
-*.log
-logs/
+{{template "gitignore-editor" .}}
 
 # Docker volumes
 data/
@@ -46,6 +11,4 @@ postgres_data/
 redis_data/
 rabbitmq_data/
 
-# Temporary files
-tmp/
-temp/ 
\ No newline at end of file
+{{template "gitignore-temp" .}}
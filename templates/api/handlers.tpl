@@ -23,7 +23,6 @@ func PingHandler(c *gin.Context) {
 	})
 }
 
-
 // NotFoundHandler handles 404 errors
 func NotFoundHandler(c *gin.Context) {
 	c.JSON(http.StatusNotFound, gin.H{
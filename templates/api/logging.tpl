@@ -2,8 +2,9 @@ package middleware
 
 import (
 	"time"
-	"go.uber.org/zap"
+
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 var logger *zap.Logger
@@ -34,4 +35,4 @@ func LoggingMiddleware() gin.HandlerFunc {
 			zap.Duration("latency", latency),
 		)
 	}
-} 
\ No newline at end of file
+}
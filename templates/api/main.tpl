@@ -1,10 +1,12 @@
-package main
+{{template "license-header" .}}package main
 
 import (
 	"log"
+
+	"github.com/joho/godotenv"
+
 	"{{.ModuleName}}/internal/server"
 	"{{.ModuleName}}/internal/service"
-	"github.com/joho/godotenv"
 )
 
 func main() {
@@ -24,4 +26,4 @@ func main() {
 	if err := srv.Start(); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
-} 
\ No newline at end of file
+}
@@ -13,14 +13,14 @@ func Logger() gin.HandlerFunc {
 		// Start time
 		start := time.Now()
 		path := c.Request.URL.Path
-		
+
 		// Process request
 		c.Next()
-		
+
 		// End time
 		end := time.Now()
 		latency := end.Sub(start)
-		
+
 		// Log request
 		fmt.Printf("[%s] %s %s %d %s\n",
 			end.Format("2006-01-02 15:04:05"),
@@ -3,6 +3,7 @@ package service
 import (
 	"database/sql"
 	"os"
+
 	_ "github.com/lib/pq"
 )
 
@@ -14,11 +15,11 @@ func InitPostgres() error {
 	if err != nil {
 		return err
 	}
-	
+
 	if err := DB.Ping(); err != nil {
 		return err
 	}
-	
+
 	return nil
 }
 
@@ -26,4 +27,4 @@ func ClosePostgres() {
 	if DB != nil {
 		DB.Close()
 	}
-} 
\ No newline at end of file
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"os"
+
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
@@ -13,7 +14,7 @@ func InitRabbitMQ() error {
 	if err != nil {
 		return err
 	}
-	
+
 	return nil
 }
 
@@ -21,4 +22,4 @@ func CloseRabbitMQ() {
 	if RabbitMQ != nil {
 		RabbitMQ.Close()
 	}
-} 
\ No newline at end of file
+}
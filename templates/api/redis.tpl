@@ -1,8 +1,9 @@
 package service
 
 import (
-	"github.com/redis/go-redis/v9"
 	"os"
+
+	"github.com/redis/go-redis/v9"
 )
 
 var RedisClient *redis.Client
@@ -11,7 +12,7 @@ func InitRedis() error {
 	RedisClient = redis.NewClient(&redis.Options{
 		Addr: os.Getenv("REDIS_URL"),
 	})
-	
+
 	return nil
 }
 
@@ -19,4 +20,4 @@ func CloseRedis() {
 	if RedisClient != nil {
 		RedisClient.Close()
 	}
-} 
\ No newline at end of file
+}
@@ -3,8 +3,10 @@ package server
 import (
 	"fmt"
 	"os"
-	"{{.ModuleName}}/internal/routes"
+
 	"github.com/gin-gonic/gin"
+
+	"{{.ModuleName}}/internal/routes"
 )
 
 type Server struct {
@@ -29,4 +31,4 @@ func (s *Server) Start() error {
 
 	// Start server
 	return s.router.Run(fmt.Sprintf(":%s", port))
-} 
\ No newline at end of file
+}
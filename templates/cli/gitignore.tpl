@@ -1,41 +1,6 @@
-# Binaries for programs and plugins
-*.exe
-*.exe~
-*.dll
-*.so
-*.dylib
-{{.ProjectName}}
+{{template "gitignore-go" .}}
 
-# Test binary, built with `go test -c`
-*.test
-
-# Output of the go coverage tool, specifically when used with LiteIDE
-*.out
-
-# Dependency directories (remove the comment below to include it)
-vendor/
-
-# Go workspace file
-go.work
-
-# IDE specific files
-.idea/
-.vscode/
-*.swp
-*.swo
-
-# OS specific files
-.DS_Store
-.DS_Store?
-._*
-.Spotlight-V100
-.Trashes
-ehthumbs.db
-Thumbs.db
-
-# Logs
-*.log
-logs/
+{{template "gitignore-editor" .}}
 
 # Config files
 config.yaml
@@ -46,6 +11,4 @@ config.yml
 build/
 dist/
 
-# Temporary files
-tmp/
-temp/ 
\ No newline at end of file
+{{template "gitignore-temp" .}}
@@ -1,8 +1 @@
-module {{.ModuleName}}
-
-go {{.GoVersion}}
-
-require (
-	github.com/spf13/cobra v1.8.0
-	github.com/spf13/viper v1.18.1
-) 
\ No newline at end of file
+{{template "go-mod" .}}
\ No newline at end of file
@@ -1,4 +1,4 @@
-package main
+{{template "license-header" .}}package main
 
 import (
 	"{{.ModuleName}}/cmd"
@@ -6,4 +6,4 @@ import (
 
 func main() {
 	cmd.Execute()
-} 
\ No newline at end of file
+}
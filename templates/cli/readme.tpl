@@ -44,4 +44,4 @@ go build -o {{.ProjectName}}
 
 ## License
 
-This project is licensed under the {{.License}} License - see the LICENSE file for details. 
\ No newline at end of file
+This project is licensed under the {{.License}} License - see the LICENSE file for details.
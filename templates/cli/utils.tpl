@@ -1,9 +1,6 @@
-package internal
+package utils
 
-import (
-	"fmt"
-	"strings"
-)
+import "fmt"
 
 // Constants for terminal colors
 const (
@@ -44,4 +41,4 @@ func StringInSlice(a string, list []string) bool {
 		}
 	}
 	return false
-} 
\ No newline at end of file
+}
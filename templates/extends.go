@@ -0,0 +1,260 @@
+package templates
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Removals lists the entries a manifest drops from the manifest it extends
+type Removals struct {
+	// Files are targets
+	Files       []string `yaml:"files,omitempty"`
+	Directories []string `yaml:"directories,omitempty"`
+	// Prompts are prompt names
+	Prompts []string `yaml:"prompts,omitempty"`
+	// Dependencies are module paths
+	Dependencies []string `yaml:"dependencies,omitempty"`
+	// Hooks are commands, removed from both pre- and post-generate
+	Hooks []string `yaml:"hooks,omitempty"`
+}
+
+func (r Removals) empty() bool {
+	return len(r.Files) == 0 && len(r.Directories) == 0 && len(r.Prompts) == 0 &&
+		len(r.Dependencies) == 0 && len(r.Hooks) == 0
+}
+
+// Parents returns the project types the manifest extends, the nearest first
+func (m *Manifest) Parents() []string {
+	parents := make([]string, len(m.parents))
+	for i, parent := range m.parents {
+		parents[len(parents)-1-i] = parent
+	}
+	return parents
+}
+
+// PartialDirs returns the partial directories of the templates of
+// projectType, in the order they are parsed: the shared partials, those of
+// the project types it extends and its own
+func (m *Manifest) PartialDirs(projectType string) []string {
+	dirs := []string{PartialsDir}
+	for _, parent := range m.parents {
+		dirs = append(dirs, path.Join(parent, PartialsDir))
+	}
+	return append(dirs, path.Join(projectType, PartialsDir))
+}
+
+func (m *Manifest) checkExtends() error {
+	if m.Extends == "" {
+		if !m.Remove.empty() {
+			return fmt.Errorf("remove: only a manifest that extends another one can remove entries")
+		}
+		return nil
+	}
+	if !IsProjectTypeName(m.Extends) {
+		return fmt.Errorf("extends: invalid project type %q", m.Extends)
+	}
+	return nil
+}
+
+// extend merges the manifest of projectType with the manifest of the project
+// type it extends. The result has every entry of the parent that is not
+// removed, with those the child redeclares replaced in place, followed by
+// the new entries of the child:
+//
+//   - files are matched by target; an inherited file is rendered from the
+//     child's directory when the child has a template with the same source
+//   - directories are merged
+//   - prompts are matched by name, dependencies by module path, hook steps
+//     by command and functions by name
+//
+// The description is inherited when the child has none.
+func (m *Manifest) extend(fsys fs.FS, projectType string, chain []string) (*Manifest, error) {
+	chain = append(chain, projectType)
+	for _, name := range chain {
+		if name == m.Extends {
+			return nil, fmt.Errorf("invalid manifest for %s: circular extends: %s", projectType, strings.Join(append(chain, m.Extends), " -> "))
+		}
+	}
+
+	parent, err := loadManifest(fsys, m.Extends, chain)
+	if err != nil {
+		return nil, fmt.Errorf("%s extends %s: %w", projectType, m.Extends, err)
+	}
+
+	merged, err := m.merge(parent, fsys, projectType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest for %s: %w", projectType, err)
+	}
+	if err := merged.check(); err != nil {
+		return nil, fmt.Errorf("invalid manifest for %s: %w", projectType, err)
+	}
+	return merged, nil
+}
+
+func (m *Manifest) merge(parent *Manifest, fsys fs.FS, projectType string) (*Manifest, error) {
+	merged := &Manifest{
+		Name:        m.Name,
+		Version:     m.Version,
+		Description: m.Description,
+		Extends:     m.Extends,
+		Remove:      m.Remove,
+		parents:     append(append([]string(nil), parent.parents...), m.Extends),
+	}
+	if merged.Description == "" {
+		merged.Description = parent.Description
+	}
+
+	var err error
+	if merged.Files, err = m.mergeFiles(parent, fsys, projectType); err != nil {
+		return nil, err
+	}
+
+	identity := func(dir string) string { return dir }
+	removed, err := m.removals("directories", m.Remove.Directories, keysOf(parent.Directories, identity))
+	if err != nil {
+		return nil, err
+	}
+	merged.Directories = mergeEntries(parent.Directories, m.Directories, removed, identity)
+
+	promptName := func(prompt Prompt) string { return prompt.Name }
+	if removed, err = m.removals("prompts", m.Remove.Prompts, keysOf(parent.Prompts, promptName)); err != nil {
+		return nil, err
+	}
+	merged.Prompts = mergeEntries(parent.Prompts, m.Prompts, removed, promptName)
+
+	depName := func(dep Dependency) string { return dep.Name }
+	if removed, err = m.removals("dependencies", m.Remove.Dependencies, keysOf(parent.Dependencies, depName)); err != nil {
+		return nil, err
+	}
+	merged.Dependencies = mergeEntries(parent.Dependencies, m.Dependencies, removed, depName)
+
+	command := func(step HookStep) string { return step.Command }
+	steps := append(append([]HookStep(nil), parent.Hooks.PreGenerate...), parent.Hooks.PostGenerate...)
+	if removed, err = m.removals("hooks", m.Remove.Hooks, keysOf(steps, command)); err != nil {
+		return nil, err
+	}
+	merged.Hooks = Hooks{
+		PreGenerate:  mergeEntries(parent.Hooks.PreGenerate, m.Hooks.PreGenerate, removed, command),
+		PostGenerate: mergeEntries(parent.Hooks.PostGenerate, m.Hooks.PostGenerate, removed, command),
+	}
+
+	if len(parent.Funcs) > 0 || len(m.Funcs) > 0 {
+		merged.Funcs = make(map[string]string, len(parent.Funcs)+len(m.Funcs))
+		for name, text := range parent.Funcs {
+			merged.Funcs[name] = text
+		}
+		for name, text := range m.Funcs {
+			merged.Funcs[name] = text
+		}
+	}
+
+	return merged, nil
+}
+
+// mergeFiles merges the files of parent and m. Several entries may share a
+// target with different conditions, so redeclaring a target replaces all of
+// them.
+func (m *Manifest) mergeFiles(parent *Manifest, fsys fs.FS, projectType string) ([]FileSpec, error) {
+	removed, err := m.removals("files", m.Remove.Files, keysOf(parent.Files, func(file FileSpec) string { return file.Target }))
+	if err != nil {
+		return nil, err
+	}
+	redeclared := make(map[string]bool)
+	for _, file := range m.Files {
+		redeclared[file.Target] = true
+	}
+
+	var files []FileSpec
+	added := make(map[string]bool)
+	for _, file := range parent.Files {
+		if removed[file.Target] {
+			continue
+		}
+		if redeclared[file.Target] {
+			if !added[file.Target] {
+				added[file.Target] = true
+				files = append(files, m.filesFor(file.Target)...)
+			}
+			continue
+		}
+
+		if file.dir == "" {
+			file.dir = m.Extends
+		}
+		if _, err := fs.Stat(fsys, path.Join(projectType, file.Source)); err == nil {
+			file.dir = ""
+		}
+		files = append(files, file)
+	}
+	for _, file := range m.Files {
+		if !added[file.Target] {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func (m *Manifest) filesFor(target string) []FileSpec {
+	var files []FileSpec
+	for _, file := range m.Files {
+		if file.Target == target {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// removals returns the set of names, checking that each one is inherited
+func (m *Manifest) removals(section string, names []string, inherited map[string]bool) (map[string]bool, error) {
+	removed := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !inherited[name] {
+			return nil, fmt.Errorf("remove.%s: %s has no %s", section, m.Extends, name)
+		}
+		removed[name] = true
+	}
+	return removed, nil
+}
+
+func keysOf[T any](entries []T, key func(T) string) map[string]bool {
+	keys := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		keys[key(entry)] = true
+	}
+	return keys
+}
+
+// mergeEntries returns the entries of parent without the removed ones, with
+// those the child redeclares replaced in place, followed by the new entries
+// of child
+func mergeEntries[T any](parent, child []T, removed map[string]bool, key func(T) string) []T {
+	redeclared := make(map[string]int)
+	for i, entry := range child {
+		redeclared[key(entry)] = i
+	}
+
+	var merged []T
+	replaced := make(map[string]bool)
+	for _, entry := range parent {
+		name := key(entry)
+		if removed[name] {
+			continue
+		}
+		if i, ok := redeclared[name]; ok {
+			if !replaced[name] {
+				replaced[name] = true
+				merged = append(merged, child[i])
+			}
+			continue
+		}
+		merged = append(merged, entry)
+	}
+	for _, entry := range child {
+		if !replaced[key(entry)] {
+			merged = append(merged, entry)
+		}
+	}
+	return merged
+}
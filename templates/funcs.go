@@ -0,0 +1,177 @@
+package templates
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/go-sova/sova-cli/pkg/utils"
+)
+
+// defaultFuncs are the functions available in every template and condition
+var defaultFuncs = template.FuncMap{
+	"camel":    utils.CamelCase,
+	"pascal":   utils.PascalCase,
+	"snake":    utils.SnakeCase,
+	"kebab":    utils.KebabCase,
+	"plural":   utils.Plural,
+	"singular": utils.Singular,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"trim":     strings.TrimSpace,
+	"replace":  replace,
+	"default":  defaultValue,
+	"join":     join,
+	"indent":   indent,
+	"quote":    quote,
+	"now":      time.Now,
+	"uuid":     newUUID,
+	"env":      os.Getenv,
+}
+
+// FuncMap returns the functions available in every template. The map is a
+// copy, so callers may add their own functions to it.
+func FuncMap() template.FuncMap {
+	funcs := make(template.FuncMap, len(defaultFuncs))
+	for name, fn := range defaultFuncs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// TemplateFuncs returns base together with the functions the manifest
+// declares under funcs. Every such function is a template of its own that is
+// executed with its argument as dot, or with the list of its arguments when
+// there is not exactly one. It may call base and the other manifest
+// functions, and replaces a function of base with the same name.
+func (m *Manifest) TemplateFuncs(base template.FuncMap) (template.FuncMap, error) {
+	funcs := make(template.FuncMap, len(base)+len(m.Funcs))
+	for name, fn := range base {
+		funcs[name] = fn
+	}
+	if len(m.Funcs) == 0 {
+		return funcs, nil
+	}
+
+	// Declare every function first so that they can call each other
+	for name := range m.Funcs {
+		funcs[name] = func(args ...interface{}) (string, error) { return "", nil }
+	}
+	parsed := make(map[string]*template.Template, len(m.Funcs))
+	for name, text := range m.Funcs {
+		if !isIdentifier(name) {
+			return nil, fmt.Errorf("funcs: invalid function name %q", name)
+		}
+		tmpl, err := template.New(name).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("funcs.%s: %w", name, err)
+		}
+		parsed[name] = tmpl
+	}
+	for name, tmpl := range parsed {
+		funcs[name] = manifestFunc(tmpl)
+	}
+	for _, tmpl := range parsed {
+		tmpl.Funcs(funcs)
+	}
+	return funcs, nil
+}
+
+func manifestFunc(tmpl *template.Template) func(args ...interface{}) (string, error) {
+	return func(args ...interface{}) (string, error) {
+		var dot interface{} = args
+		if len(args) == 1 {
+			dot = args[0]
+		}
+		var buf strings.Builder
+		if err := tmpl.Execute(&buf, dot); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// replace replaces every old in s with new. Its arguments are in the order
+// that lets s come from a pipeline: {{ .Name | replace "-" "_" }}.
+func replace(old, new, s string) string {
+	return strings.ReplaceAll(s, old, new)
+}
+
+// defaultValue returns value, or def when value is missing or empty:
+// {{ .Port | default 8080 }}
+func defaultValue(def interface{}, value ...interface{}) interface{} {
+	if len(value) == 0 || isEmpty(value[0]) {
+		return def
+	}
+	return value[0]
+}
+
+func isEmpty(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// join joins the elements of list with sep: {{ .Features | join ", " }}
+func join(sep string, list interface{}) (string, error) {
+	if list == nil {
+		return "", nil
+	}
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: expected a list, got %T", list)
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// indent indents every line of s by spaces spaces
+func indent(spaces int, s string) string {
+	pad := strings.Repeat(" ", spaces)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// quote returns value as a double-quoted Go string literal
+func quote(value interface{}) string {
+	return strconv.Quote(fmt.Sprint(value))
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate uuid: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
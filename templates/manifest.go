@@ -0,0 +1,396 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the name of the manifest in every project type directory
+const ManifestFile = "template.yaml"
+
+// TestDataDir is the directory of a project type that holds its golden
+// tests. It is never rendered.
+const TestDataDir = "testdata"
+
+// Manifest declares what a project type generates
+type Manifest struct {
+	Name         string       `yaml:"name"`
+	Version      string       `yaml:"version"`
+	Description  string       `yaml:"description"`
+	Prompts      []Prompt     `yaml:"prompts"`
+	Directories  []string     `yaml:"directories"`
+	Files        []FileSpec   `yaml:"files"`
+	Dependencies []Dependency `yaml:"dependencies"`
+	Hooks        Hooks        `yaml:"hooks"`
+	// Funcs declares template functions of the project type, each a
+	// template of its own; see TemplateFuncs
+	Funcs map[string]string `yaml:"funcs"`
+	// Extends names the project type this manifest is derived from, and
+	// Remove the entries of that type it drops; see extend
+	Extends string   `yaml:"extends,omitempty"`
+	Remove  Removals `yaml:"remove,omitempty"`
+
+	// parents are the project types this one extends, the furthest first
+	parents []string
+}
+
+// FileSpec renders Source, relative to the project type directory, to Target,
+// relative to the project directory. When is an optional condition.
+type FileSpec struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+	When   string `yaml:"when"`
+
+	// dir is the project type directory Source is in, when the file is
+	// inherited from the project type it extends
+	dir string
+}
+
+// Template returns the path of the template of the file in the templates of
+// projectType, including the project type directory it is taken from
+func (f FileSpec) Template(projectType string) string {
+	if f.dir != "" {
+		return path.Join(f.dir, f.Source)
+	}
+	return path.Join(projectType, f.Source)
+}
+
+// Dependency is a Go module required by generated projects
+type Dependency struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	When    string `yaml:"when"`
+}
+
+// Hooks are steps run before and after a project is generated
+type Hooks struct {
+	PreGenerate  []HookStep `yaml:"pre-generate"`
+	PostGenerate []HookStep `yaml:"post-generate"`
+}
+
+// HookStep is a single hook. When is an optional condition.
+type HookStep struct {
+	Command string `yaml:"command"`
+	When    string `yaml:"when"`
+}
+
+// ResolvedManifest is a manifest with every condition evaluated
+type ResolvedManifest struct {
+	Directories  []string
+	Files        map[string]string
+	Dependencies []Dependency
+	Hooks        Hooks
+}
+
+// LoadManifest reads the manifest of a project type. Project types without a
+// template.yaml get a manifest that renders every file in their directory:
+// the target is the path without its .tpl suffix, and a leading "_" in a
+// file name becomes ".".
+func LoadManifest(projectType string) (*Manifest, error) {
+	return LoadManifestFS(currentFS(), projectType)
+}
+
+// LoadManifestFS reads the manifest of a project type from fsys
+func LoadManifestFS(fsys fs.FS, projectType string) (*Manifest, error) {
+	return loadManifest(fsys, projectType, nil)
+}
+
+// loadManifest reads the manifest of projectType and merges it with the
+// manifests it extends. chain lists the project types that led to it.
+func loadManifest(fsys fs.FS, projectType string, chain []string) (*Manifest, error) {
+	content, err := fs.ReadFile(fsys, path.Join(projectType, ManifestFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return conventionManifest(fsys, projectType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest for %s: %w", projectType, err)
+	}
+
+	manifest, err := ParseManifest(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest for %s: %w", projectType, err)
+	}
+	if manifest.Name == "" {
+		manifest.Name = projectType
+	}
+
+	if manifest.Extends != "" {
+		return manifest.extend(fsys, projectType, chain)
+	}
+	return manifest, nil
+}
+
+// ParseManifest decodes a template.yaml. Unknown keys are rejected.
+func ParseManifest(content []byte) (*Manifest, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	manifest := &Manifest{}
+	if err := decoder.Decode(manifest); err != nil {
+		return nil, err
+	}
+	if err := manifest.check(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func conventionManifest(fsys fs.FS, projectType string) (*Manifest, error) {
+	manifest := &Manifest{Name: projectType}
+
+	err := fs.WalkDir(fsys, projectType, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			switch strings.TrimPrefix(filePath, projectType+"/") {
+			case TestDataDir, PartialsDir, GeneratorsDir:
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		source := strings.TrimPrefix(filePath, projectType+"/")
+		if source == ManifestFile {
+			return nil
+		}
+		manifest.Files = append(manifest.Files, FileSpec{
+			Source: source,
+			Target: conventionTarget(source),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates for %s: %w", projectType, err)
+	}
+	if len(manifest.Files) == 0 {
+		return nil, fmt.Errorf("project type %s has no templates", projectType)
+	}
+
+	return manifest, nil
+}
+
+func conventionTarget(source string) string {
+	dir, name := path.Split(strings.TrimSuffix(source, ".tpl"))
+	if strings.HasPrefix(name, "_") {
+		name = "." + strings.TrimPrefix(name, "_")
+	}
+	return path.Join(dir, name)
+}
+
+// check validates the structure of a manifest without looking at the files
+// it refers to
+func (m *Manifest) check() error {
+	names := make(map[string]bool)
+	for i, prompt := range m.Prompts {
+		if err := prompt.check(); err != nil {
+			return fmt.Errorf("prompts[%d]: %w", i, err)
+		}
+		for _, name := range []string{prompt.Name, prompt.Alias} {
+			if name == "" {
+				continue
+			}
+			if names[name] {
+				return fmt.Errorf("prompts[%d]: duplicate name %s", i, name)
+			}
+			names[name] = true
+		}
+	}
+
+	targets := make(map[string][]int)
+	for i, file := range m.Files {
+		if file.Source == "" || file.Target == "" {
+			return fmt.Errorf("files[%d]: source and target are required", i)
+		}
+		if !fs.ValidPath(file.Source) || !fs.ValidPath(file.Target) {
+			return fmt.Errorf("files[%d]: paths must be relative and must not contain \"..\"", i)
+		}
+		if err := checkCondition(file.When); err != nil {
+			return fmt.Errorf("files[%d]: %w", i, err)
+		}
+		// The same target may appear several times with conditions that
+		// exclude each other; specs that always match together are an error
+		for _, j := range targets[file.Target] {
+			other := m.Files[j]
+			if other.When == "" || file.When == "" || strings.TrimSpace(other.When) == strings.TrimSpace(file.When) {
+				return fmt.Errorf("files[%d]: duplicate target %s: %s and %s are both generated whenever %s", i, file.Target, other.describe(j), file.describe(i), overlap(other.When, file.When))
+			}
+		}
+		targets[file.Target] = append(targets[file.Target], i)
+	}
+
+	if err := m.checkExtends(); err != nil {
+		return err
+	}
+
+	if _, err := m.TemplateFuncs(defaultFuncs); err != nil {
+		return err
+	}
+
+	for i, dir := range m.Directories {
+		if !fs.ValidPath(dir) {
+			return fmt.Errorf("directories[%d]: paths must be relative and must not contain \"..\"", i)
+		}
+	}
+
+	for i, dep := range m.Dependencies {
+		if dep.Name == "" || dep.Version == "" {
+			return fmt.Errorf("dependencies[%d]: name and version are required", i)
+		}
+		if err := checkCondition(dep.When); err != nil {
+			return fmt.Errorf("dependencies[%d]: %w", i, err)
+		}
+	}
+
+	for name, steps := range map[string][]HookStep{"pre-generate": m.Hooks.PreGenerate, "post-generate": m.Hooks.PostGenerate} {
+		for i, step := range steps {
+			if step.Command == "" {
+				return fmt.Errorf("hooks.%s[%d]: command is required", name, i)
+			}
+			if err := checkCondition(step.When); err != nil {
+				return fmt.Errorf("hooks.%s[%d]: %w", name, i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that every source file of the manifest exists for projectType
+func (m *Manifest) Validate(fsys fs.FS, projectType string) error {
+	if err := m.check(); err != nil {
+		return err
+	}
+	for _, file := range m.Files {
+		if _, err := fs.Stat(fsys, file.Template(projectType)); err != nil {
+			return fmt.Errorf("template %s for %s not found", file.Source, file.Target)
+		}
+	}
+	return nil
+}
+
+// describe names the file spec at index i of the files of a manifest
+func (f FileSpec) describe(i int) string {
+	if f.When == "" {
+		return fmt.Sprintf("files[%d] (source %s)", i, f.Source)
+	}
+	return fmt.Sprintf("files[%d] (source %s, when %s)", i, f.Source, f.When)
+}
+
+func overlap(a, b string) string {
+	switch {
+	case a == "" && b == "":
+		return "the project is generated"
+	case a == "":
+		return b + " is true"
+	}
+	return a + " is true"
+}
+
+// Resolve evaluates every condition of the manifest against data. The
+// returned files map targets to template names that include the project
+// type, ready to be passed to TemplateLoader.LoadTemplate. Two files with
+// the same target whose conditions both hold are an error.
+func (m *Manifest) Resolve(projectType string, data interface{}) (*ResolvedManifest, error) {
+	resolved := &ResolvedManifest{
+		Directories: append([]string(nil), m.Directories...),
+		Files:       make(map[string]string),
+	}
+
+	matched := make(map[string]int)
+	for i, file := range m.Files {
+		ok, err := EvalCondition(file.When, data)
+		if err != nil {
+			return nil, fmt.Errorf("file %s: %w", file.Target, err)
+		}
+		if !ok {
+			continue
+		}
+		if j, ok := matched[file.Target]; ok {
+			return nil, fmt.Errorf("file %s: both %s and %s match", file.Target, m.Files[j].describe(j), file.describe(i))
+		}
+		matched[file.Target] = i
+		resolved.Files[file.Target] = file.Template(projectType)
+	}
+
+	for _, dep := range m.Dependencies {
+		ok, err := EvalCondition(dep.When, data)
+		if err != nil {
+			return nil, fmt.Errorf("dependency %s: %w", dep.Name, err)
+		}
+		if ok {
+			resolved.Dependencies = append(resolved.Dependencies, dep)
+		}
+	}
+	sort.SliceStable(resolved.Dependencies, func(i, j int) bool {
+		return resolved.Dependencies[i].Name < resolved.Dependencies[j].Name
+	})
+
+	for _, hooks := range []struct {
+		in  []HookStep
+		out *[]HookStep
+	}{
+		{m.Hooks.PreGenerate, &resolved.Hooks.PreGenerate},
+		{m.Hooks.PostGenerate, &resolved.Hooks.PostGenerate},
+	} {
+		for _, step := range hooks.in {
+			ok, err := EvalCondition(step.When, data)
+			if err != nil {
+				return nil, fmt.Errorf("hook %q: %w", step.Command, err)
+			}
+			if ok {
+				*hooks.out = append(*hooks.out, step)
+			}
+		}
+	}
+
+	return resolved, nil
+}
+
+// EvalCondition evaluates a manifest condition. Conditions are text/template
+// pipelines such as ".UsePostgres" or "and .UseZap (not .UseRedis)" that may
+// use the functions of FuncMap; an empty condition is always true.
+func EvalCondition(condition string, data interface{}) (bool, error) {
+	if strings.TrimSpace(condition) == "" {
+		return true, nil
+	}
+
+	tmpl, err := parseCondition(condition)
+	if err != nil {
+		return false, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return false, fmt.Errorf("failed to evaluate condition %q: %w", condition, err)
+	}
+	return buf.String() == "true", nil
+}
+
+func checkCondition(condition string) error {
+	if strings.TrimSpace(condition) == "" {
+		return nil
+	}
+	_, err := parseCondition(condition)
+	return err
+}
+
+func parseCondition(condition string) (*template.Template, error) {
+	if strings.Contains(condition, "{{") || strings.Contains(condition, "}}") {
+		return nil, fmt.Errorf("invalid condition %q: write the pipeline without {{ }}", condition)
+	}
+
+	tmpl, err := template.New("when").Funcs(defaultFuncs).Parse("{{if " + condition + "}}true{{end}}")
+	if err != nil {
+		return nil, fmt.Errorf("invalid condition %q: %w", condition, err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,297 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// OverlayFS stacks several filesystems on top of each other. A file in an
+// earlier layer hides the file with the same path in later layers, and
+// directory listings are merged across all layers.
+type OverlayFS struct {
+	layers []fs.FS
+}
+
+// NewOverlayFS creates an overlay in which layers[0] takes precedence.
+func NewOverlayFS(layers ...fs.FS) *OverlayFS {
+	return &OverlayFS{layers: layers}
+}
+
+// Open opens the named file from the first layer that has it
+func (o *OverlayFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	for _, layer := range o.layers {
+		file, err := layer.Open(name)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir merges the directory listings of every layer that has the directory
+func (o *OverlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	seen := make(map[string]bool)
+	var entries []fs.DirEntry
+	found := false
+
+	for _, layer := range o.layers {
+		layerEntries, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		found = true
+		for _, entry := range layerEntries {
+			if seen[entry.Name()] {
+				continue
+			}
+			seen[entry.Name()] = true
+			entries = append(entries, entry)
+		}
+	}
+
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+var (
+	sourceMu     sync.RWMutex
+	templateDirs []string
+	sourceFS     fs.FS = TemplateFS
+)
+
+// SetTemplateDirs overlays the given directories on the embedded templates
+// for every loader created afterwards. Directories listed first take
+// precedence; directories that do not exist are ignored.
+func SetTemplateDirs(dirs []string) {
+	var layers []fs.FS
+	var used []string
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		layers = append(layers, os.DirFS(dir))
+		used = append(used, dir)
+	}
+
+	sourceMu.Lock()
+	defer sourceMu.Unlock()
+
+	templateDirs = used
+	if len(layers) == 0 {
+		sourceFS = TemplateFS
+		return
+	}
+	sourceFS = NewOverlayFS(append(layers, TemplateFS)...)
+}
+
+// TemplateDirs returns the on-disk template directories currently in use
+func TemplateDirs() []string {
+	sourceMu.RLock()
+	defer sourceMu.RUnlock()
+	return append([]string(nil), templateDirs...)
+}
+
+// SaveTemplateSources returns a function that restores the template
+// directories and sources as they are now, undoing the SetTemplateDirs and
+// AddTemplateSource calls made in between
+func SaveTemplateSources() (restore func()) {
+	sourceMu.RLock()
+	dirs, fsys := templateDirs, sourceFS
+	sourceMu.RUnlock()
+
+	return func() {
+		sourceMu.Lock()
+		defer sourceMu.Unlock()
+		templateDirs, sourceFS = dirs, fsys
+	}
+}
+
+// TemplateDir returns the template directory that provides projectType, or
+// false when it only comes from the embedded templates
+func TemplateDir(projectType string) (string, bool) {
+	for _, dir := range TemplateDirs() {
+		if info, err := os.Stat(filepath.Join(dir, projectType)); err == nil && info.IsDir() {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+func currentFS() fs.FS {
+	sourceMu.RLock()
+	defer sourceMu.RUnlock()
+	return sourceFS
+}
+
+// ProjectTypes returns every project type available from the embedded
+// templates and the configured template directories. Each top-level
+// directory is a project type; names starting with "." or "_" are skipped.
+func ProjectTypes() ([]string, error) {
+	entries, err := fs.ReadDir(currentFS(), ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var types []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+			continue
+		}
+		types = append(types, name)
+	}
+	return types, nil
+}
+
+// TemplateFiles returns the paths of all templates of a project type,
+// relative to the project type directory. The manifest is not included.
+func TemplateFiles(projectType string) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(currentFS(), projectType, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path == projectType+"/"+TestDataDir {
+			return fs.SkipDir
+		}
+		if !d.IsDir() && path != projectType+"/"+ManifestFile {
+			files = append(files, strings.TrimPrefix(path, projectType+"/"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// AddTemplateSource layers a template fetched from a remote source or a
+// directory on top of the current templates and returns the project types
+// it provides. A directory with a template.yaml at its root is a single
+// project type, named after the manifest or fallbackName; any other
+// directory is treated like a template directory with one project type per
+// subdirectory.
+func AddTemplateSource(dir, fallbackName string) ([]string, error) {
+	dirFS := os.DirFS(dir)
+
+	var layer fs.FS
+	var types []string
+	if content, err := fs.ReadFile(dirFS, ManifestFile); err == nil {
+		manifest, err := ParseManifest(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid manifest in %s: %w", dir, err)
+		}
+		name := manifest.Name
+		if !IsProjectTypeName(name) {
+			name = fallbackName
+		}
+		if !IsProjectTypeName(name) {
+			return nil, fmt.Errorf("template in %s needs a name in %s", dir, ManifestFile)
+		}
+		layer = MountFS(name, dirFS)
+		types = []string{name}
+	} else {
+		layer = dirFS
+		entries, err := fs.ReadDir(dirFS, ".")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read templates in %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && IsProjectTypeName(entry.Name()) {
+				types = append(types, entry.Name())
+			}
+		}
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("no templates found in %s", dir)
+	}
+
+	sourceMu.Lock()
+	defer sourceMu.Unlock()
+	sourceFS = NewOverlayFS(layer, sourceFS)
+
+	return types, nil
+}
+
+// IsProjectTypeName reports whether name can be used as a project type, i.e.
+// as a top-level template directory
+func IsProjectTypeName(name string) bool {
+	return name != "" && fs.ValidPath(name) && !strings.Contains(name, "/") &&
+		!strings.HasPrefix(name, ".") && !strings.HasPrefix(name, "_")
+}
+
+// MountFS presents fsys as the directory name of an otherwise empty
+// filesystem. It only lists its root through ReadDir, which is enough to
+// be used as a layer of an OverlayFS.
+func MountFS(name string, fsys fs.FS) fs.FS {
+	return &mountFS{name: name, fsys: fsys}
+}
+
+type mountFS struct {
+	name string
+	fsys fs.FS
+}
+
+func (m *mountFS) Open(name string) (fs.File, error) {
+	rel, ok := m.rel(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return m.fsys.Open(rel)
+}
+
+func (m *mountFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == "." {
+		info, err := fs.Stat(m.fsys, ".")
+		if err != nil {
+			return nil, err
+		}
+		return []fs.DirEntry{mountEntry{fs.FileInfoToDirEntry(info), m.name}}, nil
+	}
+
+	rel, ok := m.rel(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return fs.ReadDir(m.fsys, rel)
+}
+
+// rel maps a path below the mount point to a path in the mounted filesystem
+func (m *mountFS) rel(name string) (string, bool) {
+	if name == m.name {
+		return ".", true
+	}
+	if strings.HasPrefix(name, m.name+"/") {
+		return strings.TrimPrefix(name, m.name+"/"), true
+	}
+	return "", false
+}
+
+type mountEntry struct {
+	fs.DirEntry
+	name string
+}
+
+func (e mountEntry) Name() string { return e.name }
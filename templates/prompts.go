@@ -0,0 +1,188 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Prompt types supported in template manifests
+const (
+	PromptInput       = "input"
+	PromptConfirm     = "confirm"
+	PromptSelect      = "select"
+	PromptMultiSelect = "multiselect"
+)
+
+var (
+	promptNamePattern  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	promptAliasPattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+)
+
+// Prompt is a question a template asks before generating a project. The
+// answer is available to templates and conditions as .<Name>.
+type Prompt struct {
+	Name    string      `yaml:"name"`
+	Type    string      `yaml:"type"`
+	Message string      `yaml:"message"`
+	Help    string      `yaml:"help"`
+	Default interface{} `yaml:"default"`
+	Options []string    `yaml:"options"`
+	// Validate is a regular expression input answers must match
+	Validate string `yaml:"validate"`
+	// When is a condition on earlier answers; the prompt is skipped and
+	// answered with its zero value when it is false
+	When string `yaml:"when"`
+	// Alias is a short name for the prompt on the command line, e.g.
+	// --with postgres for a confirm prompt named UsePostgres
+	Alias string `yaml:"alias"`
+}
+
+func (p Prompt) check() error {
+	if !promptNamePattern.MatchString(p.Name) {
+		return fmt.Errorf("name %q must start with a letter and contain only letters, digits and underscores", p.Name)
+	}
+	if p.Alias != "" && !promptAliasPattern.MatchString(p.Alias) {
+		return fmt.Errorf("%s: alias %q must be lowercase letters, digits and dashes", p.Name, p.Alias)
+	}
+	if err := checkCondition(p.When); err != nil {
+		return fmt.Errorf("%s: %w", p.Name, err)
+	}
+
+	switch p.Type {
+	case PromptInput:
+		if p.Validate != "" {
+			if _, err := regexp.Compile(p.Validate); err != nil {
+				return fmt.Errorf("%s: invalid validate pattern: %w", p.Name, err)
+			}
+		}
+	case PromptConfirm:
+	case PromptSelect, PromptMultiSelect:
+		if len(p.Options) == 0 {
+			return fmt.Errorf("%s: %s prompts need options", p.Name, p.Type)
+		}
+	default:
+		return fmt.Errorf("%s: unknown type %q (expected input, confirm, select or multiselect)", p.Name, p.Type)
+	}
+
+	if p.Default != nil {
+		if _, err := p.Coerce(p.Default); err != nil {
+			return fmt.Errorf("%s: invalid default: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// DefaultValue returns the prompt's default, or the zero value of its type
+func (p Prompt) DefaultValue() interface{} {
+	if p.Default != nil {
+		if value, err := p.Coerce(p.Default); err == nil {
+			return value
+		}
+	}
+	return p.ZeroValue()
+}
+
+// ZeroValue returns the answer used for prompts that are skipped
+func (p Prompt) ZeroValue() interface{} {
+	switch p.Type {
+	case PromptConfirm:
+		return false
+	case PromptMultiSelect:
+		return []string{}
+	default:
+		return ""
+	}
+}
+
+// Coerce converts a value from a flag, answers file or default into the
+// type of the prompt (string, bool or []string) and validates it.
+func (p Prompt) Coerce(value interface{}) (interface{}, error) {
+	switch p.Type {
+	case PromptConfirm:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "y", "yes":
+				return true, nil
+			case "n", "no":
+				return false, nil
+			}
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a boolean", v)
+			}
+			return b, nil
+		}
+		return nil, fmt.Errorf("%v is not a boolean", value)
+
+	case PromptMultiSelect:
+		var items []string
+		switch v := value.(type) {
+		case string:
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		case []string:
+			items = v
+		case []interface{}:
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+		default:
+			return nil, fmt.Errorf("%v is not a list", value)
+		}
+		if items == nil {
+			items = []string{}
+		}
+		for _, item := range items {
+			if !containsString(p.Options, item) {
+				return nil, fmt.Errorf("%q is not one of %s", item, strings.Join(p.Options, ", "))
+			}
+		}
+		return items, nil
+
+	case PromptSelect:
+		s := fmt.Sprint(value)
+		if !containsString(p.Options, s) {
+			return nil, fmt.Errorf("%q is not one of %s", s, strings.Join(p.Options, ", "))
+		}
+		return s, nil
+
+	default:
+		s := fmt.Sprint(value)
+		if err := p.ValidateInput(s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+}
+
+// ValidateInput checks an input answer against the prompt's pattern
+func (p Prompt) ValidateInput(value string) error {
+	if p.Validate == "" {
+		return nil
+	}
+	re, err := regexp.Compile(p.Validate)
+	if err != nil {
+		return err
+	}
+	if !re.MatchString(value) {
+		return fmt.Errorf("%q does not match %s", value, p.Validate)
+	}
+	return nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,29 +1,60 @@
 package templates
 
 import (
+	"bytes"
 	"embed"
+	"errors"
 	"fmt"
 	"io/fs"
-	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"text/template"
 
+	"github.com/go-sova/sova-cli/internal/gosource"
 	"github.com/go-sova/sova-cli/pkg/utils"
 )
 
-//go:embed cli/* api/*
+//go:embed cli/* api/* _partials/*
 var TemplateFS embed.FS
 
+// PartialsDir holds templates that are not rendered themselves but parsed
+// into every template, so that their {{define}} blocks can be used with
+// {{template}}. The top-level PartialsDir is shared by every project type;
+// the PartialsDir of a project type is parsed after it, and a template
+// itself last, so each can redefine the blocks of the ones before.
+const PartialsDir = "_partials"
+
+// GeneratorsDir is the directory of a project type that holds the templates
+// sova generate adds to existing projects of that type. They are not
+// rendered when a project is generated.
+const GeneratorsDir = "_generators"
+
 // TemplateLoader handles loading templates from the embedded filesystem
 type TemplateLoader struct {
 	fs     fs.FS
+	funcs  template.FuncMap
 	logger *utils.Logger
+	// partialDirs overrides the partials parsed into every template; see
+	// SetPartialDirs
+	partialDirs []string
 }
 
-// NewTemplateLoader creates a new template loader
+// NewTemplateLoader creates a new template loader that reads the embedded
+// templates overlaid with any directories set through SetTemplateDirs
 func NewTemplateLoader() *TemplateLoader {
 	return &TemplateLoader{
-		fs:     TemplateFS,
+		fs:     currentFS(),
+		funcs:  FuncMap(),
+		logger: utils.NewLoggerWithPrefix(utils.Info, "TemplateLoader"),
+	}
+}
+
+// NewTemplateLoaderFS creates a template loader that reads from fsys
+func NewTemplateLoaderFS(fsys fs.FS) *TemplateLoader {
+	return &TemplateLoader{
+		fs:     fsys,
+		funcs:  FuncMap(),
 		logger: utils.NewLoggerWithPrefix(utils.Info, "TemplateLoader"),
 	}
 }
@@ -32,17 +63,29 @@ func (l *TemplateLoader) SetLogger(logger *utils.Logger) {
 	l.logger = logger
 }
 
+// Funcs adds funcs to the functions of every template the loader parses,
+// replacing those with the same name. Templates start with FuncMap.
+func (l *TemplateLoader) Funcs(funcs template.FuncMap) *TemplateLoader {
+	for name, fn := range funcs {
+		l.funcs[name] = fn
+	}
+	return l
+}
+
+// SetPartialDirs sets the directories whose partials are parsed into every
+// template, in order. By default they are PartialsDir and the PartialsDir
+// of the project type of the template; see Manifest.PartialDirs.
+func (l *TemplateLoader) SetPartialDirs(dirs []string) {
+	l.partialDirs = dirs
+}
+
 // LoadTemplate loads a template by name from the embedded filesystem
 func (l *TemplateLoader) LoadTemplate(name string) (*template.Template, error) {
 	// If the template name already includes a category prefix (e.g. "api/env.tpl"),
 	// try loading it directly
 	content, err := fs.ReadFile(l.fs, name)
 	if err == nil {
-		tmpl, err := template.New(filepath.Base(name)).Parse(string(content))
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
-		}
-		return tmpl, nil
+		return l.parse(name, content)
 	}
 
 	// If direct loading fails, try each category as a fallback
@@ -64,14 +107,58 @@ func (l *TemplateLoader) LoadTemplateFromCategory(category, name string) (*templ
 		return nil, fmt.Errorf("failed to read template %s: %w", templatePath, err)
 	}
 
-	tmpl, err := template.New(filepath.Base(name)).Parse(string(content))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse template %s: %w", templatePath, err)
+	return l.parse(filepath.ToSlash(templatePath), content)
+}
+
+// parse parses the template name, with the partials of its project type
+func (l *TemplateLoader) parse(name string, content []byte) (*template.Template, error) {
+	tmpl := template.New(path.Base(name)).Funcs(l.funcs)
+
+	dirs := l.partialDirs
+	if dirs == nil {
+		dirs = []string{PartialsDir}
+		if projectType, _, ok := strings.Cut(name, "/"); ok && projectType != PartialsDir {
+			dirs = append(dirs, path.Join(projectType, PartialsDir))
+		}
+	}
+	for _, dir := range dirs {
+		if err := l.parsePartials(tmpl, dir); err != nil {
+			return nil, err
+		}
 	}
 
+	if _, err := tmpl.Parse(string(content)); err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
 	return tmpl, nil
 }
 
+// parsePartials adds every .tpl file of dir to tmpl, in name order
+func (l *TemplateLoader) parsePartials(tmpl *template.Template, dir string) error {
+	entries, err := fs.ReadDir(l.fs, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read partials in %s: %w", dir, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tpl") {
+			continue
+		}
+		partial := path.Join(dir, entry.Name())
+		content, err := fs.ReadFile(l.fs, partial)
+		if err != nil {
+			return fmt.Errorf("failed to read partial %s: %w", partial, err)
+		}
+		if _, err := tmpl.New(partial).Parse(string(content)); err != nil {
+			return fmt.Errorf("failed to parse partial %s: %w", partial, err)
+		}
+	}
+	return nil
+}
+
 // FileGenerator handles generating files from templates
 type FileGenerator struct {
 	loader *TemplateLoader
@@ -90,40 +177,47 @@ func (g *FileGenerator) SetLogger(logger *utils.Logger) {
 	g.logger = logger
 }
 
-// GenerateFile generates a file from a template
-func (g *FileGenerator) GenerateFile(templateName, outputPath string, data interface{}) error {
-	g.logger.Debug("Generating file %s from template %s", outputPath, templateName)
-
-	// Create the directory if it doesn't exist
-	dir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", dir, err)
-	}
-
-	// Load the template
+// Render executes a template and returns the output without writing it anywhere
+func (g *FileGenerator) Render(templateName string, data interface{}) ([]byte, error) {
 	tmpl, err := g.loader.LoadTemplate(templateName)
 	if err != nil {
-		return fmt.Errorf("failed to load template %s: %w", templateName, err)
+		return nil, fmt.Errorf("failed to load template %s: %w", templateName, err)
 	}
 
-	// Create the output file
-	file, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", outputPath, err)
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to execute template %s: %w", templateName, err)
 	}
-	defer file.Close()
 
-	// Execute the template
-	if err := tmpl.Execute(file, data); err != nil {
-		return fmt.Errorf("failed to execute template %s: %w", templateName, err)
+	return buf.Bytes(), nil
+}
+
+// RenderFile renders the template for the file at targetPath. Go files are
+// formatted and have their imports fixed, so a template does not need to get
+// blank lines or import groups exactly right for every combination of
+// answers. A Go file that does not parse is reported with the template name
+// and the offending line.
+func (g *FileGenerator) RenderFile(templateName, targetPath string, data interface{}) ([]byte, error) {
+	content, err := g.Render(templateName, data)
+	if err != nil || !strings.HasSuffix(targetPath, ".go") {
+		return content, err
 	}
 
-	return nil
+	modulePath := ""
+	if values, ok := data.(map[string]interface{}); ok {
+		modulePath, _ = values["ModuleName"].(string)
+	}
+	formatted, err := gosource.Format(content, modulePath)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: generated %s does not parse: %w", templateName, filepath.ToSlash(targetPath), err)
+	}
+	return formatted, nil
 }
 
-// GetTemplateFS returns the embedded filesystem containing all templates
+// GetTemplateFS returns the filesystem containing all templates: the embedded
+// set overlaid with any configured template directories
 func GetTemplateFS() fs.FS {
-	return TemplateFS
+	return currentFS()
 }
 
 // GetTemplatePath returns the path to a specific template within the embedded filesystem
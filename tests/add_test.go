@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/pkg/questions"
+)
+
+func TestDetectProject(t *testing.T) {
+	testCases := []struct {
+		name        string
+		projectType string
+		values      map[string]interface{}
+	}{
+		{
+			name:        "API project",
+			projectType: "api",
+			values:      map[string]interface{}{"UseZap": true, "UsePostgres": false, "UseRedis": true, "UseRabbitMQ": false},
+		},
+		{
+			name:        "API project without components",
+			projectType: "api",
+			values:      map[string]interface{}{"UseZap": false, "UsePostgres": false, "UseRedis": false, "UseRabbitMQ": false},
+		},
+		{
+			name:        "CLI project",
+			projectType: "cli",
+			values:      map[string]interface{}{"UseZap": false},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			answers := &questions.ProjectAnswers{ProjectType: tc.projectType, ModulePath: "example.com/shop", Values: tc.values}
+			plan := planProject(t, "shop", answers)
+			projectDir := filepath.Join(t.TempDir(), "shop")
+			if err := plan.Write(context.Background(), projectDir); err != nil {
+				t.Fatalf("Failed to write project: %v", err)
+			}
+
+			detected, err := project.DetectProject(projectDir)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if detected.ProjectType != tc.projectType || detected.ProjectName != "shop" || detected.ModulePath != "example.com/shop" {
+				t.Errorf("Detected %s project %s (%s)", detected.ProjectType, detected.ProjectName, detected.ModulePath)
+			}
+			for name, want := range tc.values {
+				if detected.Values[name] != want {
+					t.Errorf("Expected %s=%v, got %v", name, want, detected.Values[name])
+				}
+			}
+		})
+	}
+
+	t.Run("Not a sova project", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplate(t, dir, "go.mod", "module example.com/other\n\ngo 1.21\n")
+		writeTemplate(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+		if _, err := project.DetectProject(dir); err == nil {
+			t.Error("Expected error but got none")
+		}
+	})
+}
+
+func TestPlanUpdate(t *testing.T) {
+	newPlan := func(files map[string]string) *project.Plan {
+		plan := &project.Plan{ProjectName: "demo"}
+		for name, content := range files {
+			plan.AddFile(name, name+".tpl", []byte(content))
+		}
+		return plan
+	}
+
+	base := newPlan(map[string]string{
+		"main.go":  "package main\n",
+		"config":   "port=8080\n",
+		".env":     "DEBUG=true\n",
+		"kept.txt": "unchanged\n",
+		"go.mod":   "module example.com/demo\n\ngo 1.21\n\nrequire github.com/gin-gonic/gin v1.9.1\n",
+	})
+	next := newPlan(map[string]string{
+		"main.go":     "package main\n",
+		"config":      "port=8080\ndb=postgres\n",
+		".env":        "DEBUG=true\nDB_HOST=localhost\n",
+		"kept.txt":    "unchanged\n",
+		"db/postgres": "postgres\n",
+		"gone.txt":    "created\n",
+		"go.mod":      "module example.com/demo\n\ngo 1.21\n\nrequire (\n\tgithub.com/gin-gonic/gin v1.9.1\n\tgithub.com/lib/pq v1.10.9\n)\n",
+	})
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"main.go": "package main\n\n// edited, but not affected by the update\n",
+		"config":  "port=8080\n",
+		".env":    "DEBUG=false\n",
+		"go.mod":  "module example.com/demo\n\ngo 1.21\n\nrequire github.com/gin-gonic/gin v1.9.1\n\nrequire golang.org/x/net v0.17.0 // indirect\n",
+	} {
+		writeTemplate(t, dir, name, content)
+	}
+
+	changes, err := next.Update(context.Background(), dir, base)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := make(map[string]project.FileAction)
+	for _, change := range changes {
+		got[change.Path] = change.Action
+	}
+	want := map[string]project.FileAction{
+		"config":      project.FileUpdated,
+		".env":        project.FileConflict,
+		"db/postgres": project.FileCreated,
+		"gone.txt":    project.FileCreated,
+		"go.mod":      project.FileUpdated,
+	}
+	if len(got) != len(want) {
+		t.Errorf("Expected changes %v, got %v", want, got)
+	}
+	for name, action := range want {
+		if got[name] != action {
+			t.Errorf("Expected %s to be %s, got %q", name, action, got[name])
+		}
+	}
+
+	testCases := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{name: "Unaffected edited file", file: "main.go", want: []string{"// edited"}},
+		{name: "Unedited file", file: "config", want: []string{"db=postgres"}},
+		{name: "Edited file", file: ".env", want: []string{"DEBUG=false"}},
+		{name: "New version of edited file", file: ".env" + project.NewFileSuffix, want: []string{"DB_HOST=localhost"}},
+		{name: "Merged go.mod", file: "go.mod", want: []string{"github.com/lib/pq v1.10.9", "golang.org/x/net v0.17.0 // indirect"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join(dir, tc.file))
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", tc.file, err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("Expected %s to contain %q, got:\n%s", tc.file, want, content)
+				}
+			}
+		})
+	}
+}
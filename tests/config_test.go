@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/config"
+	"github.com/spf13/viper"
+)
+
+func TestConfigValidateKey(t *testing.T) {
+	testCases := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "Default", key: config.KeyAuthor},
+		{name: "Other case", key: "defaults.goversion"},
+		{name: "Template directory", key: config.KeyTemplateDirectory},
+		{name: "Unknown key", key: "bogus.key", wantErr: true},
+		{name: "Section", key: "defaults", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := config.ValidateKey(tc.key)
+			if tc.wantErr {
+				if err == nil || !strings.Contains(err.Error(), config.KeyTemplateDirectory) {
+					t.Errorf("Expected an error listing the valid keys, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestConfigSetValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".sova.yaml")
+	initial := "# team settings\ndefaults:\n  license: MIT # house license\n"
+	if err := os.WriteFile(path, []byte(initial), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	if err := config.SetValue(path, config.KeyAuthor, "Jane Doe"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := config.SetValue(path, "templates.directory", "~/.sova/templates"); err != nil {
+		t.Fatalf("Failed to set nested value: %v", err)
+	}
+	if err := config.SetValue(path, "defaults", "x"); err == nil {
+		t.Error("Expected error when overwriting a section but got none")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read config file: %v", err)
+	}
+	for _, want := range []string{"# team settings", "license: MIT # house license", "author: Jane Doe", "directory: ~/.sova/templates"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Config file does not contain %q:\n%s", want, content)
+		}
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("Failed to read config with viper: %v", err)
+	}
+	config.BindEnv(v)
+	t.Setenv("SOVA_DEFAULT_LICENSE", "Apache-2.0")
+
+	defaults := config.GetDefaults(v)
+	if defaults.Author != "Jane Doe" {
+		t.Errorf("Author mismatch. Want %q, got %q", "Jane Doe", defaults.Author)
+	}
+	if defaults.License != "Apache-2.0" {
+		t.Errorf("License mismatch. Want %q, got %q", "Apache-2.0", defaults.License)
+	}
+	if defaults.GoVersion != "1.21" {
+		t.Errorf("GoVersion mismatch. Want %q, got %q", "1.21", defaults.GoVersion)
+	}
+}
+
+func TestConfigTemplateDirs(t *testing.T) {
+	separator := string(filepath.ListSeparator)
+	testCases := []struct {
+		name     string
+		set      string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "Path with a space",
+			set:      "/path/with space/templates",
+			expected: []string{"/path/with space/templates"},
+		},
+		{
+			name:     "Path list",
+			set:      "/team templates" + separator + "~/.sova/templates",
+			expected: []string{"/team templates", "~/.sova/templates"},
+		},
+		{
+			name:     "YAML list",
+			content:  "templates:\n  directory:\n    - /team templates\n    - ~/.sova/templates\n",
+			expected: []string{"/team templates", "~/.sova/templates"},
+		},
+		{
+			name:    "Not set",
+			content: "defaults:\n  author: Jane Doe\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".sova.yaml")
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("Failed to write config file: %v", err)
+			}
+			if tc.set != "" {
+				if err := config.SetValue(path, config.KeyTemplateDirectory, tc.set); err != nil {
+					t.Fatalf("Failed to set value: %v", err)
+				}
+			}
+
+			v := viper.New()
+			v.SetConfigFile(path)
+			if err := v.ReadInConfig(); err != nil {
+				t.Fatalf("Failed to read config with viper: %v", err)
+			}
+			dirs := config.TemplateDirs(v)
+			if !reflect.DeepEqual(dirs, tc.expected) {
+				t.Errorf("Expected directories %q, got %q", tc.expected, dirs)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/conflict"
+	"github.com/go-sova/sova-cli/internal/project"
+)
+
+func TestParseConflictPolicy(t *testing.T) {
+	for _, policy := range conflict.Policies {
+		got, err := conflict.ParsePolicy(string(policy))
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		if got != policy {
+			t.Errorf("Expected %s, got %s", policy, got)
+		}
+	}
+	if _, err := conflict.ParsePolicy("force"); err == nil {
+		t.Error("Expected error but got none")
+	}
+}
+
+func TestConflictResolverWriteFile(t *testing.T) {
+	const current, next = "package main\n\n// edited\n", "package main\n"
+
+	testCases := []struct {
+		name        string
+		resolver    *conflict.Resolver
+		oldBackup   bool
+		want        string
+		decision    conflict.Policy
+		backup      string
+		expectError bool
+	}{
+		{name: "Nil resolver skips", resolver: nil, want: current, decision: conflict.Skip},
+		{name: "Skip", resolver: conflict.NewResolver(conflict.Skip, nil), want: current, decision: conflict.Skip},
+		{name: "Overwrite", resolver: conflict.NewResolver(conflict.Overwrite, nil), want: next, decision: conflict.Overwrite},
+		{name: "Backup", resolver: conflict.NewResolver(conflict.Backup, nil), want: next, decision: conflict.Backup, backup: "main.go.bak"},
+		{name: "Backup keeps earlier backups", resolver: conflict.NewResolver(conflict.Backup, nil), oldBackup: true, want: next, decision: conflict.Backup, backup: "main.go.bak.1"},
+		{
+			name: "Prompt",
+			resolver: conflict.NewResolver(conflict.Prompt, func(path string, got, want []byte) (conflict.Policy, error) {
+				if string(got) != current || string(want) != next {
+					t.Errorf("Expected to be asked about %q and %q, got %q and %q", current, next, got, want)
+				}
+				return conflict.Backup, nil
+			}),
+			want:     next,
+			decision: conflict.Backup,
+			backup:   "main.go.bak",
+		},
+		{name: "Prompt without a way to ask", resolver: conflict.NewResolver(conflict.Prompt, nil), want: current, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "main.go")
+			writeTemplate(t, dir, "main.go", current)
+			if tc.oldBackup {
+				writeTemplate(t, dir, "main.go.bak", "older\n")
+			}
+
+			result, err := tc.resolver.WriteFile(path, []byte(next))
+			if tc.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			if string(content) != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, content)
+			}
+			if result.Decision != tc.decision {
+				t.Errorf("Expected decision %q, got %q", tc.decision, result.Decision)
+			}
+
+			if tc.backup == "" {
+				if result.Backup != "" {
+					t.Errorf("Expected no backup, got %s", result.Backup)
+				}
+				return
+			}
+			if result.Backup != filepath.Join(dir, tc.backup) {
+				t.Errorf("Expected backup %s, got %s", filepath.Join(dir, tc.backup), result.Backup)
+			}
+			saved, err := os.ReadFile(result.Backup)
+			if err != nil {
+				t.Fatalf("Failed to read backup: %v", err)
+			}
+			if string(saved) != current {
+				t.Errorf("Expected backup %q, got %q", current, saved)
+			}
+		})
+	}
+
+	t.Run("New and unchanged files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplate(t, dir, "same.go", next)
+		resolver := conflict.NewResolver(conflict.Prompt, func(path string, current, next []byte) (conflict.Policy, error) {
+			t.Errorf("Unexpected question about %s", path)
+			return conflict.Skip, nil
+		})
+
+		for _, name := range []string{"same.go", "sub/new.go"} {
+			result, err := resolver.WriteFile(filepath.Join(dir, name), []byte(next))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Decision != "" {
+				t.Errorf("Expected no decision for %s, got %q", name, result.Decision)
+			}
+		}
+		if content, _ := os.ReadFile(filepath.Join(dir, "sub", "new.go")); string(content) != next {
+			t.Errorf("Expected %q, got %q", next, content)
+		}
+		if len(resolver.Results) != 0 {
+			t.Errorf("Expected no results, got %v", resolver.Results)
+		}
+	})
+}
+
+func TestPlanGenerateIntoExistingDirectory(t *testing.T) {
+	plan := &project.Plan{ProjectName: "demo", Lock: &project.Lock{Template: project.LockTemplate{Name: "demo", Version: "1.0.0"}, Files: map[string]string{}}}
+	plan.AddFile("README.md", "README.md.tpl", []byte("# demo\n"))
+	plan.AddFile("main.go", "main.go.tpl", []byte("package main\n"))
+
+	testCases := []struct {
+		name     string
+		policy   conflict.Policy
+		readme   string
+		modified bool
+	}{
+		{name: "Skip", policy: conflict.Skip, readme: "# my notes\n", modified: true},
+		{name: "Overwrite", policy: conflict.Overwrite, readme: "# demo\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTemplate(t, dir, "README.md", "# my notes\n")
+			writeTemplate(t, dir, "notes.txt", "not generated\n")
+
+			plan.OnConflict = conflict.NewResolver(tc.policy, nil)
+			if err := plan.Generate(context.Background(), dir, nil); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for name, want := range map[string]string{"README.md": tc.readme, "main.go": "package main\n", "notes.txt": "not generated\n"} {
+				content, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatalf("Failed to read %s: %v", name, err)
+				}
+				if string(content) != want {
+					t.Errorf("Expected %s to be %q, got %q", name, want, content)
+				}
+			}
+
+			lock, err := project.ReadLock(dir)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			statuses, err := lock.Check(dir)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var want []project.FileStatus
+			if tc.modified {
+				want = []project.FileStatus{{Path: "README.md", State: project.FileModified}}
+			}
+			if !reflect.DeepEqual(statuses, want) {
+				t.Errorf("Expected %v, got %v", want, statuses)
+			}
+		})
+	}
+
+	t.Run("Without a resolver", func(t *testing.T) {
+		dir := t.TempDir()
+		plan.OnConflict = nil
+		if err := plan.Generate(context.Background(), dir, nil); err == nil {
+			t.Error("Expected error but got none")
+		}
+	})
+}
+
+func TestPlanUpdateOnConflict(t *testing.T) {
+	base := &project.Plan{ProjectName: "demo"}
+	base.AddFile(".env", ".env.tpl", []byte("DEBUG=true\n"))
+	next := &project.Plan{ProjectName: "demo"}
+	next.AddFile(".env", ".env.tpl", []byte("DEBUG=true\nDB_HOST=localhost\n"))
+
+	testCases := []struct {
+		name   string
+		policy conflict.Policy
+		want   project.FileChange
+		env    string
+	}{
+		{name: "Skip", policy: conflict.Skip, want: project.FileChange{Path: ".env", Action: project.FileSkipped}, env: "DEBUG=false\n"},
+		{name: "Overwrite", policy: conflict.Overwrite, want: project.FileChange{Path: ".env", Action: project.FileUpdated}, env: "DEBUG=true\nDB_HOST=localhost\n"},
+		{name: "Backup", policy: conflict.Backup, want: project.FileChange{Path: ".env", Action: project.FileUpdated, Backup: ".env.bak"}, env: "DEBUG=true\nDB_HOST=localhost\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTemplate(t, dir, ".env", "DEBUG=false\n")
+
+			next.OnConflict = conflict.NewResolver(tc.policy, nil)
+			changes, err := next.Update(context.Background(), dir, base)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(changes, []project.FileChange{tc.want}) {
+				t.Errorf("Expected %v, got %v", []project.FileChange{tc.want}, changes)
+			}
+
+			content, err := os.ReadFile(filepath.Join(dir, ".env"))
+			if err != nil {
+				t.Fatalf("Failed to read .env: %v", err)
+			}
+			if string(content) != tc.env {
+				t.Errorf("Expected %q, got %q", tc.env, content)
+			}
+			if _, err := os.Stat(filepath.Join(dir, ".env"+project.NewFileSuffix)); !os.IsNotExist(err) {
+				t.Errorf("Expected no %s file", project.NewFileSuffix)
+			}
+		})
+	}
+}
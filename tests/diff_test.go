@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/diff"
+)
+
+func TestDiff(t *testing.T) {
+	testCases := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "Equal", a: "a\nb\n", b: "a\nb\n", want: " a\n b\n"},
+		{name: "Insert", a: "a\nc\n", b: "a\nb\nc\n", want: " a\n+b\n c\n"},
+		{name: "Delete", a: "a\nb\nc\n", b: "a\nc\n", want: " a\n-b\n c\n"},
+		{name: "Replace", a: "a\nb\nc\n", b: "a\nx\nc\n", want: " a\n-b\n+x\n c\n"},
+		{name: "From empty", a: "", b: "a\n", want: "+a\n"},
+		{name: "Missing final newline", a: "a\nb", b: "a\nb\n", want: " a\n-b+b\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got strings.Builder
+			for _, edit := range diff.Diff(diff.Lines(tc.a), diff.Lines(tc.b)) {
+				got.WriteString(map[diff.Op]string{diff.Equal: " ", diff.Delete: "-", diff.Insert: "+"}[edit.Op] + edit.Text)
+			}
+			if got.String() != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got.String())
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	const base = "package main\n\nfunc main() {\n\tstart()\n}\n\nfunc start() {}\n"
+
+	testCases := []struct {
+		name      string
+		ours      string
+		theirs    string
+		want      string
+		conflicts int
+	}{
+		{
+			name:   "Only theirs changed",
+			ours:   base,
+			theirs: "package main\n\nfunc main() {\n\tstart()\n\twait()\n}\n\nfunc start() {}\n",
+			want:   "package main\n\nfunc main() {\n\tstart()\n\twait()\n}\n\nfunc start() {}\n",
+		},
+		{
+			name:   "Only ours changed",
+			ours:   "// Command demo\npackage main\n\nfunc main() {\n\tstart()\n}\n\nfunc start() {}\n",
+			theirs: base,
+			want:   "// Command demo\npackage main\n\nfunc main() {\n\tstart()\n}\n\nfunc start() {}\n",
+		},
+		{
+			name:   "Both changed different regions",
+			ours:   "// Command demo\npackage main\n\nfunc main() {\n\tstart()\n}\n\nfunc start() {}\n",
+			theirs: "package main\n\nfunc main() {\n\tstart()\n}\n\nfunc start() {\n\tlisten()\n}\n",
+			want:   "// Command demo\npackage main\n\nfunc main() {\n\tstart()\n}\n\nfunc start() {\n\tlisten()\n}\n",
+		},
+		{
+			name:   "Both made the same change",
+			ours:   "package main\n\nfunc main() {\n\trun()\n}\n\nfunc start() {}\n",
+			theirs: "package main\n\nfunc main() {\n\trun()\n}\n\nfunc start() {}\n",
+			want:   "package main\n\nfunc main() {\n\trun()\n}\n\nfunc start() {}\n",
+		},
+		{
+			name:      "Conflict",
+			ours:      "package main\n\nfunc main() {\n\trun()\n}\n\nfunc start() {}\n",
+			theirs:    "package main\n\nfunc main() {\n\tstart()\n\twait()\n}\n\nfunc start() {}\n",
+			want:      "package main\n\nfunc main() {\n<<<<<<< current\n\trun()\n=======\n\tstart()\n\twait()\n>>>>>>> template\n}\n\nfunc start() {}\n",
+			conflicts: 1,
+		},
+		{
+			name:      "Conflict at the end without final newline",
+			ours:      base + "// mine",
+			theirs:    base + "// theirs",
+			want:      base + "<<<<<<< current\n// mine\n=======\n// theirs\n>>>>>>> template\n",
+			conflicts: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := diff.Merge(diff.Lines(base), diff.Lines(tc.ours), diff.Lines(tc.theirs), "current", "template")
+			if result.Text() != tc.want {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tc.want, result.Text())
+			}
+			if result.Conflicts != tc.conflicts {
+				t.Errorf("Expected %d conflicts, got %d", tc.conflicts, result.Conflicts)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	const long = "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+
+	testCases := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "Equal", a: "a\nb\n", b: "a\nb\n", want: ""},
+		{
+			name: "One hunk",
+			a:    "a\nb\nc\n",
+			b:    "a\nx\nc\n",
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "Two hunks",
+			a:    long,
+			b:    strings.Replace(strings.Replace(long, "2\n", "two\n", 1), "11\n", "", 1),
+			want: "--- old\n+++ new\n@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n@@ -8,5 +8,4 @@\n 8\n 9\n 10\n-11\n 12\n",
+		},
+		{
+			name: "Nearby changes share a hunk",
+			a:    long,
+			b:    strings.Replace(strings.Replace(long, "2\n", "two\n", 1), "8\n", "eight\n", 1),
+			want: "--- old\n+++ new\n@@ -1,11 +1,11 @@\n 1\n-2\n+two\n 3\n 4\n 5\n 6\n 7\n-8\n+eight\n 9\n 10\n 11\n",
+		},
+		{name: "New file", a: "", b: "a\n", want: "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n"},
+		{
+			name: "Missing final newline",
+			a:    "a\nb",
+			b:    "a\nb\n",
+			want: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := diff.Unified("old", "new", diff.Lines(tc.a), diff.Lines(tc.b), 3)
+			if got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-sova/sova-cli/pkg/generator"
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+// extendsFS holds a base project type, svc extending it and edge extending svc
+func extendsFS() fstest.MapFS {
+	return fstest.MapFS{
+		"base/template.yaml": {Data: []byte(`name: base
+version: 1.0.0
+description: A base service
+prompts:
+  - name: UseDB
+    type: confirm
+    message: Database?
+    default: true
+  - name: UseCache
+    type: confirm
+    message: Cache?
+directories: [cmd, internal]
+files:
+  - source: main.tpl
+    target: main.txt
+  - source: db.tpl
+    target: db.txt
+    when: .UseDB
+  - source: docker.tpl
+    target: Dockerfile
+dependencies:
+  - name: example.com/db
+    version: v1.0.0
+  - name: example.com/log
+    version: v1.0.0
+hooks:
+  post-generate:
+    - command: gofmt
+    - command: git init
+funcs:
+  shout: '{{ upper . }}!'
+`)},
+		"base/main.tpl":             {Data: []byte(`{{template "banner" .}} main`)},
+		"base/db.tpl":               {Data: []byte(`base db`)},
+		"base/docker.tpl":           {Data: []byte(`FROM scratch`)},
+		"base/_partials/banner.tpl": {Data: []byte(`{{define "banner"}}base banner{{end}}`)},
+		"svc/template.yaml": {Data: []byte(`name: svc
+version: 0.1.0
+extends: base
+prompts:
+  - name: UseCache
+    type: confirm
+    message: Cache?
+    default: true
+  - name: UseQueue
+    type: confirm
+    message: Queue?
+directories: [internal, internal/queue]
+files:
+  - source: queue.tpl
+    target: queue.txt
+    when: .UseQueue
+dependencies:
+  - name: example.com/log
+    version: v2.0.0
+remove:
+  files: [Dockerfile]
+  hooks: [git init]
+`)},
+		"svc/db.tpl":               {Data: []byte(`svc db {{ shout "ok" }}`)},
+		"svc/queue.tpl":            {Data: []byte(`queue`)},
+		"svc/_partials/banner.tpl": {Data: []byte(`{{define "banner"}}svc banner{{end}}`)},
+		"edge/template.yaml": {Data: []byte(`extends: svc
+remove:
+  prompts: [UseQueue]
+  directories: [cmd]
+`)},
+	}
+}
+
+func TestManifestExtends(t *testing.T) {
+	fsys := extendsFS()
+
+	manifest, err := templates.LoadManifestFS(fsys, "svc")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if manifest.Name != "svc" || manifest.Version != "0.1.0" || manifest.Description != "A base service" {
+		t.Errorf("Unexpected metadata: %s %s %q", manifest.Name, manifest.Version, manifest.Description)
+	}
+
+	var prompts []string
+	for _, prompt := range manifest.Prompts {
+		prompts = append(prompts, prompt.Name)
+		if prompt.Name == "UseCache" && prompt.Default != true {
+			t.Errorf("Expected the UseCache prompt of svc, got %+v", prompt)
+		}
+	}
+	if want := []string{"UseDB", "UseCache", "UseQueue"}; !reflect.DeepEqual(prompts, want) {
+		t.Errorf("Expected prompts %v, got %v", want, prompts)
+	}
+
+	if want := []string{"cmd", "internal", "internal/queue"}; !reflect.DeepEqual(manifest.Directories, want) {
+		t.Errorf("Expected directories %v, got %v", want, manifest.Directories)
+	}
+
+	files := make(map[string]string)
+	for _, file := range manifest.Files {
+		files[file.Target] = file.Template("svc")
+	}
+	wantFiles := map[string]string{"main.txt": "base/main.tpl", "db.txt": "svc/db.tpl", "queue.txt": "svc/queue.tpl"}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Errorf("Expected files %v, got %v", wantFiles, files)
+	}
+
+	wantDeps := []templates.Dependency{{Name: "example.com/db", Version: "v1.0.0"}, {Name: "example.com/log", Version: "v2.0.0"}}
+	if !reflect.DeepEqual(manifest.Dependencies, wantDeps) {
+		t.Errorf("Expected dependencies %v, got %v", wantDeps, manifest.Dependencies)
+	}
+	if len(manifest.Hooks.PostGenerate) != 1 || manifest.Hooks.PostGenerate[0].Command != "gofmt" {
+		t.Errorf("Expected only the gofmt hook, got %v", manifest.Hooks.PostGenerate)
+	}
+	if _, ok := manifest.Funcs["shout"]; !ok {
+		t.Error("Expected the shout function to be inherited")
+	}
+	if err := manifest.Validate(fsys, "svc"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	t.Run("Chain", func(t *testing.T) {
+		manifest, err := templates.LoadManifestFS(fsys, "edge")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if manifest.Version != "" {
+			t.Errorf("Did not expect a version to be inherited, got %s", manifest.Version)
+		}
+		if want := []string{"internal", "internal/queue"}; !reflect.DeepEqual(manifest.Directories, want) {
+			t.Errorf("Expected directories %v, got %v", want, manifest.Directories)
+		}
+		if len(manifest.Prompts) != 2 {
+			t.Errorf("Expected 2 prompts, got %d", len(manifest.Prompts))
+		}
+		files := make(map[string]string)
+		for _, file := range manifest.Files {
+			files[file.Target] = file.Template("edge")
+		}
+		if !reflect.DeepEqual(files, wantFiles) {
+			t.Errorf("Expected files %v, got %v", wantFiles, files)
+		}
+		want := []string{"_partials", "base/_partials", "svc/_partials", "edge/_partials"}
+		if got := manifest.PartialDirs("edge"); !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected partial directories %v, got %v", want, got)
+		}
+	})
+}
+
+func TestManifestExtendsErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		manifest    string
+		expectError string
+	}{
+		{name: "Unknown parent", manifest: "extends: missing\n", expectError: "extends missing"},
+		{name: "Circular", manifest: "extends: child\n", expectError: "circular extends: child -> child"},
+		{name: "Remove missing file", manifest: "extends: base\nremove:\n  files: [README.md]\n", expectError: "remove.files: base has no README.md"},
+		{name: "Remove missing prompt", manifest: "extends: base\nremove:\n  prompts: [UseQueue]\n", expectError: "remove.prompts"},
+		{name: "Remove without extends", manifest: "remove:\n  files: [main.txt]\n", expectError: "only a manifest that extends"},
+		{name: "Duplicate alias", manifest: "extends: base\nprompts:\n  - name: UseOther\n    alias: db\n    type: confirm\n    message: Other?\n", expectError: "duplicate name db"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := extendsFS()
+			fsys["base/template.yaml"] = &fstest.MapFile{Data: []byte("prompts:\n  - name: UseDB\n    alias: db\n    type: confirm\n    message: Database?\nfiles:\n  - source: main.tpl\n    target: main.txt\n")}
+			fsys["child/template.yaml"] = &fstest.MapFile{Data: []byte(tc.manifest)}
+
+			_, err := templates.LoadManifestFS(fsys, "child")
+			if err == nil || !strings.Contains(err.Error(), tc.expectError) {
+				t.Errorf("Expected error containing %q, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestGenerateExtendedTemplate(t *testing.T) {
+	result, err := generator.Generate(context.Background(), generator.Spec{
+		ProjectName: "demo",
+		Answers:     &questions.ProjectAnswers{ProjectType: "svc", Values: map[string]interface{}{"UseDB": true, "UseQueue": true}},
+		Source:      extendsFS(),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"main.txt":  "svc banner main",
+		"db.txt":    "svc db OK!",
+		"queue.txt": "queue",
+	}
+	if len(result.Project.Files) != len(want) {
+		t.Errorf("Expected %d files, got %d", len(want), len(result.Project.Files))
+	}
+	for name, content := range want {
+		file, ok := result.Project.File(name)
+		if !ok {
+			t.Errorf("Expected %s in the plan", name)
+			continue
+		}
+		if string(file.Content) != content {
+			t.Errorf("Expected %s to be %q, got %q", name, content, file.Content)
+		}
+	}
+	if result.Project.Template != "svc" || result.Project.TemplateVersion != "0.1.0" {
+		t.Errorf("Unexpected template: %s %s", result.Project.Template, result.Project.TemplateVersion)
+	}
+}
@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"text/template"
+	"time"
+
+	"github.com/go-sova/sova-cli/pkg/generator"
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+func renderFuncs(t *testing.T, text string, data interface{}) (string, error) {
+	t.Helper()
+	tmpl, err := template.New("test").Funcs(templates.FuncMap()).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf strings.Builder
+	err = tmpl.Execute(&buf, data)
+	return buf.String(), err
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	os.Setenv("SOVA_TEST_FUNCS", "from env")
+	defer os.Unsetenv("SOVA_TEST_FUNCS")
+
+	data := map[string]interface{}{
+		"Name":     "user_profile",
+		"Features": []string{"auth", "metrics"},
+		"Port":     0,
+	}
+
+	testCases := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{name: "Camel", template: `{{ camel "user_profile" }} {{ camel "HTTPServer" }} {{ camel "user-id" }}`, expected: "userProfile httpServer userId"},
+		{name: "Pascal", template: `{{ pascal "user_profile" }} {{ pascal "order item" }} {{ .Name | pascal }}`, expected: "UserProfile OrderItem UserProfile"},
+		{name: "Snake", template: `{{ snake "UserProfile" }} {{ snake "HTTPServer" }} {{ snake "api-v2" }}`, expected: "user_profile http_server api_v2"},
+		{name: "Kebab", template: `{{ kebab "UserProfile" }} {{ kebab "user_profile" }}`, expected: "user-profile user-profile"},
+		{name: "Plural", template: `{{ plural "user" }} {{ plural "Category" }} {{ plural "address" }} {{ plural "box" }} {{ plural "status" }} {{ plural "person" }} {{ plural "human" }} {{ plural "day" }}`, expected: "users Categories addresses boxes statuses people humans days"},
+		{name: "Plural keeps plurals and uncountables", template: `{{ plural "users" }} {{ plural "metadata" }} {{ plural "user_data" }}`, expected: "users metadata user_data"},
+		{name: "Plural of identifiers", template: `{{ plural "OrderItem" }} {{ plural "USER" }} {{ plural "ChildPerson" }}`, expected: "OrderItems USERS ChildPeople"},
+		{name: "Singular", template: `{{ singular "users" }} {{ singular "Categories" }} {{ singular "addresses" }} {{ singular "boxes" }} {{ singular "statuses" }} {{ singular "people" }} {{ singular "analyses" }} {{ singular "caches" }}`, expected: "user Category address box status person analysis cache"},
+		{name: "Singular keeps singulars", template: `{{ singular "user" }} {{ singular "status" }} {{ singular "class" }} {{ singular "news" }}`, expected: "user status class news"},
+		{name: "Upper lower trim", template: `{{ upper "api" }} {{ lower "API" }} [{{ trim "  x  " }}]`, expected: "API api [x]"},
+		{name: "Replace", template: `{{ .Name | replace "_" "-" }}`, expected: "user-profile"},
+		{name: "Default", template: `{{ .Port | default 8080 }} {{ .Missing | default "none" }} {{ .Name | default "x" }}`, expected: "8080 none user_profile"},
+		{name: "Join", template: `{{ .Features | join ", " }}`, expected: "auth, metrics"},
+		{name: "Indent", template: `{{ indent 2 "a\nb" }}`, expected: "  a\n  b"},
+		{name: "Quote", template: `{{ quote .Name }} {{ quote 8080 }}`, expected: `"user_profile" "8080"`},
+		{name: "Env", template: `{{ env "SOVA_TEST_FUNCS" }}`, expected: "from env"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := renderFuncs(t, tc.template, data)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	t.Run("Now", func(t *testing.T) {
+		got, err := renderFuncs(t, `{{ now.Year }}`, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if want := time.Now().Format("2006"); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	})
+
+	t.Run("UUID", func(t *testing.T) {
+		got, err := renderFuncs(t, `{{ uuid }} {{ uuid }}`, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+		ids := strings.Fields(got)
+		if len(ids) != 2 || ids[0] == ids[1] {
+			t.Fatalf("Expected two different UUIDs, got %q", got)
+		}
+		for _, id := range ids {
+			if !pattern.MatchString(id) {
+				t.Errorf("Expected a version 4 UUID, got %q", id)
+			}
+		}
+	})
+
+	t.Run("Join rejects non-lists", func(t *testing.T) {
+		if _, err := renderFuncs(t, `{{ join ", " .Name }}`, data); err == nil {
+			t.Error("Expected error but got none")
+		}
+	})
+
+	t.Run("FuncMap is a copy", func(t *testing.T) {
+		funcs := templates.FuncMap()
+		delete(funcs, "camel")
+		if _, ok := templates.FuncMap()["camel"]; !ok {
+			t.Error("Expected camel in a new FuncMap")
+		}
+	})
+}
+
+func TestTemplateFuncsInConditions(t *testing.T) {
+	ok, err := templates.EvalCondition(`eq (lower .Database) "postgres"`, map[string]interface{}{"Database": "Postgres"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !ok {
+		t.Error("Expected the condition to hold")
+	}
+}
+
+func TestManifestFuncs(t *testing.T) {
+	source := fstest.MapFS{
+		"svc/template.yaml": {Data: []byte(`name: svc
+funcs:
+  table: '{{ . | snake | plural }}'
+  route: '/{{ table . | replace "_" "-" }}'
+  upper: '{{ . }}!'
+files:
+  - source: main.tpl
+    target: main.txt
+`)},
+		"svc/main.tpl": {Data: []byte(`{{ table "OrderItem" }} {{ route "OrderItem" }} {{ upper "x" }} {{ greet .ProjectName }}` + "\n")},
+	}
+
+	spec := generator.Spec{
+		ProjectName: "demo",
+		Answers:     &questions.ProjectAnswers{ProjectType: "svc"},
+		Source:      source,
+		Funcs: template.FuncMap{
+			"greet": func(name string) string { return "hello " + name },
+		},
+	}
+	result, err := generator.Generate(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	file, ok := result.Project.File("main.txt")
+	if !ok {
+		t.Fatal("Expected main.txt in the plan")
+	}
+	if want := "order_items /order-items x! hello demo\n"; string(file.Content) != want {
+		t.Errorf("Expected %q, got %q", want, file.Content)
+	}
+
+	spec.Funcs = nil
+	if _, err := generator.Generate(context.Background(), spec); err == nil || !strings.Contains(err.Error(), "greet") {
+		t.Errorf("Expected error about greet, got %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/pkg/generator"
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+// planProject renders a project from the built-in templates without writing it
+func planProject(t *testing.T, projectName string, answers *questions.ProjectAnswers) *project.Plan {
+	t.Helper()
+	plan, err := project.PlanProject(templates.GetTemplateFS(), nil, projectName, answers)
+	if err != nil {
+		t.Fatalf("Failed to plan project: %v", err)
+	}
+	return plan
+}
+
+func TestGenerate(t *testing.T) {
+	source := fstest.MapFS{
+		"svc/template.yaml": {Data: []byte("name: svc\nversion: 0.3.0\ndescription: A small service\ndirectories: [internal]\nfiles:\n  - source: main.tpl\n    target: main.go\n  - source: readme.tpl\n    target: README.md\n    when: .WithReadme\n")},
+		"svc/main.tpl":      {Data: []byte("package main\nimport (\n\"os\"\n\"fmt\"\n)\nfunc main() {\nfmt.Println(\"{{.ProjectName}}\")\n}\n")},
+		"svc/readme.tpl":    {Data: []byte("# {{.ProjectName}}\n\n{{.ProjectDescription}}\n")},
+		"bad/main.go.tpl":   {Data: []byte("package main\n\nfunc main() {\n\tfmt.Println(\n}\n")},
+	}
+
+	testCases := []struct {
+		name        string
+		spec        generator.Spec
+		files       map[string]string
+		expectError string
+	}{
+		{
+			name: "Render only",
+			spec: generator.Spec{
+				ProjectName: "demo",
+				Answers:     &questions.ProjectAnswers{ProjectType: "svc", ModulePath: "example.com/demo", Values: map[string]interface{}{"WithReadme": true}},
+			},
+			files: map[string]string{
+				"main.go":   "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"demo\")\n}\n",
+				"README.md": "# demo\n\nA small service\n",
+			},
+		},
+		{
+			name: "Project name from answers",
+			spec: generator.Spec{
+				Answers: &questions.ProjectAnswers{ProjectName: "other", ProjectType: "svc", Values: map[string]interface{}{"WithReadme": false}},
+			},
+			files: map[string]string{
+				"main.go": "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"other\")\n}\n",
+			},
+		},
+		{
+			name:        "Invalid Go",
+			spec:        generator.Spec{ProjectName: "demo", Answers: &questions.ProjectAnswers{ProjectType: "bad"}},
+			expectError: "template bad/main.go.tpl",
+		},
+		{
+			name:        "No answers",
+			spec:        generator.Spec{ProjectName: "demo"},
+			expectError: "no answers",
+		},
+		{
+			name:        "No project name",
+			spec:        generator.Spec{Answers: &questions.ProjectAnswers{ProjectType: "svc"}},
+			expectError: "project name is required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.spec.Source = source
+			result, err := generator.Generate(context.Background(), tc.spec)
+			if tc.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectError) {
+					t.Errorf("Expected error containing %q, got %v", tc.expectError, err)
+				}
+				if result.Project != nil {
+					t.Error("Did not expect a plan for a failed render")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(result.Project.Files) != len(tc.files) {
+				t.Errorf("Expected %d files, got %d", len(tc.files), len(result.Project.Files))
+			}
+			for name, want := range tc.files {
+				file, ok := result.Project.File(name)
+				if !ok {
+					t.Errorf("Expected %s in the plan", name)
+					continue
+				}
+				if string(file.Content) != want {
+					t.Errorf("Expected %s to be %q, got %q", name, want, file.Content)
+				}
+			}
+			if result.Project.Template != "svc" || result.Project.TemplateVersion != "0.3.0" {
+				t.Errorf("Unexpected template: %s %s", result.Project.Template, result.Project.TemplateVersion)
+			}
+		})
+	}
+
+	t.Run("Directory sink", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "demo")
+		spec := generator.Spec{
+			ProjectName: "demo",
+			Answers:     &questions.ProjectAnswers{ProjectType: "svc", Values: map[string]interface{}{"WithReadme": true}},
+			Source:      source,
+			Sink:        &generator.DirSink{Dir: dir},
+		}
+		if _, err := generator.Generate(context.Background(), spec); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, name := range []string{"main.go", "README.md", "internal", project.LockFile} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+				t.Errorf("Expected %s to be written: %v", name, err)
+			}
+		}
+
+		if _, err := generator.Generate(context.Background(), spec); err == nil {
+			t.Error("Expected error for an existing directory without a conflict policy")
+		}
+	})
+
+	t.Run("Existing directory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "demo")
+		writeTemplate(t, dir, "main.go", "package main // mine\n")
+
+		var asked []string
+		_, err := generator.Generate(context.Background(), generator.Spec{
+			ProjectName: "demo",
+			Answers:     &questions.ProjectAnswers{ProjectType: "svc"},
+			Source:      source,
+			Sink: &generator.DirSink{
+				Dir:        dir,
+				Hooks:      &generator.HookOptions{NoGit: true},
+				OnConflict: generator.ConflictPrompt,
+				Ask: func(path string, current, next []byte) (generator.ConflictPolicy, error) {
+					asked = append(asked, filepath.Base(path))
+					return generator.ConflictSkip, nil
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(asked) != 1 || asked[0] != "main.go" {
+			t.Errorf("Expected to be asked about main.go, got %v", asked)
+		}
+		if content, _ := os.ReadFile(filepath.Join(dir, "main.go")); string(content) != "package main // mine\n" {
+			t.Errorf("Expected main.go to be kept, got %q", content)
+		}
+	})
+
+	t.Run("Custom sink", func(t *testing.T) {
+		sink := &memorySink{}
+		result, err := generator.Generate(context.Background(), generator.Spec{
+			ProjectName: "demo",
+			Answers:     &questions.ProjectAnswers{ProjectType: "svc"},
+			Source:      source,
+			Sink:        sink,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if sink.project != result.Project || len(sink.files) != 1 || sink.files[0] != "main.go" {
+			t.Errorf("Expected the sink to receive the project with main.go, got %v", sink.files)
+		}
+	})
+
+	t.Run("Broken template writes nothing", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "demo")
+		_, err := generator.Generate(context.Background(), generator.Spec{
+			ProjectName: "demo",
+			Answers:     &questions.ProjectAnswers{ProjectType: "bad"},
+			Source:      source,
+			Sink:        &generator.DirSink{Dir: dir},
+		})
+		if err == nil {
+			t.Fatal("Expected error but got none")
+		}
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Error("Did not expect a project directory for a template that renders invalid Go")
+		}
+	})
+}
+
+// memorySink records the project it is asked to write
+type memorySink struct {
+	project *generator.Project
+	files   []string
+}
+
+func (s *memorySink) Write(ctx context.Context, p *generator.Project) error {
+	s.project = p
+	for _, file := range p.Files {
+		s.files = append(s.files, file.Path)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/golden"
+	"github.com/go-sova/sova-cli/pkg/templatetest"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+const goldenManifest = `name: worker
+prompts:
+  - name: UseCron
+    alias: cron
+    type: confirm
+    message: Schedule jobs?
+files:
+  - source: main.tpl
+    target: main.go
+  - source: cron.tpl
+    target: cron.go
+    when: .UseCron
+`
+
+func TestGoldenFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	templateDir := filepath.Join(tempDir, "worker")
+	writeTemplate(t, templateDir, "template.yaml", goldenManifest)
+	writeTemplate(t, templateDir, "main.tpl", "package main\n\n// {{.ProjectName}} in {{.ModuleName}}\nfunc main() {}\n")
+	writeTemplate(t, templateDir, "cron.tpl", "package main\n")
+	writeTemplate(t, templateDir, "testdata/cron.yaml", "name: jobs\ncomponents:\n  cron: true\n")
+	writeTemplate(t, templateDir, "testdata/plain.yaml", "values:\n  UseCron: false\n")
+
+	defer templates.SetTemplateDirs(nil)
+	targets, err := golden.AddSource(templateDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(targets) != 1 || targets[0].ProjectType != "worker" || targets[0].Dir != filepath.Join(templateDir, "testdata") {
+		t.Fatalf("Expected worker tested against its testdata directory, got %+v", targets)
+	}
+	goldenDir := targets[0].Dir
+
+	if _, err := golden.Run(context.Background(), "worker", goldenDir, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(goldenDir, "cron", "main.go.golden"))
+	if err != nil {
+		t.Fatalf("Expected golden file to be written: %v", err)
+	}
+	if !strings.Contains(string(content), "// jobs in example.com/jobs") {
+		t.Errorf("Golden file was not rendered with the case answers:\n%s", content)
+	}
+	if _, err := os.Stat(filepath.Join(goldenDir, "plain", "cron.go.golden")); !os.IsNotExist(err) {
+		t.Error("Did not expect cron.go in the golden files of the plain case")
+	}
+
+	testCases := []struct {
+		name   string
+		change func(t *testing.T)
+		want   map[string]string
+	}{
+		{
+			name:   "Unchanged template",
+			change: func(t *testing.T) {},
+			want:   map[string]string{},
+		},
+		{
+			name: "Changed template",
+			change: func(t *testing.T) {
+				writeTemplate(t, templateDir, "main.tpl", "package main\n\n// {{.ProjectName}}\nfunc main() {}\n")
+			},
+			want: map[string]string{
+				"cron":  `main.go: line 3: got "// jobs", want "// jobs in example.com/jobs"`,
+				"plain": `main.go: line 3: got "// example", want "// example in example.com/example"`,
+			},
+		},
+		{
+			name: "Golden file that is no longer generated",
+			change: func(t *testing.T) {
+				writeTemplate(t, templateDir, "main.tpl", "package main\n\n// {{.ProjectName}} in {{.ModuleName}}\nfunc main() {}\n")
+				writeTemplate(t, goldenDir, "plain/cron.go.golden", "package main\n")
+			},
+			want: map[string]string{
+				"plain": "cron.go: has a golden file but was not generated",
+			},
+		},
+		{
+			name: "Missing golden file",
+			change: func(t *testing.T) {
+				if err := os.RemoveAll(filepath.Join(goldenDir, "plain")); err != nil {
+					t.Fatalf("Failed to remove golden files: %v", err)
+				}
+			},
+			want: map[string]string{
+				"plain": "main.go: generated but has no golden file",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.change(t)
+
+			results, err := golden.Run(context.Background(), "worker", goldenDir, false)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(results) != 2 {
+				t.Fatalf("Expected 2 cases, got %+v", results)
+			}
+			for _, result := range results {
+				var got []string
+				for _, difference := range result.Differences {
+					got = append(got, difference.String())
+				}
+				if strings.Join(got, "\n") != tc.want[result.Case] {
+					t.Errorf("Case %s: expected differences %q, got %q", result.Case, tc.want[result.Case], got)
+				}
+			}
+		})
+	}
+}
+
+func TestTemplatetestRunDir(t *testing.T) {
+	tempDir := t.TempDir()
+	writeTemplate(t, tempDir, "worker/template.yaml", goldenManifest)
+	writeTemplate(t, tempDir, "worker/main.tpl", "package main\n\nfunc main() {}\n")
+	writeTemplate(t, tempDir, "worker/cron.tpl", "package main\n")
+	writeTemplate(t, tempDir, "worker/testdata/default/main.go.golden", "package main\n\nfunc main() {}\n")
+
+	// Sources added before RunDir are still there after it
+	dirs := t.TempDir()
+	writeTemplate(t, dirs, "local/template.yaml", "name: local\n")
+	source := t.TempDir()
+	writeTemplate(t, source, "template.yaml", "name: fetched\n")
+	templates.SetTemplateDirs([]string{dirs})
+	defer templates.SetTemplateDirs(nil)
+	if _, err := templates.AddTemplateSource(source, "fetched"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("RunDir", func(t *testing.T) {
+		templatetest.RunDir(t, tempDir)
+	})
+
+	testCases := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{name: "Template directory", path: "local/template.yaml", want: true},
+		{name: "Template source", path: "fetched/template.yaml", want: true},
+		{name: "Template of RunDir", path: "worker/template.yaml", want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := fs.Stat(templates.GetTemplateFS(), tc.path)
+			if got := err == nil; got != tc.want {
+				t.Errorf("Expected %s to exist: %v, got: %v", tc.path, tc.want, got)
+			}
+		})
+	}
+	if dirs := templates.TemplateDirs(); len(dirs) != 1 {
+		t.Errorf("Expected the template directory to be kept, got %v", dirs)
+	}
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/gosource"
+)
+
+func TestFormatGoSource(t *testing.T) {
+	testCases := []struct {
+		name       string
+		modulePath string
+		src        string
+		want       string
+		wantLine   int
+	}{
+		{
+			name:       "Groups and sorts imports",
+			modulePath: "example.com/demo",
+			src:        "package main\nimport (\n\"example.com/demo/internal/config\"\n\"github.com/spf13/cobra\"\n\"os\"\n\"fmt\"\n)\nfunc main() { fmt.Println(os.Args, config.Load, cobra.Command{}) }\n",
+			want:       "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\n\t\"github.com/spf13/cobra\"\n\n\t\"example.com/demo/internal/config\"\n)\n\nfunc main() { fmt.Println(os.Args, config.Load, cobra.Command{}) }\n",
+		},
+		{
+			name: "Removes unused standard library imports",
+			src:  "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\t\"strings\"\n)\n\nfunc main() { fmt.Println() }\n",
+			want: "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println() }\n",
+		},
+		{
+			name: "Removes the import block when nothing is used",
+			src:  "package main\n\nimport \"os\"\n\nfunc main() {}\n",
+			want: "package main\n\nfunc main() {}\n",
+		},
+		{
+			name: "Keeps imports whose name is not certain",
+			src:  "package main\n\nimport (\n\t\"github.com/go-redis/redis/v8\"\n\t_ \"github.com/lib/pq\"\n)\n\nfunc main() {}\n",
+			want: "package main\n\nimport (\n\t\"github.com/go-redis/redis/v8\"\n\t_ \"github.com/lib/pq\"\n)\n\nfunc main() {}\n",
+		},
+		{
+			name:       "Module path without a dot",
+			modulePath: "demo",
+			src:        "package main\n\nimport (\n\t\"demo/cmd\"\n\t\"os\"\n)\n\nfunc main() { cmd.Execute(os.Args) }\n",
+			want:       "package main\n\nimport (\n\t\"os\"\n\n\t\"demo/cmd\"\n)\n\nfunc main() { cmd.Execute(os.Args) }\n",
+		},
+		{
+			name:     "Syntax error",
+			src:      "package main\n\nfunc main() {\n\tx :=\n}\n",
+			wantLine: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := gosource.Format([]byte(tc.src), tc.modulePath)
+			if tc.wantLine != 0 {
+				var syntaxErr *gosource.Error
+				if !errors.As(err, &syntaxErr) {
+					t.Fatalf("Expected a gosource.Error, got %v", err)
+				}
+				if syntaxErr.Line != tc.wantLine {
+					t.Errorf("Expected the error on line %d, got %d (%v)", tc.wantLine, syntaxErr.Line, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/conflict"
+	"github.com/go-sova/sova-cli/internal/hooks"
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/pkg/utils"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+func newTestRunner(output *bytes.Buffer) *hooks.Runner {
+	logger := utils.NewLogger(utils.Info)
+	logger.SetOutput(output)
+	runner := hooks.NewRunner()
+	runner.SetLogger(logger)
+	return runner
+}
+
+func TestHookRunner(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	testCases := []struct {
+		name       string
+		steps      []string
+		files      map[string]string
+		wantFiles  map[string]string
+		wantOutput []string
+		wantErr    string
+	}{
+		{
+			name:      "Gofmt",
+			steps:     []string{"gofmt"},
+			files:     map[string]string{"main.go": "package main\nfunc main() {\n}\n", "notes.txt": "func  main"},
+			wantFiles: map[string]string{"main.go": "package main\n\nfunc main() {\n}\n", "notes.txt": "func  main"},
+		},
+		{
+			name:      "Gofmt leaves the base render alone",
+			steps:     []string{"gofmt"},
+			files:     map[string]string{"main.go": "package main\nfunc main() {\n}\n", ".sova/base/main.go": "package main\nfunc main() {\n}\n"},
+			wantFiles: map[string]string{"main.go": "package main\n\nfunc main() {\n}\n", ".sova/base/main.go": "package main\nfunc main() {\n}\n"},
+		},
+		{
+			name:    "Gofmt on invalid Go",
+			steps:   []string{"gofmt"},
+			files:   map[string]string{"internal/broken.go": "package broken\nfunc {\n"},
+			wantErr: `hook "gofmt" failed: internal/broken.go:`,
+		},
+		{
+			name:       "Commands in order",
+			steps:      []string{"echo one > steps.txt", "echo two >> steps.txt", "cat steps.txt"},
+			wantFiles:  map[string]string{"steps.txt": "one\ntwo\n"},
+			wantOutput: []string{"Running echo one > steps.txt", "  one", "  two"},
+		},
+		{
+			name:       "Failing command stops the remaining steps",
+			steps:      []string{"echo oops >&2; exit 3", "touch never"},
+			wantOutput: []string{"  oops"},
+			wantErr:    `hook "echo oops >&2; exit 3" failed: sh exited with status 3`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				writeTemplate(t, dir, name, content)
+			}
+
+			var steps []templates.HookStep
+			for _, command := range tc.steps {
+				steps = append(steps, templates.HookStep{Command: command})
+			}
+
+			var output bytes.Buffer
+			err := newTestRunner(&output).Run(context.Background(), dir, steps)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("Expected error %q, got %v", tc.wantErr, err)
+				}
+				if _, err := os.Stat(filepath.Join(dir, "never")); err == nil {
+					t.Error("Expected the steps after a failing step to be skipped")
+				}
+			} else if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for name, want := range tc.wantFiles {
+				content, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatalf("Failed to read %s: %v", name, err)
+				}
+				if string(content) != want {
+					t.Errorf("Expected %s to contain %q, got %q", name, want, content)
+				}
+			}
+			for _, want := range tc.wantOutput {
+				if !strings.Contains(output.String(), want) {
+					t.Errorf("Expected output containing %q, got:\n%s", want, output.String())
+				}
+			}
+		})
+	}
+}
+
+func TestHookRunnerGitInit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	gitConfig := filepath.Join(t.TempDir(), "gitconfig")
+	if err := os.WriteFile(gitConfig, []byte("[user]\n\tname = Sova Test\n\temail = sova@example.com\n"), 0644); err != nil {
+		t.Fatalf("Failed to write git config: %v", err)
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", gitConfig)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	testCases := []struct {
+		name       string
+		noGit      bool
+		repository bool
+		wantCommit bool
+	}{
+		{name: "Initial commit", wantCommit: true},
+		{name: "No git", noGit: true},
+		{name: "Existing repository", repository: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "project")
+			writeTemplate(t, dir, "main.go", "package main\n")
+			if tc.repository {
+				runGit(t, dir, "init", "--quiet")
+			}
+
+			var output bytes.Buffer
+			runner := newTestRunner(&output)
+			runner.NoGit = tc.noGit
+			if err := runner.Run(context.Background(), dir, []templates.HookStep{{Command: "git  init"}}); err != nil {
+				t.Fatalf("Unexpected error: %v\n%s", err, output.String())
+			}
+
+			_, err := os.Stat(filepath.Join(dir, ".git"))
+			if tc.noGit {
+				if err == nil {
+					t.Error("Did not expect a git repository with NoGit")
+				}
+				return
+			}
+			if !tc.wantCommit {
+				if status := runGit(t, dir, "status", "--porcelain"); status != "?? main.go" {
+					t.Errorf("Expected main.go to stay untracked, got:\n%s", status)
+				}
+				return
+			}
+
+			log, err := exec.Command("git", "-C", dir, "log", "--format=%s", "--name-only").CombinedOutput()
+			if err != nil {
+				t.Fatalf("Expected a commit: %v\n%s", err, log)
+			}
+			if !strings.Contains(string(log), hooks.InitialCommitMessage) || !strings.Contains(string(log), "main.go") {
+				t.Errorf("Expected an initial commit with main.go, got:\n%s", log)
+			}
+		})
+	}
+}
+
+func TestPlanGenerateHooks(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	testCases := []struct {
+		name        string
+		hooks       templates.Hooks
+		wantProject bool
+		wantHookErr bool
+		wantFiles   []string
+	}{
+		{
+			name: "Pre and post generate",
+			hooks: templates.Hooks{
+				PreGenerate:  []templates.HookStep{{Command: "touch pre.txt"}},
+				PostGenerate: []templates.HookStep{{Command: "test -f main.go && touch post.txt"}},
+			},
+			wantProject: true,
+			wantFiles:   []string{"main.go", "pre.txt", "post.txt"},
+		},
+		{
+			name:  "Failing pre-generate hook",
+			hooks: templates.Hooks{PreGenerate: []templates.HookStep{{Command: "exit 1"}}},
+		},
+		{
+			name:        "Failing post-generate hook",
+			hooks:       templates.Hooks{PostGenerate: []templates.HookStep{{Command: "exit 1"}}},
+			wantProject: true,
+			wantHookErr: true,
+			wantFiles:   []string{"main.go"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parent := t.TempDir()
+			projectDir := filepath.Join(parent, "demo")
+
+			plan := &project.Plan{ProjectName: "demo", Hooks: tc.hooks}
+			plan.AddFile("main.go", "main.tpl", []byte("package main\n"))
+
+			var output bytes.Buffer
+			err := plan.Generate(context.Background(), projectDir, newTestRunner(&output))
+
+			var hookErr *project.HookError
+			switch {
+			case tc.wantHookErr:
+				if !errors.As(err, &hookErr) {
+					t.Errorf("Expected a HookError, got %v", err)
+				}
+			case tc.wantProject:
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+			default:
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+			}
+
+			entries, _ := os.ReadDir(parent)
+			if !tc.wantProject {
+				if len(entries) != 0 {
+					t.Errorf("Expected nothing to be written, found %d entries", len(entries))
+				}
+				return
+			}
+			for _, name := range tc.wantFiles {
+				if _, err := os.Stat(filepath.Join(projectDir, name)); err != nil {
+					t.Errorf("Expected %s in the project: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanGenerateConfirmShell(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	testCases := []struct {
+		name        string
+		allow       bool
+		confirmErr  error
+		wantProject bool
+		wantFiles   []string
+		noFiles     []string
+	}{
+		{
+			name:        "Allowed",
+			allow:       true,
+			wantProject: true,
+			wantFiles:   []string{"pre.txt", "post.txt"},
+		},
+		{
+			name:        "Refused",
+			wantProject: true,
+			noFiles:     []string{"pre.txt", "post.txt"},
+		},
+		{
+			name:       "Confirmation fails",
+			confirmErr: errors.New("no terminal"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parent := t.TempDir()
+			projectDir := filepath.Join(parent, "demo")
+
+			plan := &project.Plan{ProjectName: "demo", Hooks: templates.Hooks{
+				PreGenerate:  []templates.HookStep{{Command: "touch pre.txt"}},
+				PostGenerate: []templates.HookStep{{Command: "gofmt"}, {Command: "touch post.txt"}},
+			}}
+			plan.AddFile("main.go", "main.tpl", []byte("package main\nfunc main() {\n}\n"))
+
+			var asked [][]string
+			var output bytes.Buffer
+			runner := newTestRunner(&output)
+			runner.ConfirmShell = func(commands []string) (bool, error) {
+				asked = append(asked, commands)
+				return tc.allow, tc.confirmErr
+			}
+
+			err := plan.Generate(context.Background(), projectDir, runner)
+			if len(asked) != 1 || strings.Join(asked[0], "; ") != "touch pre.txt; touch post.txt" {
+				t.Errorf("Expected to be asked once about both commands, got %q", asked)
+			}
+			if !tc.wantProject {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				if entries, _ := os.ReadDir(parent); len(entries) != 0 {
+					t.Errorf("Expected nothing to be written, found %d entries", len(entries))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v\n%s", err, output.String())
+			}
+
+			for _, name := range tc.wantFiles {
+				if _, err := os.Stat(filepath.Join(projectDir, name)); err != nil {
+					t.Errorf("Expected %s in the project: %v", name, err)
+				}
+			}
+			for _, name := range tc.noFiles {
+				if _, err := os.Stat(filepath.Join(projectDir, name)); err == nil {
+					t.Errorf("Did not expect %s in the project", name)
+				}
+			}
+			content, _ := os.ReadFile(filepath.Join(projectDir, "main.go"))
+			if string(content) != "package main\n\nfunc main() {\n}\n" {
+				t.Errorf("Expected the built-in gofmt step to run, got:\n%s", content)
+			}
+		})
+	}
+}
+
+func TestPlanGenerateIntoExistingRepo(t *testing.T) {
+	for _, tool := range []string{"git", "sh"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s is not available", tool)
+		}
+	}
+
+	gitConfig := filepath.Join(t.TempDir(), "gitconfig")
+	if err := os.WriteFile(gitConfig, []byte("[user]\n\tname = Sova Test\n\temail = sova@example.com\n"), 0644); err != nil {
+		t.Fatalf("Failed to write git config: %v", err)
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", gitConfig)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	testCases := []struct {
+		name          string
+		inExistingDir bool
+		wantPost      bool
+	}{
+		{name: "Hooks skipped"},
+		{name: "Hooks run on request", inExistingDir: true, wantPost: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			projectDir := filepath.Join(t.TempDir(), "demo")
+			writeTemplate(t, projectDir, "notes.txt", "first\n")
+			runGit(t, projectDir, "init", "--quiet")
+			runGit(t, projectDir, "add", "notes.txt")
+			runGit(t, projectDir, "commit", "--quiet", "-m", "Notes")
+			writeTemplate(t, projectDir, "notes.txt", "first\nsecond\n")
+			writeTemplate(t, projectDir, "wip.txt", "work in progress\n")
+
+			plan := &project.Plan{ProjectName: "demo", Hooks: templates.Hooks{
+				PreGenerate:  []templates.HookStep{{Command: "touch pre.txt"}},
+				PostGenerate: []templates.HookStep{{Command: "touch post.txt"}, {Command: "git init"}},
+			}}
+			plan.AddFile("main.go", "main.tpl", []byte("package main\n"))
+			plan.OnConflict = conflict.NewResolver(conflict.Skip, nil)
+
+			var output bytes.Buffer
+			runner := newTestRunner(&output)
+			runner.InExistingDir = tc.inExistingDir
+			if err := plan.Generate(context.Background(), projectDir, runner); err != nil {
+				t.Fatalf("Unexpected error: %v\n%s", err, output.String())
+			}
+
+			for _, name := range []string{"pre.txt", "post.txt"} {
+				_, err := os.Stat(filepath.Join(projectDir, name))
+				if tc.wantPost && err != nil {
+					t.Errorf("Expected the hook creating %s to run: %v", name, err)
+				}
+				if !tc.wantPost && err == nil {
+					t.Errorf("Did not expect the hook creating %s to run", name)
+				}
+			}
+
+			if log := runGit(t, projectDir, "log", "--format=%s"); log != "Notes" {
+				t.Errorf("Expected no commit by git init, got:\n%s", log)
+			}
+			status := runGit(t, projectDir, "status", "--porcelain")
+			if !strings.Contains(status, "M notes.txt") || !strings.Contains(status, "?? wip.txt") || !strings.Contains(status, "?? main.go") {
+				t.Errorf("Expected the changes in the repository to stay uncommitted and unstaged, got:\n%s", status)
+			}
+		})
+	}
+}
@@ -1,144 +1,40 @@
 package tests
 
 import (
-	"os"
-	"os/exec"
+	"context"
+	"flag"
 	"path/filepath"
 	"testing"
+
+	"github.com/go-sova/sova-cli/internal/golden"
+	"github.com/go-sova/sova-cli/pkg/templatetest"
 )
 
-func TestProjectInitialization(t *testing.T) {
-	tempDir, err := os.MkdirTemp("", "sova-test-*")
-	if err != nil {
-		t.Fatalf("Failed to create temp directory: %v", err)
-	}
-	defer os.RemoveAll(tempDir)
+// update is read by templatetest, which does not define the flag itself
+var update = flag.Bool("update", false, "rewrite the golden files of the built-in templates")
 
+// TestProjectInitialization renders the built-in templates with the answers
+// in testdata/golden and compares the generated projects with the golden
+// files. Run `go test ./tests -run TestProjectInitialization -update` after
+// changing a template and review the updated golden files.
+func TestProjectInitialization(t *testing.T) {
 	testCases := []struct {
-		name        string
-		projectName string
-		template    string
-		wantDirs    []string
-		wantFiles   []string
-		wantErr     bool
+		name     string
+		template string
 	}{
-		{
-			name:        "Basic project creation",
-			projectName: "test-project",
-			template:    "default",
-			wantDirs: []string{
-				"cmd",
-				"internal",
-				"pkg",
-				"api",
-				"docs",
-				"scripts",
-				"test",
-			},
-			wantFiles: []string{
-				"main.go",
-				"go.mod",
-				"README.md",
-			},
-			wantErr: false,
-		},
-		{
-			name:        "Web project creation",
-			projectName: "web-project",
-			template:    "go-web",
-			wantDirs: []string{
-				"cmd",
-				"internal",
-				"pkg",
-				"api",
-				"docs",
-				"scripts",
-				"test",
-				"web",
-				"templates",
-				"static",
-			},
-			wantFiles: []string{
-				"main.go",
-				"go.mod",
-				"README.md",
-				"web/handlers.go",
-				"web/middleware.go",
-				"web/routes.go",
-			},
-			wantErr: false,
-		},
-		{
-			name:        "CLI project creation",
-			projectName: "cli-project",
-			template:    "cli",
-			wantDirs: []string{
-				"cmd",
-				"internal",
-				"pkg",
-				"docs",
-			},
-			wantFiles: []string{
-				"main.go",
-				"go.mod",
-				"README.md",
-				"cmd/root.go",
-				"cmd/version.go",
-			},
-			wantErr: false,
-		},
-		{
-			name:        "Invalid template",
-			projectName: "invalid-project",
-			template:    "nonexistent",
-			wantDirs:    []string{},
-			wantFiles:   []string{},
-			wantErr:     true,
-		},
+		{name: "API project creation", template: "api"},
+		{name: "CLI project creation", template: "cli"},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			projectDir := filepath.Join(tempDir, tc.projectName)
-			cmd := exec.Command("go", "run", "../main.go", "init", tc.projectName, "--template", tc.template)
-			cmd.Dir = tempDir
-			output, err := cmd.CombinedOutput()
-
-			if tc.wantErr {
-				if err == nil {
-					t.Errorf("Expected error but got none. Output: %s", string(output))
-				}
-				return
-			} else if err != nil {
-				t.Fatalf("Failed to run command: %v\nOutput: %s", err, string(output))
-			}
-
-			for _, dir := range tc.wantDirs {
-				dirPath := filepath.Join(projectDir, dir)
-				if _, err := os.Stat(dirPath); os.IsNotExist(err) {
-					t.Errorf("Expected directory %s does not exist", dir)
-				}
-			}
-
-			for _, file := range tc.wantFiles {
-				filePath := filepath.Join(projectDir, file)
-				info, err := os.Stat(filePath)
-				if os.IsNotExist(err) {
-					t.Errorf("Expected file %s does not exist", file)
-					continue
-				}
-				if info.Size() == 0 {
-					t.Errorf("File %s exists but is empty", file)
-				}
-			}
-
-			if !tc.wantErr {
-				buildCmd := exec.Command("go", "build", "./...")
-				buildCmd.Dir = projectDir
-				if output, err := buildCmd.CombinedOutput(); err != nil {
-					t.Errorf("Project failed to build: %v\nOutput: %s", err, string(output))
-				}
-			}
+			templatetest.Run(t, tc.template, filepath.Join("testdata", "golden", tc.template))
 		})
 	}
+
+	t.Run("Invalid template", func(t *testing.T) {
+		if _, err := golden.Run(context.Background(), "nonexistent", t.TempDir(), false); err == nil {
+			t.Error("Expected error but got none")
+		}
+	})
 }
@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+func TestLintBuiltinTemplates(t *testing.T) {
+	testCases := []struct {
+		name         string
+		template     string
+		combinations int
+	}{
+		{name: "API template", template: "api", combinations: 16},
+		{name: "CLI template", template: "cli", combinations: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := project.LintTemplate(tc.template)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if report.Combinations != tc.combinations {
+				t.Errorf("Expected %d combinations, got %d", tc.combinations, report.Combinations)
+			}
+			for _, failure := range report.Failures {
+				t.Errorf("%s: %v", failure.Label, failure.Problems)
+			}
+		})
+	}
+}
+
+func TestLintTemplate(t *testing.T) {
+	const manifest = `name: broken
+prompts:
+  - name: UseCache
+    type: confirm
+    message: Use a cache?
+  - name: Store
+    type: select
+    message: Store?
+    options: [memory, disk]
+    when: .UseCache
+files:
+  - source: main.tpl
+    target: main.go
+  - source: compose.tpl
+    target: compose.yml
+  - source: go-mod.tpl
+    target: go.mod
+`
+
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		failures []string
+		problem  string
+	}{
+		{
+			name: "Clean template",
+			files: map[string]string{
+				"main.tpl":    "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"{{.ProjectName}}\")\n}\n",
+				"compose.tpl": "services:\n  app:\n    image: {{.ProjectName}}\n",
+				"go-mod.tpl":  "module {{.ModuleName}}\n\ngo {{.GoVersion}}\n",
+			},
+		},
+		{
+			name: "Missing import in one combination",
+			files: map[string]string{
+				"main.tpl":    "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"{{.ProjectName}}\")\n{{- if eq .Store \"disk\"}}\n\tos.Exit(0)\n{{- end}}\n}\n",
+				"compose.tpl": "services:\n  app:\n    image: {{.ProjectName}}\n",
+				"go-mod.tpl":  "module {{.ModuleName}}\n\ngo {{.GoVersion}}\n",
+			},
+			failures: []string{`UseCache=true Store="disk"`},
+			problem:  `main.go (broken/main.tpl): undefined: os (missing import?)`,
+		},
+		{
+			name: "Missing import",
+			files: map[string]string{
+				"main.tpl":    "package main\n\nfunc main() {\n\tfmt.Println(\"{{.ProjectName}}\")\n}\n",
+				"compose.tpl": "services:\n  app:\n    image: {{.ProjectName}}\n",
+				"go-mod.tpl":  "module {{.ModuleName}}\n\ngo {{.GoVersion}}\n",
+			},
+			failures: []string{`UseCache=false Store=""`, `UseCache=true Store="memory"`, `UseCache=true Store="disk"`},
+			problem:  "undefined: fmt (missing import?)",
+		},
+		{
+			name: "Invalid Go in one combination",
+			files: map[string]string{
+				"main.tpl":    "package main\n\nfunc main() {\n{{- if .UseCache}}\n\tcache :=\n{{- end}}\n}\n",
+				"compose.tpl": "services:\n  app:\n    image: {{.ProjectName}}\n",
+				"go-mod.tpl":  "module {{.ModuleName}}\n\ngo {{.GoVersion}}\n",
+			},
+			failures: []string{`UseCache=true Store="memory"`, `UseCache=true Store="disk"`},
+			problem:  "template broken/main.tpl: generated main.go does not parse: line 5:",
+		},
+		{
+			name: "Empty YAML block",
+			files: map[string]string{
+				"main.tpl":    "package main\n\nfunc main() {}\n",
+				"compose.tpl": "services:\n{{- if .UseCache}}\n  cache:\n    image: redis\n{{- end}}\n",
+				"go-mod.tpl":  "module {{.ModuleName}}\n\ngo {{.GoVersion}}\n",
+			},
+			failures: []string{`UseCache=false Store=""`},
+			problem:  "compose.yml (broken/compose.tpl): line 1: services is empty",
+		},
+		{
+			name: "Invalid go.mod",
+			files: map[string]string{
+				"main.tpl":    "package main\n\nfunc main() {}\n",
+				"compose.tpl": "services:\n  app:\n    image: {{.ProjectName}}\n",
+				"go-mod.tpl":  "go {{.GoVersion}}\n",
+			},
+			failures: []string{`UseCache=false Store=""`, `UseCache=true Store="memory"`, `UseCache=true Store="disk"`},
+			problem:  "go.mod (broken/go-mod.tpl): missing module directive",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTemplate(t, dir, "template.yaml", manifest)
+			for name, content := range tc.files {
+				writeTemplate(t, dir, name, content)
+			}
+
+			report, err := project.LintTemplateFS(templates.MountFS("broken", os.DirFS(dir)), "broken")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if report.Combinations != 3 {
+				t.Errorf("Expected 3 combinations, got %d", report.Combinations)
+			}
+
+			if len(report.Failures) != len(tc.failures) {
+				t.Fatalf("Expected %d failing combinations, got %+v", len(tc.failures), report.Failures)
+			}
+			for i, failure := range report.Failures {
+				if failure.Label != tc.failures[i] {
+					t.Errorf("Expected combination %s to fail, got %s", tc.failures[i], failure.Label)
+				}
+				found := false
+				for _, problem := range failure.Problems {
+					if strings.Contains(problem.String(), tc.problem) {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("Expected problem %q for %s, got %v", tc.problem, failure.Label, failure.Problems)
+				}
+			}
+		})
+	}
+}
+
+func TestLintOverlappingFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "template.yaml", `name: store
+prompts:
+  - name: UsePostgres
+    type: confirm
+    message: Postgres?
+  - name: UseRedis
+    type: confirm
+    message: Redis?
+files:
+  - source: postgres.tpl
+    target: store.txt
+    when: .UsePostgres
+  - source: redis.tpl
+    target: store.txt
+    when: .UseRedis
+`)
+	writeTemplate(t, dir, "postgres.tpl", "postgres")
+	writeTemplate(t, dir, "redis.tpl", "redis")
+
+	report, err := project.LintTemplateFS(templates.MountFS("store", os.DirFS(dir)), "store")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.Failures) != 1 || report.Failures[0].Label != "UsePostgres=true UseRedis=true" {
+		t.Fatalf("Expected only UsePostgres=true UseRedis=true to fail, got %+v", report.Failures)
+	}
+	problem := report.Failures[0].Problems[0].String()
+	if !strings.Contains(problem, "source postgres.tpl") || !strings.Contains(problem, "source redis.tpl") {
+		t.Errorf("Expected the problem to name both files, got %q", problem)
+	}
+}
+
+func TestLintTemplateDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, filepath.Join(dir, "api"), "template.yaml", "name: api\nfiles:\n  - source: main.tpl\n    target: main.go\n")
+	writeTemplate(t, filepath.Join(dir, "api"), "main.tpl", "package main\n\nfunc main() {\n\tfmt.Println()\n}\n")
+
+	templates.SetTemplateDirs([]string{dir})
+	defer templates.SetTemplateDirs(nil)
+
+	report, err := project.LintTemplate("api")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.Failures) != 1 || report.Failures[0].Label != "(no prompts)" {
+		t.Errorf("Expected the overlay template to fail linting, got %+v", report.Failures)
+	}
+}
@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/internal/remote"
+	"github.com/go-sova/sova-cli/pkg/generator"
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+func TestProjectLock(t *testing.T) {
+	values := map[string]interface{}{"UseZap": true, "UsePostgres": true, "UseRedis": false, "UseRabbitMQ": false}
+	answers := &questions.ProjectAnswers{ProjectType: "api", ModulePath: "example.com/shop", GoVersion: "1.22", License: "MIT", Values: values}
+	plan := planProject(t, "shop", answers)
+	projectDir := filepath.Join(t.TempDir(), "shop")
+	if err := plan.Generate(context.Background(), projectDir, nil); err != nil {
+		t.Fatalf("Failed to generate project: %v", err)
+	}
+
+	lock, err := project.ReadLock(projectDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if lock.Template.Name != "api" || lock.Template.Version == "" || lock.Template.Source != project.SourceEmbedded || lock.SovaVersion == "" {
+		t.Errorf("Unexpected lock metadata: %+v %q", lock.Template, lock.SovaVersion)
+	}
+	if lock.Project.Name != "shop" || lock.Project.Module != "example.com/shop" || lock.Project.GoVersion != "1.22" {
+		t.Errorf("Unexpected project in lock: %+v", lock.Project)
+	}
+	if len(lock.Files) != len(plan.Files) {
+		t.Errorf("Expected %d files in the lock, got %d", len(plan.Files), len(lock.Files))
+	}
+	for _, file := range plan.Files {
+		if lock.Files[file.Path] != project.HashContent(file.Content) {
+			t.Errorf("Expected the hash of %s to be recorded", file.Path)
+		}
+	}
+
+	locked, err := lock.ProjectAnswers()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(locked.Values, values) || locked.ModulePath != "example.com/shop" || locked.License != "MIT" {
+		t.Errorf("Expected the answers the project was generated with, got %+v", locked)
+	}
+
+	testCases := []struct {
+		name   string
+		change func(t *testing.T)
+		want   []project.FileStatus
+	}{
+		{
+			name:   "Unchanged project",
+			change: func(t *testing.T) {},
+		},
+		{
+			name: "Modified and deleted files",
+			change: func(t *testing.T) {
+				writeTemplate(t, projectDir, ".env", "PORT=9090\n")
+				if err := os.Remove(filepath.Join(projectDir, "Dockerfile")); err != nil {
+					t.Fatalf("Failed to remove Dockerfile: %v", err)
+				}
+				writeTemplate(t, projectDir, "internal/handlers/users.go", "package handlers\n")
+			},
+			want: []project.FileStatus{
+				{Path: ".env", State: project.FileModified},
+				{Path: "Dockerfile", State: project.FileDeleted},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.change(t)
+			statuses, err := lock.Check(projectDir)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(statuses, tc.want) {
+				t.Errorf("Expected %v, got %v", tc.want, statuses)
+			}
+		})
+	}
+
+	t.Run("Missing lock", func(t *testing.T) {
+		if _, err := project.ReadLock(t.TempDir()); !os.IsNotExist(err) {
+			t.Errorf("Expected a not-exist error, got %v", err)
+		}
+	})
+}
+
+func TestProjectLockTemplateSource(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"template.yaml": "name: svc\nversion: 1.2.0\nfiles:\n  - source: main.tpl\n    target: main.go\n",
+		"main.tpl":      "package main\n",
+	}
+	archive := filepath.Join(dir, "svc.tar.gz")
+	if err := os.WriteFile(archive, tarGz(t, files), 0644); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	for name, content := range files {
+		writeTemplate(t, filepath.Join(dir, "svc"), name, content)
+	}
+	defer templates.SetTemplateDirs(nil)
+
+	testCases := []struct {
+		name         string
+		ref          string
+		wantRevision bool
+	}{
+		{name: "Archive", ref: archive, wantRevision: true},
+		{name: "Directory", ref: filepath.Join(dir, "svc")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source, err := remote.Parse(tc.ref)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			fetched, err := source.Fetch(context.Background(), t.TempDir())
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, err := templates.AddTemplateSource(fetched.Dir, source.Name()); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			projectDir := filepath.Join(t.TempDir(), "demo")
+			_, err = generator.Generate(context.Background(), generator.Spec{
+				ProjectName:      "demo",
+				Answers:          &questions.ProjectAnswers{ProjectType: "svc", ModulePath: "example.com/demo"},
+				TemplateSource:   tc.ref,
+				TemplateRevision: fetched.Revision,
+				Sink:             &generator.DirSink{Dir: projectDir},
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			lock, err := project.ReadLock(projectDir)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			want := project.LockTemplate{Name: "svc", Version: "1.2.0", Source: project.SourceLocal, Location: tc.ref, Revision: fetched.Revision}
+			if lock.Template != want {
+				t.Errorf("Expected template %+v in the lock, got %+v", want, lock.Template)
+			}
+			if tc.wantRevision && lock.Template.Revision == "" {
+				t.Error("Expected the revision of the archive in the lock")
+			}
+
+			base := planProject(t, "demo", &questions.ProjectAnswers{ProjectType: "svc", ModulePath: "example.com/demo"})
+			base.Lock = lock
+			plan := planProject(t, "demo", &questions.ProjectAnswers{ProjectType: "svc", ModulePath: "example.com/demo"})
+			changes, err := plan.Update(context.Background(), projectDir, base)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := plan.RecordUpdate(projectDir, base, changes); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			updated, err := project.ReadLock(projectDir)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if updated.Template != want {
+				t.Errorf("Expected the update to keep template %+v in the lock, got %+v", want, updated.Template)
+			}
+		})
+	}
+}
+
+func TestPlanRecordUpdate(t *testing.T) {
+	newPlan := func(files map[string]string) *project.Plan {
+		plan := &project.Plan{ProjectName: "demo", Lock: &project.Lock{Template: project.LockTemplate{Name: "demo"}, Files: map[string]string{}}}
+		for name, content := range files {
+			plan.AddFile(name, name+".tpl", []byte(content))
+		}
+		return plan
+	}
+
+	base := newPlan(map[string]string{"main.go": "package main\n", "app.env": "DEBUG=true\n", "notes.md": "notes\n"})
+	dir := t.TempDir()
+	if err := base.Generate(context.Background(), filepath.Join(dir, "demo"), nil); err != nil {
+		t.Fatalf("Failed to generate project: %v", err)
+	}
+	dir = filepath.Join(dir, "demo")
+
+	// Files rewritten by a hook after generation still count as generated
+	writeTemplate(t, dir, "main.go", "package main\n\n// formatted\n")
+	if err := base.Lock.HashFiles(dir, []string{"main.go"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	writeTemplate(t, dir, "notes.md", "my notes\n")
+
+	next := newPlan(map[string]string{"main.go": "package main\n\nfunc main() {}\n", "app.env": "DEBUG=true\n", "notes.md": "notes\nmore\n"})
+	changes, err := next.Update(context.Background(), dir, base)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []project.FileChange{
+		{Path: "main.go", Action: project.FileUpdated},
+		{Path: "notes.md", Action: project.FileConflict, NewPath: "notes.md" + project.NewFileSuffix},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Expected %v, got %v", want, changes)
+	}
+
+	if err := next.RecordUpdate(dir, base, changes); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lock, err := project.ReadLock(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	statuses, err := lock.Check(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Path != "notes.md" || statuses[0].State != project.FileModified {
+		t.Errorf("Expected only notes.md to be modified, got %v", statuses)
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, project.LockFile))
+	if !strings.HasPrefix(string(content), "# Generated by sova") {
+		t.Errorf("Expected the lock to start with a header, got:\n%s", content)
+	}
+}
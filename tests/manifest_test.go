@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/templates"
+)
+
+func TestBuiltinManifests(t *testing.T) {
+	for _, projectType := range []string{"api", "cli"} {
+		t.Run(projectType, func(t *testing.T) {
+			manifest, err := templates.LoadManifestFS(templates.TemplateFS, projectType)
+			if err != nil {
+				t.Fatalf("Failed to load manifest: %v", err)
+			}
+			if err := manifest.Validate(templates.TemplateFS, projectType); err != nil {
+				t.Errorf("Manifest is invalid: %v", err)
+			}
+			if manifest.Version == "" || manifest.Description == "" {
+				t.Errorf("Manifest is missing version or description: %+v", manifest)
+			}
+		})
+	}
+}
+
+func TestManifestResolve(t *testing.T) {
+	manifest, err := templates.ParseManifest([]byte(`
+name: svc
+version: 0.1.0
+directories: [cmd]
+files:
+  - source: main.tpl
+    target: cmd/main.go
+  - source: postgres.tpl
+    target: internal/postgres.go
+    when: .UsePostgres
+  - source: cache.tpl
+    target: internal/cache.go
+    when: and .UsePostgres (not .UseRedis)
+dependencies:
+  - name: github.com/lib/pq
+    version: v1.10.9
+    when: .UsePostgres
+  - name: github.com/gin-gonic/gin
+    version: v1.9.1
+hooks:
+  post-generate:
+    - command: go mod tidy
+      when: .UsePostgres
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+
+	testCases := []struct {
+		name      string
+		data      map[string]interface{}
+		wantFiles []string
+		wantDeps  []string
+		wantHooks int
+	}{
+		{
+			name:      "Nothing enabled",
+			data:      map[string]interface{}{},
+			wantFiles: []string{"cmd/main.go"},
+			wantDeps:  []string{"github.com/gin-gonic/gin"},
+		},
+		{
+			name:      "Postgres only",
+			data:      map[string]interface{}{"UsePostgres": true},
+			wantFiles: []string{"cmd/main.go", "internal/cache.go", "internal/postgres.go"},
+			wantDeps:  []string{"github.com/gin-gonic/gin", "github.com/lib/pq"},
+			wantHooks: 1,
+		},
+		{
+			name:      "Postgres and Redis",
+			data:      map[string]interface{}{"UsePostgres": true, "UseRedis": true},
+			wantFiles: []string{"cmd/main.go", "internal/postgres.go"},
+			wantDeps:  []string{"github.com/gin-gonic/gin", "github.com/lib/pq"},
+			wantHooks: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved, err := manifest.Resolve("svc", tc.data)
+			if err != nil {
+				t.Fatalf("Failed to resolve manifest: %v", err)
+			}
+
+			if len(resolved.Files) != len(tc.wantFiles) {
+				t.Errorf("Files mismatch. Want %v, got %v", tc.wantFiles, resolved.Files)
+			}
+			for _, file := range tc.wantFiles {
+				if _, ok := resolved.Files[file]; !ok {
+					t.Errorf("Expected file %s, got %v", file, resolved.Files)
+				}
+			}
+
+			if len(resolved.Dependencies) != len(tc.wantDeps) {
+				t.Fatalf("Dependencies mismatch. Want %v, got %v", tc.wantDeps, resolved.Dependencies)
+			}
+			for i, dep := range tc.wantDeps {
+				if resolved.Dependencies[i].Name != dep {
+					t.Errorf("Dependency %d mismatch. Want %s, got %s", i, dep, resolved.Dependencies[i].Name)
+				}
+			}
+
+			if len(resolved.Hooks.PostGenerate) != tc.wantHooks {
+				t.Errorf("Hooks mismatch. Want %d, got %d", tc.wantHooks, len(resolved.Hooks.PostGenerate))
+			}
+		})
+	}
+}
+
+func TestManifestResolveOverlappingFiles(t *testing.T) {
+	manifest, err := templates.ParseManifest([]byte(`
+name: svc
+files:
+  - source: postgres.tpl
+    target: internal/store.go
+    when: .UsePostgres
+  - source: redis.tpl
+    target: internal/store.go
+    when: .UseRedis
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+
+	resolved, err := manifest.Resolve("svc", map[string]interface{}{"UseRedis": true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resolved.Files["internal/store.go"] != "svc/redis.tpl" {
+		t.Errorf("Expected internal/store.go from svc/redis.tpl, got %v", resolved.Files)
+	}
+
+	_, err = manifest.Resolve("svc", map[string]interface{}{"UsePostgres": true, "UseRedis": true})
+	if err == nil {
+		t.Fatal("Expected error but got none")
+	}
+	for _, want := range []string{"internal/store.go", "files[0] (source postgres.tpl, when .UsePostgres)", "files[1] (source redis.tpl, when .UseRedis)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestInvalidManifest(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "Unknown key", content: "name: x\nfile: []\n"},
+		{name: "Missing target", content: "files:\n  - source: main.tpl\n"},
+		{name: "Escaping target", content: "files:\n  - source: main.tpl\n    target: ../main.go\n"},
+		{name: "Bad condition", content: "files:\n  - source: main.tpl\n    target: main.go\n    when: .UseZap }}\n"},
+		{name: "Dependency without version", content: "dependencies:\n  - name: github.com/lib/pq\n"},
+		{name: "Bad function", content: "funcs:\n  table: '{{ . | snake'\n"},
+		{name: "Unknown function in function", content: "funcs:\n  table: '{{ . | tableize }}'\n"},
+		{name: "Bad function name", content: "funcs:\n  table-name: '{{ . }}'\n"},
+		{name: "Duplicate target", content: "files:\n  - source: a.tpl\n    target: main.go\n  - source: b.tpl\n    target: main.go\n"},
+		{name: "Duplicate target with a condition", content: "files:\n  - source: a.tpl\n    target: main.go\n  - source: b.tpl\n    target: main.go\n    when: .UseZap\n"},
+		{name: "Duplicate target with the same condition", content: "files:\n  - source: a.tpl\n    target: main.go\n    when: .UseZap\n  - source: b.tpl\n    target: main.go\n    when: .UseZap\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := templates.ParseManifest([]byte(tc.content)); err == nil {
+				t.Error("Expected error but got none")
+			}
+		})
+	}
+}
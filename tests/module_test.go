@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/project"
+)
+
+func TestModulePathFromRemote(t *testing.T) {
+	testCases := []struct {
+		name   string
+		remote string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "HTTPS remote",
+			remote: "https://github.com/acme/my-api.git",
+			want:   "github.com/acme/my-api",
+			wantOK: true,
+		},
+		{
+			name:   "SCP-like remote",
+			remote: "git@github.com:acme/my-api.git",
+			want:   "github.com/acme/my-api",
+			wantOK: true,
+		},
+		{
+			name:   "SSH remote with port",
+			remote: "ssh://git@gitlab.example.com:2222/platform/services/billing",
+			want:   "gitlab.example.com/platform/services/billing",
+			wantOK: true,
+		},
+		{
+			name:   "Local path",
+			remote: "/srv/git/my-api.git",
+			wantOK: false,
+		},
+		{
+			name:   "Empty remote",
+			remote: "",
+			wantOK: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := project.ModulePathFromRemote(tc.remote)
+			if ok != tc.wantOK {
+				t.Fatalf("ok mismatch for %q. Want %v, got %v", tc.remote, tc.wantOK, ok)
+			}
+			if got != tc.want {
+				t.Errorf("Module path mismatch for %q. Want %q, got %q", tc.remote, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateModulePath(t *testing.T) {
+	testCases := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "Domain path", path: "github.com/acme/my-api", wantErr: false},
+		{name: "Bare name", path: "my-api", wantErr: false},
+		{name: "Major version suffix", path: "github.com/acme/my-api/v2", wantErr: false},
+		{name: "Space", path: "my api", wantErr: true},
+		{name: "Leading slash", path: "/my-api", wantErr: true},
+		{name: "Empty", path: "", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := project.ValidateModulePath(tc.path)
+			if tc.wantErr && err == nil {
+				t.Errorf("Expected error for %q but got none", tc.path)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("Unexpected error for %q: %v", tc.path, err)
+			}
+		})
+	}
+}
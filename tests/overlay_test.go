@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-sova/sova-cli/templates"
+)
+
+func TestOverlayFS(t *testing.T) {
+	custom := fstest.MapFS{
+		"api/routes.tpl":         {Data: []byte("custom routes")},
+		"worker/cmd/main.go.tpl": {Data: []byte("package main")},
+	}
+	builtin := fstest.MapFS{
+		"api/routes.tpl": {Data: []byte("builtin routes")},
+		"api/main.tpl":   {Data: []byte("builtin main")},
+		"cli/root.tpl":   {Data: []byte("builtin root")},
+	}
+	overlay := templates.NewOverlayFS(custom, builtin)
+
+	t.Run("Override", func(t *testing.T) {
+		content, err := fs.ReadFile(overlay, "api/routes.tpl")
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		if string(content) != "custom routes" {
+			t.Errorf("Content mismatch. Want %q, got %q", "custom routes", content)
+		}
+	})
+
+	t.Run("Fallthrough", func(t *testing.T) {
+		content, err := fs.ReadFile(overlay, "api/main.tpl")
+		if err != nil {
+			t.Fatalf("Failed to read file: %v", err)
+		}
+		if string(content) != "builtin main" {
+			t.Errorf("Content mismatch. Want %q, got %q", "builtin main", content)
+		}
+	})
+
+	t.Run("Merged listing", func(t *testing.T) {
+		entries, err := fs.ReadDir(overlay, ".")
+		if err != nil {
+			t.Fatalf("Failed to read directory: %v", err)
+		}
+
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		want := []string{"api", "cli", "worker"}
+		if len(names) != len(want) {
+			t.Fatalf("Listing mismatch. Want %v, got %v", want, names)
+		}
+		for i := range want {
+			if names[i] != want[i] {
+				t.Errorf("Listing mismatch. Want %v, got %v", want, names)
+			}
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		if _, err := fs.ReadFile(overlay, "api/missing.tpl"); err == nil {
+			t.Error("Expected error but got none")
+		}
+	})
+}
+
+func TestTemplateDirs(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "worker/go.mod.tpl", "module {{.ModuleName}}\n")
+
+	templates.SetTemplateDirs([]string{dir})
+	defer templates.SetTemplateDirs(nil)
+
+	types, err := templates.ProjectTypes()
+	if err != nil {
+		t.Fatalf("Failed to list project types: %v", err)
+	}
+	found := false
+	for _, projectType := range types {
+		if projectType == "worker" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected worker in project types, got %v", types)
+	}
+
+	files, err := templates.TemplateFiles("worker")
+	if err != nil {
+		t.Fatalf("Failed to list template files: %v", err)
+	}
+	if len(files) != 1 || files[0] != "go.mod.tpl" {
+		t.Errorf("Template files mismatch. Want [go.mod.tpl], got %v", files)
+	}
+}
+
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write template %s: %v", name, err)
+	}
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+func TestTemplatePartials(t *testing.T) {
+	shared := map[string]string{
+		"_partials/header.tpl": `{{define "header"}}# {{.ProjectName}}{{end}}{{define "footer"}}shared footer{{end}}`,
+		"_partials/notes.txt":  `{{define "header"}}not a partial{{end}}`,
+	}
+
+	testCases := []struct {
+		name        string
+		files       map[string]string
+		expected    string
+		expectError string
+	}{
+		{
+			name:     "Shared partial",
+			files:    map[string]string{"svc/main.tpl": `{{template "header" .}} / {{template "footer" .}}`},
+			expected: "# demo / shared footer",
+		},
+		{
+			name: "Project type partial overrides shared one",
+			files: map[string]string{
+				"svc/_partials/footer.tpl": `{{define "footer"}}svc footer{{end}}`,
+				"svc/main.tpl":             `{{template "header" .}} / {{template "footer" .}}`,
+			},
+			expected: "# demo / svc footer",
+		},
+		{
+			name:     "Template overrides partial",
+			files:    map[string]string{"svc/main.tpl": `{{define "footer"}}own footer{{end}}{{template "header" .}} / {{template "footer" .}}`},
+			expected: "# demo / own footer",
+		},
+		{
+			name: "Partials of other project types are not used",
+			files: map[string]string{
+				"other/_partials/footer.tpl": `{{define "footer"}}other footer{{end}}`,
+				"svc/main.tpl":               `{{template "footer" .}}`,
+			},
+			expected: "shared footer",
+		},
+		{
+			name: "Broken partial",
+			files: map[string]string{
+				"svc/_partials/broken.tpl": `{{define "broken"}}{{.ProjectName}`,
+				"svc/main.tpl":             `{{template "header" .}}`,
+			},
+			expectError: "svc/_partials/broken.tpl",
+		},
+		{
+			name:        "Unknown partial",
+			files:       map[string]string{"svc/main.tpl": `{{template "missing" .}}`},
+			expectError: "missing",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fsys := fstest.MapFS{}
+			for name, content := range shared {
+				fsys[name] = &fstest.MapFile{Data: []byte(content)}
+			}
+			for name, content := range tc.files {
+				fsys[name] = &fstest.MapFile{Data: []byte(content)}
+			}
+
+			generator := templates.NewFileGenerator(templates.NewTemplateLoaderFS(fsys))
+			content, err := generator.Render("svc/main.tpl", map[string]interface{}{"ProjectName": "demo"})
+			if tc.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectError) {
+					t.Errorf("Expected error containing %q, got %v", tc.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(content) != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, content)
+			}
+		})
+	}
+
+	t.Run("Partials are not rendered by convention", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"svc/main.tpl":             {Data: []byte(`{{template "footer" .}}`)},
+			"svc/_partials/footer.tpl": {Data: []byte(`{{define "footer"}}footer{{end}}`)},
+		}
+		manifest, err := templates.LoadManifestFS(fsys, "svc")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(manifest.Files) != 1 || manifest.Files[0].Target != "main" {
+			t.Errorf("Expected only main to be rendered, got %+v", manifest.Files)
+		}
+	})
+}
+
+func TestBuiltinPartials(t *testing.T) {
+	types, err := templates.ProjectTypes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, projectType := range types {
+		if projectType == templates.PartialsDir {
+			t.Errorf("Did not expect %s to be a project type", templates.PartialsDir)
+		}
+	}
+
+	for _, projectType := range []string{"api", "cli"} {
+		t.Run(projectType, func(t *testing.T) {
+			answers := &questions.ProjectAnswers{ProjectType: projectType, Author: "Jane Doe", License: "MIT", Values: map[string]interface{}{}}
+			plan := planProject(t, "demo", answers)
+
+			main := "main.go"
+			if projectType == "api" {
+				main = "cmd/main.go"
+			}
+			file, ok := plan.File(main)
+			if !ok {
+				t.Fatalf("Expected %s in the plan", main)
+			}
+			header := "// Copyright (c) Jane Doe.\n// Licensed under the MIT License. See LICENSE for details.\n\npackage main\n"
+			if !strings.HasPrefix(string(file.Content), header) {
+				t.Errorf("Expected %s to start with the license header, got %q", main, file.Content)
+			}
+
+			gitignore, ok := plan.File(".gitignore")
+			if !ok {
+				t.Fatal("Expected .gitignore in the plan")
+			}
+			for _, line := range []string{"\ndemo\n", "\n.idea/\n", "\n.env\n", "\ntemp/\n"} {
+				if !strings.Contains(string(gitignore.Content), line) {
+					t.Errorf("Expected .gitignore to contain %q", strings.TrimSpace(line))
+				}
+			}
+		})
+	}
+}
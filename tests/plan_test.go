@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/pkg/questions"
+)
+
+func TestDryRunPlan(t *testing.T) {
+	tempDir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	t.Run("API plan", func(t *testing.T) {
+		answers := &questions.ProjectAnswers{ProjectType: "api", Values: map[string]interface{}{"UsePostgres": true}}
+		plan := planProject(t, "plan-api", answers)
+
+		file, ok := plan.File("internal/service/postgres.go")
+		if !ok {
+			t.Fatal("Expected internal/service/postgres.go in plan")
+		}
+		if file.Template != "api/postgres.tpl" || len(file.Content) == 0 {
+			t.Errorf("Unexpected planned file: %s rendered from %s (%d bytes)", file.Path, file.Template, len(file.Content))
+		}
+		if _, ok := plan.File("internal/service/redis.go"); ok {
+			t.Error("Did not expect internal/service/redis.go in plan")
+		}
+
+		var out bytes.Buffer
+		plan.Print(&out)
+		if !strings.Contains(out.String(), "postgres.go  (api/postgres.tpl,") {
+			t.Errorf("Plan output does not list postgres.go:\n%s", out.String())
+		}
+	})
+
+	t.Run("CLI plan", func(t *testing.T) {
+		plan := planProject(t, "plan-cli", &questions.ProjectAnswers{ProjectType: "cli"})
+
+		file, ok := plan.File("cmd/root.go")
+		if !ok {
+			t.Fatal("Expected cmd/root.go in plan")
+		}
+		if !bytes.Contains(file.Content, []byte(`Use:   "plan-cli"`)) {
+			t.Errorf("Rendered root.go does not use the project name:\n%s", file.Content)
+		}
+	})
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to read temp directory: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Dry run wrote %d entries to disk", len(entries))
+	}
+}
+
+func TestPlanWriteIsAtomic(t *testing.T) {
+	tempDir := t.TempDir()
+
+	newPlan := func() *project.Plan {
+		plan := &project.Plan{ProjectName: "atomic", Directories: []string{"cmd", "docs"}}
+		plan.AddFile("cmd/main.go", "api/main.tpl", []byte("package main\n"))
+		plan.AddFile("go.mod", "api/go-mod.tpl", []byte("module atomic\n"))
+		return plan
+	}
+
+	t.Run("Successful write", func(t *testing.T) {
+		projectDir := filepath.Join(tempDir, "ok")
+		if err := newPlan().Write(context.Background(), projectDir); err != nil {
+			t.Fatalf("Failed to write plan: %v", err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(projectDir, "cmd", "main.go"))
+		if err != nil {
+			t.Fatalf("Failed to read generated file: %v", err)
+		}
+		if string(content) != "package main\n" {
+			t.Errorf("Content mismatch. Want %q, got %q", "package main\n", content)
+		}
+		if info, err := os.Stat(filepath.Join(projectDir, "docs")); err != nil || !info.IsDir() {
+			t.Error("Expected empty directory docs to be created")
+		}
+	})
+
+	t.Run("Existing directory", func(t *testing.T) {
+		projectDir := filepath.Join(tempDir, "ok")
+		if err := newPlan().Write(context.Background(), projectDir); err == nil {
+			t.Error("Expected error for existing directory but got none")
+		}
+	})
+
+	t.Run("Cancelled write", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		projectDir := filepath.Join(tempDir, "cancelled")
+		if err := newPlan().Write(ctx, projectDir); err == nil {
+			t.Fatal("Expected error for cancelled context but got none")
+		}
+	})
+
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to read temp directory: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.Name() != "ok" {
+			t.Errorf("Unexpected leftover entry: %s", entry.Name())
+		}
+	}
+}
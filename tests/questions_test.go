@@ -0,0 +1,329 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+func apiValues(zap, postgres, redis, rabbitmq bool) map[string]interface{} {
+	return map[string]interface{}{"UseZap": zap, "UsePostgres": postgres, "UseRedis": redis, "UseRabbitMQ": rabbitmq}
+}
+
+func TestPresetAnswers(t *testing.T) {
+	testCases := []struct {
+		name        string
+		projectType string
+		with        []string
+		useDefaults bool
+		want        questions.ProjectAnswers
+		wantErr     bool
+	}{
+		{
+			name:        "API with selected components",
+			projectType: "api",
+			with:        []string{"postgres", "redis"},
+			want:        questions.ProjectAnswers{ProjectType: "api", Values: apiValues(false, true, true, false)},
+		},
+		{
+			name:        "API defaults",
+			projectType: "api",
+			useDefaults: true,
+			want:        questions.ProjectAnswers{ProjectType: "api", Values: apiValues(true, true, false, false)},
+		},
+		{
+			name:        "CLI defaults",
+			projectType: "cli",
+			useDefaults: true,
+			want:        questions.ProjectAnswers{ProjectType: "cli", Values: map[string]interface{}{"UseZap": false}},
+		},
+		{
+			name:        "Unknown component",
+			projectType: "cli",
+			with:        []string{"postgres"},
+			wantErr:     true,
+		},
+		{
+			name:        "Unknown project type",
+			projectType: "web",
+			useDefaults: true,
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			preset := &questions.Preset{}
+			var err error
+			if tc.with != nil {
+				err = preset.With(tc.projectType, tc.with)
+			}
+			if err == nil && tc.useDefaults {
+				err = preset.ApplyDefaults(tc.projectType)
+			}
+
+			var answers *questions.ProjectAnswers
+			if err == nil {
+				answers, err = questions.AskProjectQuestionsWithPreset(tc.projectType, preset)
+			}
+
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*answers, tc.want) {
+				t.Errorf("Answers mismatch. Want %+v, got %+v", tc.want, *answers)
+			}
+		})
+	}
+}
+
+func TestLoadPreset(t *testing.T) {
+	tempDir := t.TempDir()
+
+	testCases := []struct {
+		name     string
+		fileName string
+		content  string
+		want     questions.ProjectAnswers
+		wantErr  bool
+	}{
+		{
+			name:     "YAML answers file",
+			fileName: "answers.yaml",
+			content: `name: my-api
+type: api
+module: github.com/acme/my-api
+author: Acme
+license: Apache-2.0
+components:
+  zap: false
+  postgres: true
+  redis: true
+  rabbitmq: false
+`,
+			want: questions.ProjectAnswers{
+				ProjectType: "api",
+				ModulePath:  "github.com/acme/my-api",
+				Author:      "Acme",
+				License:     "Apache-2.0",
+				Values:      apiValues(false, true, true, false),
+			},
+		},
+		{
+			name:     "JSON answers file",
+			fileName: "answers.json",
+			content:  `{"name": "my-cli", "type": "cli", "components": {"zap": true}}`,
+			want:     questions.ProjectAnswers{ProjectType: "cli", Values: map[string]interface{}{"UseZap": true}},
+		},
+		{
+			name:     "Values by name and alias",
+			fileName: "values.yaml",
+			content:  "type: api\nvalues:\n  UseZap: false\n  postgres: \"false\"\n  redis: true\n  rabbitmq: false\n",
+			want:     questions.ProjectAnswers{ProjectType: "api", Values: apiValues(false, false, true, false)},
+		},
+		{
+			name:     "Unknown value",
+			fileName: "unknown-value.yaml",
+			content:  "type: api\nvalues:\n  port: 8080\n",
+			wantErr:  true,
+		},
+		{
+			name:     "Unknown key",
+			fileName: "typo.yaml",
+			content:  "type: api\ncomponent:\n  zap: true\n",
+			wantErr:  true,
+		},
+		{
+			name:     "Component of another project type",
+			fileName: "mismatch.yaml",
+			content:  "type: cli\ncomponents:\n  postgres: true\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(tempDir, tc.fileName)
+			if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("Failed to write answers file: %v", err)
+			}
+
+			preset, err := questions.LoadPreset(path)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			answers, err := questions.AskProjectQuestionsWithPreset(preset.ProjectType, preset)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*answers, tc.want) {
+				t.Errorf("Answers mismatch. Want %+v, got %+v", tc.want, *answers)
+			}
+		})
+	}
+}
+
+func TestTemplatePrompts(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "service/main.tpl", "package main\n")
+	writeTemplate(t, dir, "service/template.yaml", `name: service
+prompts:
+  - name: Port
+    type: input
+    message: Which port should the service listen on?
+    default: "8080"
+    validate: ^[0-9]+$
+  - name: Database
+    type: select
+    options: [none, postgres, mysql]
+    default: none
+  - name: Migrations
+    alias: migrations
+    type: confirm
+    default: true
+    when: ne .Database "none"
+  - name: Features
+    type: multiselect
+    options: [auth, metrics, tracing]
+    default: [metrics]
+files:
+  - source: main.tpl
+    target: main.go
+`)
+	templates.SetTemplateDirs([]string{dir})
+	defer templates.SetTemplateDirs(nil)
+
+	testCases := []struct {
+		name    string
+		values  map[string]interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "Defaults",
+			want: map[string]interface{}{"Port": "8080", "Database": "none", "Migrations": false, "Features": []string{"metrics"}},
+		},
+		{
+			name:   "Preset values",
+			values: map[string]interface{}{"Port": "9000", "Database": "postgres", "Features": "auth,tracing"},
+			want:   map[string]interface{}{"Port": "9000", "Database": "postgres", "Migrations": true, "Features": []string{"auth", "tracing"}},
+		},
+		{
+			name:    "Input not matching pattern",
+			values:  map[string]interface{}{"Port": "http"},
+			wantErr: true,
+		},
+		{
+			name:    "Option not offered",
+			values:  map[string]interface{}{"Database": "oracle"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			preset := &questions.Preset{}
+			for name, value := range tc.values {
+				preset.Set(name, value)
+			}
+			if err := preset.ApplyDefaults("service"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			answers, err := questions.AskProjectQuestionsWithPreset("service", preset)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(answers.Values, tc.want) {
+				t.Errorf("Values mismatch. Want %v, got %v", tc.want, answers.Values)
+			}
+		})
+	}
+
+	components, err := questions.Components("service")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(components, []string{"migrations"}) {
+		t.Errorf("Expected components [migrations], got %v", components)
+	}
+}
+
+func TestPresetFromAnswers(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "service/main.tpl", "package main\n")
+	writeTemplate(t, dir, "service/template.yaml", `name: service
+prompts:
+  - name: UseCache
+    alias: cache
+    type: confirm
+  - name: Store
+    type: select
+    options: [memory, disk]
+    default: disk
+    when: .UseCache
+  - name: UseMetrics
+    alias: metrics
+    type: confirm
+files:
+  - source: main.tpl
+    target: main.go
+`)
+	templates.SetTemplateDirs([]string{dir})
+	defer templates.SetTemplateDirs(nil)
+
+	previous := &questions.ProjectAnswers{
+		ProjectType: "service",
+		Values:      map[string]interface{}{"UseCache": false, "Store": "", "UseMetrics": true},
+	}
+
+	prompt, err := questions.Component("service", "cache")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := questions.Component("service", "postgres"); err == nil || !strings.Contains(err.Error(), "available: cache, metrics") {
+		t.Errorf("Expected an error listing the components, got %v", err)
+	}
+
+	preset, err := questions.PresetFromAnswers(previous)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	preset.Set(prompt.Name, true)
+	if err := preset.ApplyDefaults("service"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	answers, err := questions.AskProjectQuestionsWithPreset("service", preset)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[string]interface{}{"UseCache": true, "Store": "disk", "UseMetrics": true}
+	if !reflect.DeepEqual(answers.Values, want) {
+		t.Errorf("Expected %v, got %v", want, answers.Values)
+	}
+}
@@ -0,0 +1,415 @@
+package tests
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/remote"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+func TestParseSource(t *testing.T) {
+	testCases := []struct {
+		name     string
+		source   string
+		kind     string
+		location string
+		ref      string
+		wantErr  bool
+	}{
+		{name: "Git with tag", source: "git+https://github.com/acme/templates.git@v1.2.0", kind: remote.KindGit, location: "https://github.com/acme/templates.git", ref: "v1.2.0"},
+		{name: "Git without ref", source: "git+https://github.com/acme/templates.git", kind: remote.KindGit, location: "https://github.com/acme/templates.git"},
+		{name: "Git over ssh", source: "git+ssh://git@github.com/acme/templates.git", kind: remote.KindGit, location: "ssh://git@github.com/acme/templates.git"},
+		{name: "Git over ssh with ref", source: "git+ssh://git@github.com/acme/templates.git@3f2c1ab", kind: remote.KindGit, location: "ssh://git@github.com/acme/templates.git", ref: "3f2c1ab"},
+		{name: "Local archive", source: "./path/to/template.tar.gz", kind: remote.KindArchive, location: "./path/to/template.tar.gz"},
+		{name: "Remote archive", source: "https://example.com/template.zip", kind: remote.KindArchive, location: "https://example.com/template.zip"},
+		{name: "Directory", source: "./my-template", kind: remote.KindDir, location: "./my-template"},
+		{name: "Git without scheme", source: "git+github.com/acme/templates", wantErr: true},
+		{name: "Unsupported URL", source: "https://example.com/template", wantErr: true},
+		{name: "Git ref that is an option", source: "git+https://github.com/acme/templates.git@--upload-pack=touch", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source, err := remote.Parse(tc.source)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if source.Kind != tc.kind || source.Location != tc.location || source.Ref != tc.ref {
+				t.Errorf("Expected %s %s @ %q, got %s %s @ %q", tc.kind, tc.location, tc.ref, source.Kind, source.Location, source.Ref)
+			}
+			if source.String() != tc.source {
+				t.Errorf("Expected String() %s, got %s", tc.source, source.String())
+			}
+		})
+	}
+}
+
+func TestFetchGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	tempDir := t.TempDir()
+	work := filepath.Join(tempDir, "work")
+	writeTemplate(t, work, "template.yaml", "name: svc\nfiles:\n  - source: main.tpl\n    target: main.go\n")
+	writeTemplate(t, work, "main.tpl", "package main // v1\n")
+
+	runGit(t, work, "init", "--quiet")
+	runGit(t, work, "add", "-A")
+	runGit(t, work, "commit", "--quiet", "-m", "v1")
+	runGit(t, work, "tag", "v1.0.0")
+	firstCommit := runGit(t, work, "rev-parse", "HEAD")
+
+	writeTemplate(t, work, "main.tpl", "package main // v2\n")
+	runGit(t, work, "commit", "--quiet", "-am", "v2")
+	runGit(t, work, "branch", "stable", firstCommit)
+
+	bare := filepath.Join(tempDir, "svc.git")
+	runGit(t, tempDir, "clone", "--quiet", "--bare", work, bare)
+	repoURL := "file://" + filepath.ToSlash(bare)
+	cacheDir := filepath.Join(tempDir, "cache")
+
+	testCases := []struct {
+		name    string
+		ref     string
+		content string
+		wantErr bool
+	}{
+		{name: "Default branch", content: "package main // v2\n"},
+		{name: "Pinned tag", ref: "@v1.0.0", content: "package main // v1\n"},
+		{name: "Pinned commit", ref: "@" + firstCommit, content: "package main // v1\n"},
+		{name: "Unknown ref", ref: "@v9.9.9", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source, err := remote.Parse("git+" + repoURL + tc.ref)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			fetched, err := source.Fetch(context.Background(), cacheDir)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			content, err := os.ReadFile(filepath.Join(fetched.Dir, "main.tpl"))
+			if err != nil {
+				t.Fatalf("Failed to read fetched template: %v", err)
+			}
+			if string(content) != tc.content {
+				t.Errorf("Expected %q, got %q", tc.content, content)
+			}
+			if _, err := os.Stat(filepath.Join(fetched.Dir, ".git")); !os.IsNotExist(err) {
+				t.Error("Expected .git to be removed from the cached template")
+			}
+			if len(fetched.Revision) != 40 {
+				t.Errorf("Expected a commit as revision, got %q", fetched.Revision)
+			}
+		})
+	}
+
+	t.Run("Branch is fetched again when it moves", func(t *testing.T) {
+		source, _ := remote.Parse("git+" + repoURL + "@stable")
+		for _, want := range []string{"package main // v1\n", "package main // v2\n"} {
+			fetched, err := source.Fetch(context.Background(), cacheDir)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			content, err := os.ReadFile(filepath.Join(fetched.Dir, "main.tpl"))
+			if err != nil {
+				t.Fatalf("Failed to read fetched template: %v", err)
+			}
+			if string(content) != want {
+				t.Errorf("Expected %q, got %q", want, content)
+			}
+			runGit(t, work, "push", "--quiet", bare, "HEAD:refs/heads/stable")
+		}
+	})
+
+	t.Run("Pinned ref is cached", func(t *testing.T) {
+		if err := os.RemoveAll(bare); err != nil {
+			t.Fatalf("Failed to remove repository: %v", err)
+		}
+		source, _ := remote.Parse("git+" + repoURL + "@v1.0.0")
+		if _, err := source.Fetch(context.Background(), cacheDir); err != nil {
+			t.Errorf("Expected cached template without access to the repository: %v", err)
+		}
+	})
+}
+
+func TestFetchArchiveSource(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"svc/template.yaml": "name: svc\nfiles:\n  - source: main.tpl\n    target: main.go\n",
+		"svc/main.tpl":      "package main\n",
+	}
+
+	testCases := []struct {
+		name    string
+		file    string
+		content []byte
+		wantErr bool
+	}{
+		{name: "Tar gz", file: "svc.tar.gz", content: tarGz(t, files)},
+		{name: "Zip", file: "svc.zip", content: zipArchive(t, files)},
+		{name: "Path traversal", file: "evil.tar.gz", content: tarGz(t, map[string]string{"../evil.tpl": "x"}), wantErr: true},
+		{name: "Corrupt archive", file: "corrupt.zip", content: []byte("not a zip"), wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			archive := filepath.Join(tempDir, tc.file)
+			if err := os.WriteFile(archive, tc.content, 0644); err != nil {
+				t.Fatalf("Failed to write archive: %v", err)
+			}
+
+			source, err := remote.Parse(archive)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			fetched, err := source.Fetch(context.Background(), filepath.Join(tempDir, "cache"))
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			// The single top-level directory of the archive is the template root
+			if _, err := os.Stat(filepath.Join(fetched.Dir, "template.yaml")); err != nil {
+				t.Errorf("Expected template.yaml at the template root: %v", err)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "cache", "evil.tpl")); !os.IsNotExist(err) {
+		t.Error("Archive entry escaped the cache directory")
+	}
+}
+
+func TestFetchArchiveSourceLimits(t *testing.T) {
+	files := map[string]string{
+		"svc/template.yaml": "name: svc\nfiles:\n  - source: main.tpl\n    target: main.go\n",
+		"svc/main.tpl":      "package main\n",
+	}
+	// Compresses to a fraction of its extracted size
+	oversized := map[string]string{
+		"svc/template.yaml": files["svc/template.yaml"],
+		"svc/big.tpl":       strings.Repeat("0", 1<<20),
+	}
+
+	testCases := []struct {
+		name     string
+		file     string
+		content  []byte
+		fileSize int64
+		size     int64
+		entries  int
+		wantErr  string
+	}{
+		{name: "Within the limits", file: "svc.tar.gz", content: tarGz(t, files), fileSize: 1 << 10, size: 1 << 10, entries: 2},
+		{name: "Oversized entry", file: "big.tar.gz", content: tarGz(t, oversized), fileSize: 1 << 10, size: 4 << 20, entries: 10, wantErr: "svc/big.tpl is larger than 1024 bytes"},
+		{name: "Oversized zip entry", file: "big.zip", content: zipArchive(t, oversized), fileSize: 1 << 10, size: 4 << 20, entries: 10, wantErr: "svc/big.tpl is larger than 1024 bytes"},
+		{name: "Oversized archive", file: "total.tar.gz", content: tarGz(t, oversized), fileSize: 4 << 20, size: 1 << 20, entries: 10, wantErr: "extracts to more than 1048576 bytes"},
+		{name: "Too many entries", file: "entries.zip", content: zipArchive(t, files), fileSize: 1 << 10, size: 1 << 10, entries: 1, wantErr: "more than 1 entries"},
+	}
+
+	defer func(fileSize, size int64, entries int) {
+		remote.MaxExtractedFileSize, remote.MaxExtractedSize, remote.MaxArchiveEntries = fileSize, size, entries
+	}(remote.MaxExtractedFileSize, remote.MaxExtractedSize, remote.MaxArchiveEntries)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			remote.MaxExtractedFileSize, remote.MaxExtractedSize, remote.MaxArchiveEntries = tc.fileSize, tc.size, tc.entries
+			archive := filepath.Join(t.TempDir(), tc.file)
+			if err := os.WriteFile(archive, tc.content, 0644); err != nil {
+				t.Fatalf("Failed to write archive: %v", err)
+			}
+			source, err := remote.Parse(archive)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			cache := filepath.Join(t.TempDir(), "cache")
+			_, err = source.Fetch(context.Background(), cache)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Expected an error containing %q, got %v", tc.wantErr, err)
+			}
+			// Nothing of a rejected archive is left in the cache
+			matches, _ := filepath.Glob(filepath.Join(cache, "archive", "*", "src"))
+			if len(matches) > 0 {
+				t.Errorf("Expected nothing to be cached, got %v", matches)
+			}
+		})
+	}
+}
+
+func TestFetchGitSourceRejectsOptionRef(t *testing.T) {
+	source := &remote.Source{Kind: remote.KindGit, Location: "https://example.com/templates.git", Ref: "--upload-pack=touch"}
+	if _, err := source.Fetch(context.Background(), t.TempDir()); err == nil || !strings.Contains(err.Error(), "must not start with -") {
+		t.Errorf("Expected the ref to be rejected, got %v", err)
+	}
+}
+
+func TestDownloadArchiveSource(t *testing.T) {
+	archive := tarGz(t, map[string]string{
+		"svc/template.yaml": "name: svc\nfiles:\n  - source: main.tpl\n    target: main.go\n",
+		"svc/main.tpl":      "package main\n",
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Without a Content-Length, only reading the body finds out its size
+		w.(http.Flusher).Flush()
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name    string
+		maxSize int64
+		wantErr bool
+	}{
+		{name: "Within the limit", maxSize: int64(len(archive))},
+		{name: "Larger than the limit", maxSize: int64(len(archive)) - 1, wantErr: true},
+	}
+
+	defer func(size int64) { remote.MaxArchiveSize = size }(remote.MaxArchiveSize)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			remote.MaxArchiveSize = tc.maxSize
+			source, err := remote.Parse(server.URL + "/svc.tar.gz")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			fetched, err := source.Fetch(context.Background(), t.TempDir())
+			if tc.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "larger than") {
+					t.Errorf("Expected the archive to be rejected, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(fetched.Dir, "template.yaml")); err != nil {
+				t.Errorf("Expected template.yaml at the template root: %v", err)
+			}
+		})
+	}
+}
+
+func TestAddTemplateSource(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "template.yaml", "name: remote-svc\nfiles:\n  - source: main.tpl\n    target: main.go\n")
+	writeTemplate(t, dir, "main.tpl", "package main // {{.ProjectName}}\n")
+	defer templates.SetTemplateDirs(nil)
+
+	types, err := templates.AddTemplateSource(dir, "fallback")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(types) != 1 || types[0] != "remote-svc" {
+		t.Fatalf("Expected project type remote-svc, got %v", types)
+	}
+
+	available, err := templates.ProjectTypes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(strings.Join(available, ","), "remote-svc") || !strings.Contains(strings.Join(available, ","), "api") {
+		t.Errorf("Expected remote-svc next to the built-in types, got %v", available)
+	}
+
+	content, err := templates.NewFileGenerator(templates.NewTemplateLoader()).Render("remote-svc/main.tpl", map[string]interface{}{"ProjectName": "demo"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(content) != "package main // demo\n" {
+		t.Errorf("Unexpected content: %q", content)
+	}
+}
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write archive: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write archive: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to write archive: %v", err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write archive: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	return buf.Bytes()
+}
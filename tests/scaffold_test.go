@@ -0,0 +1,476 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/internal/scaffold"
+	"github.com/go-sova/sova-cli/pkg/questions"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+const generatedRoutes = `package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"example.com/shop/internal/handlers"
+	"example.com/shop/internal/middleware"
+)
+
+// SetupRoutes configures all the routes for the application
+func SetupRoutes(router *gin.Engine) {
+	// Add logging middleware
+	router.Use(middleware.LoggingMiddleware())
+
+	// API routes
+	api := router.Group("/api")
+	{
+		api.GET("/ping", handlers.PingHandler)
+		api.GET("/health", handlers.HealthHandler)
+	}
+}
+`
+
+func TestRoutesAdd(t *testing.T) {
+	getUser := scaffold.Route{Method: "GET", Path: "/users/:id", Handler: "handlers.GetUserHandler"}
+
+	testCases := []struct {
+		name     string
+		src      string
+		routes   []scaffold.Route
+		expected []string
+		wantErr  string
+	}{
+		{
+			name:     "Generated file",
+			src:      generatedRoutes,
+			routes:   []scaffold.Route{getUser},
+			expected: []string{"\t\tapi.GET(\"/health\", handlers.HealthHandler)\n\t\tapi.GET(\"/users/:id\", handlers.GetUserHandler)\n\t}\n"},
+		},
+		{
+			name: "Several routes",
+			src:  generatedRoutes,
+			routes: []scaffold.Route{
+				{Method: "POST", Path: "/users", Handler: "handlers.CreateUserHandler"},
+				{Method: "DELETE", Path: "/users/:id", Handler: "handlers.DeleteUserHandler"},
+			},
+			expected: []string{"\t\tapi.POST(\"/users\", handlers.CreateUserHandler)\n\t\tapi.DELETE(\"/users/:id\", handlers.DeleteUserHandler)\n\t}\n"},
+		},
+		{
+			name:     "Trailing comment in the group",
+			src:      strings.Replace(generatedRoutes, "handlers.HealthHandler)\n", "handlers.HealthHandler)\n\t\t// more routes here\n", 1),
+			routes:   []scaffold.Route{getUser},
+			expected: []string{"\t\t// more routes here\n\t\tapi.GET(\"/users/:id\", handlers.GetUserHandler)\n\t}\n"},
+		},
+		{
+			name:     "Empty group block",
+			src:      strings.Replace(generatedRoutes, "\t\tapi.GET(\"/ping\", handlers.PingHandler)\n\t\tapi.GET(\"/health\", handlers.HealthHandler)\n", "", 1),
+			routes:   []scaffold.Route{getUser},
+			expected: []string{"\t{\n\t\tapi.GET(\"/users/:id\", handlers.GetUserHandler)\n\t}\n"},
+		},
+		{
+			name: "Group without a block",
+			src: strings.Replace(generatedRoutes, "\t{\n\t\tapi.GET(\"/ping\", handlers.PingHandler)\n\t\tapi.GET(\"/health\", handlers.HealthHandler)\n\t}\n",
+				"\tapi.GET(\"/ping\", handlers.PingHandler)\n\n\trouter.NoRoute(handlers.PingHandler)\n", 1),
+			routes:   []scaffold.Route{getUser},
+			expected: []string{"\tapi.GET(\"/ping\", handlers.PingHandler)\n\tapi.GET(\"/users/:id\", handlers.GetUserHandler)\n\n\trouter.NoRoute"},
+		},
+		{
+			name:     "Missing handlers import",
+			src:      strings.Replace(strings.Replace(generatedRoutes, "\t\"example.com/shop/internal/handlers\"\n", "", 1), "handlers.", "middleware.", -1),
+			routes:   []scaffold.Route{getUser},
+			expected: []string{"\"example.com/shop/internal/handlers\"\n", "api.GET(\"/users/:id\", handlers.GetUserHandler)"},
+		},
+		{
+			name:     "Aliased handlers import",
+			src:      strings.Replace(strings.Replace(generatedRoutes, "\t\"example.com/shop/internal/handlers\"\n", "\th \"example.com/shop/internal/handlers\"\n", 1), "handlers.", "h.", -1),
+			routes:   []scaffold.Route{getUser},
+			expected: []string{"\th \"example.com/shop/internal/handlers\"\n", "api.GET(\"/users/:id\", h.GetUserHandler)"},
+		},
+		{
+			name:     "Dot handlers import",
+			src:      strings.Replace(strings.Replace(generatedRoutes, "\t\"example.com/shop/internal/handlers\"\n", "\t. \"example.com/shop/internal/handlers\"\n", 1), "handlers.", "", -1),
+			routes:   []scaffold.Route{getUser},
+			expected: []string{"api.GET(\"/users/:id\", GetUserHandler)"},
+		},
+		{
+			name:    "Route already registered",
+			src:     generatedRoutes,
+			routes:  []scaffold.Route{{Method: "GET", Path: "/health", Handler: "handlers.OtherHandler"}},
+			wantErr: "already registers GET /api/health",
+		},
+		{
+			name:    "No router group",
+			src:     "package routes\n\nfunc SetupRoutes() {\n}\n",
+			wantErr: "creates no router group",
+		},
+		{
+			name:    "No SetupRoutes",
+			src:     "package routes\n",
+			wantErr: "has no function SetupRoutes",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			routes, err := scaffold.ParseRoutes([]byte(tc.src))
+			var result []byte
+			if err == nil {
+				result, err = routes.Add("example.com/shop/internal/handlers", tc.routes...)
+			}
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, expected := range tc.expected {
+				if !strings.Contains(string(result), expected) {
+					t.Errorf("Expected routes.go to contain %q, got:\n%s", expected, result)
+				}
+			}
+			if _, err := scaffold.ParseRoutes(result); err != nil {
+				t.Errorf("Failed to parse the result: %v", err)
+			}
+		})
+	}
+}
+
+func TestRoutesRelativePath(t *testing.T) {
+	routes, err := scaffold.ParseRoutes([]byte(generatedRoutes))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if routes.Prefix() != "/api" {
+		t.Errorf("Expected prefix /api, got %s", routes.Prefix())
+	}
+
+	testCases := map[string]string{
+		"/users/:id":     "/users/:id",
+		"users":          "/users",
+		"/api/users/:id": "/users/:id",
+		"/api":           "/",
+		"/apis":          "/apis",
+	}
+	for path, expected := range testCases {
+		if relative := routes.RelativePath(path); relative != expected {
+			t.Errorf("RelativePath(%q) = %q, expected %q", path, relative, expected)
+		}
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		funcName string
+		file     string
+		params   []scaffold.Param
+		example  string
+		wantErr  bool
+	}{
+		{
+			name:     "get-user",
+			path:     "/users/:id",
+			funcName: "GetUserHandler",
+			file:     "internal/handlers/get_user.go",
+			params:   []scaffold.Param{{Name: "id", Var: "id"}},
+			example:  "/users/1",
+		},
+		{
+			name:     "ListFilesHandler",
+			path:     "/files/:type/*file_path",
+			funcName: "ListFilesHandler",
+			file:     "internal/handlers/list_files.go",
+			params:   []scaffold.Param{{Name: "type", Var: "typeParam"}, {Name: "file_path", Var: "filePath"}},
+			example:  "/files/1/1",
+		},
+		{
+			name:     "status",
+			path:     "/status",
+			funcName: "StatusHandler",
+			file:     "internal/handlers/status.go",
+			example:  "/status",
+		},
+		{name: "123", path: "/", wantErr: true},
+		{name: "handler", path: "/", wantErr: true},
+		{name: "user-test", path: "/", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler, err := scaffold.NewHandler(tc.name, "GET", tc.path)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if handler.Name != tc.funcName {
+				t.Errorf("Expected function %s, got %s", tc.funcName, handler.Name)
+			}
+			if handler.File != tc.file {
+				t.Errorf("Expected file %s, got %s", tc.file, handler.File)
+			}
+			values := handler.Values("/api")
+			if params := values["Params"].([]scaffold.Param); !reflect.DeepEqual(params, tc.params) {
+				t.Errorf("Expected params %v, got %v", tc.params, params)
+			}
+			if values["ExamplePath"] != tc.example {
+				t.Errorf("Expected example path %s, got %v", tc.example, values["ExamplePath"])
+			}
+			if values["URL"] != "/api"+strings.TrimSuffix(tc.path, "/") {
+				t.Errorf("Unexpected URL %v", values["URL"])
+			}
+		})
+	}
+}
+
+func TestScaffoldRender(t *testing.T) {
+	answers := &questions.ProjectAnswers{ProjectType: "api", ModulePath: "example.com/shop", Values: map[string]interface{}{}}
+	s, err := project.NewScaffold(templates.GetTemplateFS(), answers)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s.ModulePath() != "example.com/shop" {
+		t.Errorf("Expected module example.com/shop, got %s", s.ModulePath())
+	}
+
+	handler, err := scaffold.NewHandler("get-user", "GET", "/users/:id")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, err := s.Render("handler.go.tpl", handler.File, handler.Values("/api"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, expected := range []string{"package handlers", "// GetUserHandler handles GET /api/users/:id", `id := c.Param("id")`} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected handler to contain %q, got:\n%s", expected, content)
+		}
+	}
+
+	cli, err := project.NewScaffold(templates.GetTemplateFS(), &questions.ProjectAnswers{ProjectType: "cli", ModulePath: "example.com/tool"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, ok := cli.Template("handler.go.tpl"); ok {
+		t.Error("Expected cli projects to have no handler generator")
+	}
+}
+
+func TestDeclaredFuncs(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "health.go", "package handlers\n\nfunc HealthHandler() {}\n\ntype server struct{}\n\nfunc (s server) PingHandler() {}\n")
+	writeTemplate(t, dir, "notes.txt", "func NotGo() {}\n")
+
+	declared, err := scaffold.DeclaredFuncs(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]string{"HealthHandler": "health.go"}
+	if !reflect.DeepEqual(declared, expected) {
+		t.Errorf("Expected %v, got %v", expected, declared)
+	}
+
+	if declared, err := scaffold.DeclaredFuncs(filepath.Join(dir, "missing")); err != nil || len(declared) != 0 {
+		t.Errorf("Expected nothing for a missing directory, got %v, %v", declared, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.go"), []byte("package handlers\nfunc {"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := scaffold.DeclaredFuncs(dir); err == nil {
+		t.Error("Expected error but got none")
+	}
+}
+
+func TestNewResource(t *testing.T) {
+	testCases := []struct {
+		name    string
+		fields  []string
+		model   string
+		varName string
+		table   string
+		path    string
+		wantErr string
+	}{
+		{name: "user", fields: []string{"name:string"}, model: "User", varName: "user", table: "users", path: "/users"},
+		{name: "blog-posts", fields: []string{"title:string"}, model: "BlogPost", varName: "blogPost", table: "blog_posts", path: "/blog-posts"},
+		{name: "Category", fields: []string{"name:string"}, model: "Category", varName: "category", table: "categories", path: "/categories"},
+		{name: "row", fields: []string{"name:string"}, model: "Row", varName: "rowItem", table: "rows", path: "/rows"},
+		{name: "news", fields: []string{"title:string"}, wantErr: "plural is the same word"},
+		{name: "user", wantErr: "has no fields"},
+		{name: "user", fields: []string{"name:string", "Name:text"}, wantErr: "duplicate field name"},
+		{name: "1st", fields: []string{"name:string"}, wantErr: "invalid resource name"},
+		{name: "item", fields: []string{"group:string", "order:int"}, wantErr: "group is a reserved word in SQL"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resource, err := scaffold.NewResource(tc.name, tc.fields)
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("Expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			got := []string{resource.Model, resource.Var, resource.Table, resource.Path}
+			expected := []string{tc.model, tc.varName, tc.table, tc.path}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestParseField(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		expected scaffold.Field
+		wantErr  bool
+	}{
+		{spec: "email:string:unique", expected: scaffold.Field{Name: "Email", Column: "email", GoType: "string", SQL: "TEXT", Unique: true}},
+		{spec: "firstName:string", expected: scaffold.Field{Name: "FirstName", Column: "first_name", GoType: "string", SQL: "TEXT"}},
+		{spec: "age:int", expected: scaffold.Field{Name: "Age", Column: "age", GoType: "int", SQL: "INTEGER"}},
+		{spec: "born_at:time", expected: scaffold.Field{Name: "BornAt", Column: "born_at", GoType: "time.Time", SQL: "TIMESTAMPTZ"}},
+		{spec: "name", wantErr: true},
+		{spec: "name:uuid", wantErr: true},
+		{spec: "name:string:indexed", wantErr: true},
+		{spec: "id:int", wantErr: true},
+		{spec: "created_at:time", wantErr: true},
+		{spec: "group:string", wantErr: true},
+		{spec: "Order:int", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			field, err := scaffold.ParseField(tc.spec)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if field != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, field)
+			}
+		})
+	}
+}
+
+func TestResourceQueries(t *testing.T) {
+	resource, err := scaffold.NewResource("user", []string{"name:string", "email:string:unique", "age:int"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	checks := map[string][2]string{
+		"Columns":       {resource.Columns(), "name, email, age"},
+		"Placeholders":  {resource.Placeholders(), "$1, $2, $3"},
+		"Assignments":   {resource.Assignments(), "name = $1, email = $2, age = $3"},
+		"IDPlaceholder": {resource.IDPlaceholder(), "$4"},
+	}
+	for name, check := range checks {
+		if check[0] != check[1] {
+			t.Errorf("Expected %s %q, got %q", name, check[1], check[0])
+		}
+	}
+
+	var routes []string
+	for _, route := range resource.Routes() {
+		routes = append(routes, route.String()+" "+route.Handler)
+	}
+	expected := []string{
+		"GET /users handlers.ListUsersHandler",
+		"GET /users/:id handlers.GetUserHandler",
+		"POST /users handlers.CreateUserHandler",
+		"PUT /users/:id handlers.UpdateUserHandler",
+		"DELETE /users/:id handlers.DeleteUserHandler",
+	}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Errorf("Expected routes %v, got %v", expected, routes)
+	}
+}
+
+func TestFindMigration(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok := scaffold.FindMigration(dir, "users"); ok {
+		t.Error("Expected no migration in an empty project")
+	}
+
+	writeTemplate(t, dir, "migrations/20240101000000_create_users.up.sql", "CREATE TABLE users ();")
+	writeTemplate(t, dir, "migrations/20240202000000_create_user_roles.up.sql", "CREATE TABLE user_roles ();")
+	version, ok := scaffold.FindMigration(dir, "users")
+	if !ok || version != "20240101000000" {
+		t.Errorf("Expected version 20240101000000, got %q, %v", version, ok)
+	}
+}
+
+func TestScaffoldRenderResource(t *testing.T) {
+	answers := &questions.ProjectAnswers{ProjectType: "api", ModulePath: "example.com/shop", Values: map[string]interface{}{"UsePostgres": true}}
+	s, err := project.NewScaffold(templates.GetTemplateFS(), answers)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if usePostgres, _ := s.Value("UsePostgres").(bool); !usePostgres {
+		t.Error("Expected UsePostgres to be true")
+	}
+
+	resource, err := scaffold.NewResource("blog-post", []string{"title:string", "body:text", "published_at:time", "views:int:unique"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string][]string{
+		"internal/models/blog_post.go":                         {"type BlogPost struct", "PublishedAt time.Time `json:\"published_at\" db:\"published_at\"`"},
+		"internal/repository/blog_post.go":                     {"func NewBlogPostRepository(db *sql.DB) *BlogPostRepository", "INSERT INTO blog_posts (title, body, published_at, views) VALUES ($1, $2, $3, $4)", "WHERE id = $5"},
+		"internal/handlers/blog_post.go":                       {"func ListBlogPostsHandler(c *gin.Context)", "repository.NewBlogPostRepository(service.DB)", `"example.com/shop/internal/service"`},
+		"internal/handlers/blog_post_test.go":                  {`router.PUT("/blog-posts/:id", UpdateBlogPostHandler)`},
+		"migrations/20240101000000_create_blog_posts.up.sql":   {"CREATE TABLE blog_posts (", "    views INTEGER NOT NULL UNIQUE,"},
+		"migrations/20240101000000_create_blog_posts.down.sql": {"DROP TABLE IF EXISTS blog_posts;"},
+	}
+
+	files := resource.Files("20240101000000")
+	if len(files) != len(expected) {
+		t.Fatalf("Expected %d files, got %d", len(expected), len(files))
+	}
+	for _, file := range files {
+		content, err := s.Render(file.Template, file.Path, resource.Values("/api"))
+		if err != nil {
+			t.Fatalf("Failed to render %s: %v", file.Template, err)
+		}
+		contains, ok := expected[file.Path]
+		if !ok {
+			t.Errorf("Unexpected file %s", file.Path)
+			continue
+		}
+		for _, text := range contains {
+			if !strings.Contains(string(content), text) {
+				t.Errorf("Expected %s to contain %q, got:\n%s", file.Path, text, content)
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/project"
+	"github.com/go-sova/sova-cli/internal/remote"
+	"github.com/go-sova/sova-cli/templates"
+)
+
+const storeManifest = "name: worker\nversion: 0.2.0\ndescription: Background worker\nfiles:\n  - source: main.tpl\n    target: main.go\n"
+
+func TestTemplateStore(t *testing.T) {
+	tempDir := t.TempDir()
+	store := project.NewTemplateStore(filepath.Join(tempDir, "store"))
+	cacheDir := filepath.Join(tempDir, "cache")
+
+	single := filepath.Join(tempDir, "worker-src")
+	writeTemplate(t, single, "template.yaml", storeManifest)
+	writeTemplate(t, single, "main.tpl", "package main\n")
+
+	pack := filepath.Join(tempDir, "pack")
+	writeTemplate(t, pack, "job/main.tpl", "package main\n")
+	writeTemplate(t, pack, "cron/main.tpl", "package main\n")
+
+	broken := filepath.Join(tempDir, "broken")
+	writeTemplate(t, broken, "template.yaml", "name: broken\nfiles:\n  - source: missing.tpl\n    target: main.go\n")
+
+	archive := filepath.Join(tempDir, "worker.tar.gz")
+	if err := os.WriteFile(archive, tarGz(t, map[string]string{"worker/template.yaml": storeManifest, "worker/main.tpl": "package main\n"}), 0644); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	testCases := []struct {
+		name    string
+		source  string
+		as      string
+		force   bool
+		want    []string
+		wantErr bool
+	}{
+		{name: "Single template from directory", source: single, want: []string{"worker"}},
+		{name: "Already installed", source: single, wantErr: true},
+		{name: "Replace with force", source: archive, force: true, want: []string{"worker"}},
+		{name: "Rename", source: archive, as: "worker2", want: []string{"worker2"}},
+		{name: "Template pack", source: pack, want: []string{"cron", "job"}},
+		{name: "Rename template pack", source: pack, as: "jobs", wantErr: true},
+		{name: "Invalid template", source: broken, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source, err := remote.Parse(tc.source)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			installed, err := store.Install(context.Background(), source, cacheDir, tc.as, tc.force)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if len(installed) != len(tc.want) {
+				t.Fatalf("Expected %v to be installed, got %v", tc.want, installed)
+			}
+			for i, entry := range installed {
+				if entry.Name != tc.want[i] {
+					t.Errorf("Expected %s to be installed, got %s", tc.want[i], entry.Name)
+				}
+				if _, err := os.Stat(filepath.Join(store.Dir, entry.Name, "main.tpl")); err != nil {
+					t.Errorf("Expected %s in the store: %v", entry.Name, err)
+				}
+			}
+		})
+	}
+
+	installed, err := store.Installed()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if entry := installed["worker"]; entry.Source != archive || entry.Revision == "" {
+		t.Errorf("Expected worker to be recorded as installed from %s, got %+v", archive, entry)
+	}
+
+	if err := store.Remove("worker"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(store.Dir, "worker")); !os.IsNotExist(err) {
+		t.Error("Expected worker to be removed from the store")
+	}
+	if installed, _ := store.Installed(); len(installed) != 3 {
+		t.Errorf("Expected 3 installed templates after remove, got %v", installed)
+	}
+	if err := store.Remove("worker"); err == nil {
+		t.Error("Expected error removing a template that is not installed")
+	}
+}
+
+func TestTemplateManager(t *testing.T) {
+	tempDir := t.TempDir()
+	localDir := filepath.Join(tempDir, "local")
+	storeDir := filepath.Join(tempDir, "store")
+
+	writeTemplate(t, localDir, "worker/template.yaml", storeManifest)
+	writeTemplate(t, localDir, "worker/main.tpl", "package main\n")
+	writeTemplate(t, localDir, "broken/template.yaml", "files:\n  - source: main.tpl\n    target: main.go\n")
+	writeTemplate(t, localDir, "broken/main.tpl", "package main // {{.ProjectName\n")
+	writeTemplate(t, storeDir, "service/template.yaml", "name: service\nversion: 1.2.0\nfiles:\n  - source: main.tpl\n    target: main.go\n")
+	writeTemplate(t, storeDir, "service/main.tpl", "package main\n")
+	writeTemplate(t, storeDir, ".installed.yaml", "service:\n  source: git+https://github.com/acme/templates.git@v1.2.0\n")
+
+	templates.SetTemplateDirs([]string{localDir, storeDir})
+	defer templates.SetTemplateDirs(nil)
+
+	manager := project.NewTemplateManager()
+	manager.SetStore(project.NewTemplateStore(storeDir))
+
+	testCases := []struct {
+		name        string
+		template    string
+		version     string
+		source      string
+		location    string
+		validateErr bool
+	}{
+		{name: "Embedded template", template: "api", version: "1.0.0", source: project.SourceEmbedded},
+		{name: "Local template", template: "worker", version: "0.2.0", source: project.SourceLocal, location: filepath.Join(localDir, "worker")},
+		{name: "Installed remote template", template: "service", version: "1.2.0", source: project.SourceRemote, location: "git+https://github.com/acme/templates.git@v1.2.0"},
+		{name: "Template that does not parse", template: "broken", source: project.SourceLocal, location: filepath.Join(localDir, "broken"), validateErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := manager.Template(tc.template)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if info.Version != tc.version || info.Source != tc.source || info.Location != tc.location {
+				t.Errorf("Expected %s %s (%s), got %s %s (%s)", tc.version, tc.source, tc.location, info.Version, info.Source, info.Location)
+			}
+
+			err = manager.ValidateTemplate(tc.template)
+			if tc.validateErr && err == nil {
+				t.Error("Expected validation error but got none")
+			}
+			if !tc.validateErr && err != nil {
+				t.Errorf("Unexpected validation error: %v", err)
+			}
+		})
+	}
+
+	names, err := manager.ListTemplates()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(names) != 5 {
+		t.Errorf("Expected api, broken, cli, service and worker, got %v", names)
+	}
+
+	if _, err := manager.Template("nonexistent"); err == nil {
+		t.Error("Expected error for unknown template")
+	}
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-sova/sova-cli/internal/project"
+)
+
+func TestPlanUpgrade(t *testing.T) {
+	newPlan := func(files map[string]string) *project.Plan {
+		plan := &project.Plan{ProjectName: "demo", Lock: &project.Lock{Template: project.LockTemplate{Name: "demo", Version: "1.1.0"}, Files: map[string]string{}}}
+		for name, content := range files {
+			plan.AddFile(name, name+".tpl", []byte(content))
+		}
+		return plan
+	}
+
+	const server = "package server\n\nfunc New() *Server {\n\treturn &Server{}\n}\n\nfunc (s *Server) Start() error {\n\treturn s.run()\n}\n"
+	base := newPlan(map[string]string{
+		"server.go":   server,
+		"handlers.go": "package handlers\n\nfunc Ping() string {\n\treturn \"pong\"\n}\n",
+		"README.md":   "# demo\n",
+		"Makefile":    "build:\n\tgo build\n",
+		"routes.go":   "package routes\n\nfunc Setup() {\n\tping()\n}\n",
+		"old.txt":     "no longer generated\n",
+		"edited.txt":  "no longer generated\n",
+		"go.mod":      "module example.com/demo\n\ngo 1.21\n\nrequire github.com/gin-gonic/gin v1.9.1\n",
+	})
+	next := newPlan(map[string]string{
+		"server.go":   strings.Replace(server, "\treturn s.run()\n", "\tdefer s.shutdown()\n\treturn s.run()\n", 1),
+		"handlers.go": "package handlers\n\nfunc Ping() string {\n\treturn \"PONG\"\n}\n",
+		"README.md":   "# demo\n\nGenerated by sova.\n",
+		"Makefile":    "build:\n\tgo build ./...\n",
+		"shutdown.go": "package server\n",
+		"routes.go":   "package routes\n\n// Setup registers the routes\nfunc Setup() {\n\tping()\n}\n",
+		"go.mod":      "module example.com/demo\n\ngo 1.21\n\nrequire github.com/gin-gonic/gin v1.10.0\n",
+	})
+
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "demo")
+	if err := base.Generate(context.Background(), dir, nil); err != nil {
+		t.Fatalf("Failed to generate project: %v", err)
+	}
+	writeTemplate(t, dir, "server.go", strings.Replace(server, "return &Server{}", "return &Server{name: \"demo\"}", 1))
+	writeTemplate(t, dir, "handlers.go", "package handlers\n\nfunc Ping() string {\n\treturn \"ok\"\n}\n")
+	writeTemplate(t, dir, "edited.txt", "still needed\n")
+	writeTemplate(t, dir, "go.mod", "module example.com/demo\n\ngo 1.21\n\nrequire github.com/gin-gonic/gin v1.9.1\n\nrequire golang.org/x/net v0.17.0 // indirect\n")
+	if err := os.Remove(filepath.Join(dir, "Makefile")); err != nil {
+		t.Fatalf("Failed to remove Makefile: %v", err)
+	}
+	// Changed by sova generate, which records the change in the lock
+	writeTemplate(t, dir, "routes.go", "package routes\n\nfunc Setup() {\n\tping()\n\tusers()\n}\n")
+	if err := base.Lock.HashFiles(dir, []string{"routes.go"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stored, err := project.ReadBase(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(stored.Files) != len(base.Files) {
+		t.Fatalf("Expected %d files in the base render, got %d", len(base.Files), len(stored.Files))
+	}
+	stored.Lock = base.Lock
+
+	changes, err := next.Upgrade(context.Background(), dir, stored)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	want := []project.FileChange{
+		{Path: "Makefile", Action: project.FileConflict, NewPath: "Makefile" + project.NewFileSuffix},
+		{Path: "README.md", Action: project.FileUpdated},
+		{Path: "go.mod", Action: project.FileUpdated},
+		{Path: "handlers.go", Action: project.FileConflict},
+		{Path: "old.txt", Action: project.FileRemoved},
+		{Path: "routes.go", Action: project.FileMerged},
+		{Path: "server.go", Action: project.FileMerged},
+		{Path: "shutdown.go", Action: project.FileCreated},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Expected %v, got %v", want, changes)
+	}
+
+	testCases := []struct {
+		name string
+		file string
+		want []string
+	}{
+		{name: "Merged file", file: "server.go", want: []string{"name: \"demo\"", "defer s.shutdown()"}},
+		{name: "File changed by sova generate", file: "routes.go", want: []string{"// Setup registers the routes", "\tusers()\n"}},
+		{name: "Conflicting file", file: "handlers.go", want: []string{"<<<<<<< current\n\treturn \"ok\"\n=======\n\treturn \"PONG\"\n>>>>>>> template demo 1.1.0\n"}},
+		{name: "File kept because it was edited", file: "edited.txt", want: []string{"still needed"}},
+		{name: "New version of a deleted file", file: "Makefile" + project.NewFileSuffix, want: []string{"go build ./..."}},
+		{name: "Requirement bumped in go.mod", file: "go.mod", want: []string{"github.com/gin-gonic/gin v1.10.0", "golang.org/x/net v0.17.0 // indirect"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join(dir, tc.file))
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", tc.file, err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(string(content), want) {
+					t.Errorf("Expected %s to contain %q, got:\n%s", tc.file, want, content)
+				}
+			}
+		})
+	}
+
+	t.Run("Recorded base render", func(t *testing.T) {
+		if err := next.RecordUpdate(dir, stored, changes); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		recorded, err := project.ReadBase(dir)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if file, ok := recorded.File("server.go"); !ok || !strings.Contains(string(file.Content), "defer s.shutdown()") {
+			t.Error("Expected the new render of server.go as its base")
+		}
+		if file, ok := recorded.File("Makefile"); !ok || strings.Contains(string(file.Content), "./...") {
+			t.Error("Expected the old render of Makefile as its base until its new version is merged")
+		}
+		if _, ok := recorded.File("old.txt"); ok {
+			t.Error("Did not expect old.txt in the base render")
+		}
+	})
+}